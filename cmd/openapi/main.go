@@ -0,0 +1,64 @@
+package main
+
+import (
+	// golang package
+	"encoding/json"
+	"fmt"
+	"orderfc/cmd/order/handler"
+	"orderfc/config"
+	"orderfc/infrastructure/health"
+	"orderfc/infrastructure/openapi"
+	"orderfc/middleware"
+	"orderfc/routes"
+	"os"
+
+	// external package
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/pflag"
+)
+
+// main main.
+//
+// It prints the OpenAPI document of the service. With --check it instead
+// registers the routes the way the service does, without connecting to any
+// dependency, and exits non zero when the document and the routes drift.
+func main() {
+	check := pflag.Bool("check", false, "fail when the registered routes and the OpenAPI document drift")
+	output := pflag.StringP("output", "o", "", "write the OpenAPI document to this file instead of stdout")
+	pflag.Parse()
+
+	doc := routes.OpenAPIDocument()
+
+	if *check {
+		gin.SetMode(gin.ReleaseMode)
+		router := gin.New()
+		routes.SetupRoutes(router, handler.OrderHandler{}, nil, nil, middleware.NewRateLimiter(config.RateLimitConfig{}, nil), health.NewChecker(0))
+
+		err := openapi.Check(doc, router.Routes())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("OpenAPI document matches %d route(s)\n", len(router.Routes()))
+		return
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode OpenAPI document: %v\n", err)
+		os.Exit(1)
+	}
+
+	data = append(data, '\n')
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+
+	err = os.WriteFile(*output, data, 0o644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write OpenAPI document: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	// golang package
+	"net/http"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/validation"
+	"orderfc/middleware"
+	"orderfc/models"
+	"strconv"
+
+	// external package
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AdminSearchOrders admin search orders by given c pointer of gin.Context.
+func (h *OrderHandler) AdminSearchOrders(c *gin.Context) {
+	var param models.AdminOrderSearchParam
+	if err := c.ShouldBindQuery(&param); err != nil {
+		_ = c.Error(validation.FromError(err))
+		return
+	}
+
+	result, err := h.OrderUsecase.SearchOrders(c.Request.Context(), param)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+	})
+}
+
+// AdminGetOrderDetail admin get order detail by given c pointer of gin.Context.
+func (h *OrderHandler) AdminGetOrderDetail(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		_ = c.Error(ErrInvalidOrderID)
+		return
+	}
+
+	result, err := h.OrderUsecase.GetRawOrderDetail(c.Request.Context(), orderID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+	})
+}
+
+// AdminUpdateOrderStatus admin update order status by given c pointer of gin.Context.
+func (h *OrderHandler) AdminUpdateOrderStatus(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		_ = c.Error(ErrInvalidOrderID)
+		return
+	}
+
+	var param models.AdminUpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&param); err != nil {
+		_ = c.Error(validation.FromError(err))
+		return
+	}
+
+	actor, _ := middleware.GetPrincipal(c)
+
+	err = h.OrderUsecase.ForceUpdateOrderStatus(c.Request.Context(), orderID, actor, param)
+	if err != nil {
+		log.WithContext(c.Request.Context()).WithFields(logrus.Fields{
+			"order_id": orderID,
+			"param":    param,
+		}).Errorf("h.OrderUsecase.ForceUpdateOrderStatus() got error %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	log.WithContext(c.Request.Context()).WithFields(logrus.Fields{
+		"order_id":      orderID,
+		"actor_type":    actor.Type,
+		"actor_subject": actor.Subject,
+		"actor_id":      actor.UserID,
+		"status":        param.Status,
+		"reason":        param.Reason,
+	}).Info("Order status forced by admin")
+
+	c.JSON(http.StatusOK, models.AdminUpdateOrderStatusResponse{
+		OrderID: orderID,
+		Status:  "updated",
+	})
+}
+
+// AdminResendOrderEvent admin resend order event by given c pointer of gin.Context.
+func (h *OrderHandler) AdminResendOrderEvent(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		_ = c.Error(ErrInvalidOrderID)
+		return
+	}
+
+	var param models.AdminResendEventRequest
+	if err := c.ShouldBindJSON(&param); err != nil {
+		_ = c.Error(validation.FromError(err))
+		return
+	}
+
+	err = h.OrderUsecase.ResendOrderEvent(c.Request.Context(), orderID, param.Event)
+	if err != nil {
+		log.WithContext(c.Request.Context()).WithFields(logrus.Fields{
+			"order_id": orderID,
+			"event":    param.Event,
+		}).Errorf("h.OrderUsecase.ResendOrderEvent() got error %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AdminResendEventResponse{
+		OrderID: orderID,
+		Event:   param.Event,
+		Status:  "sent",
+	})
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	// golang package
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"orderfc/infrastructure/log"
+	"orderfc/models"
+	"strconv"
+	"time"
+
+	// external package
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	orderStatusEventName        = "status"
+	defaultOrderEventsHeartbeat = 15 * time.Second
+	orderEventsHeartbeatComment = ": heartbeat\n\n"
+	headerLastEventID           = "Last-Event-ID"
+)
+
+// GetOrderEvents get order events by given c pointer of gin.Context.
+//
+// It streams the status changes of the order as server-sent events until the
+// client disconnects or the server shuts down, starting with its current
+// status. A client reconnecting with the Last-Event-ID header resumes after
+// that event.
+func (h *OrderHandler) GetOrderEvents(c *gin.Context) {
+	userIDStr, isExist := c.Get("user_id")
+	if !isExist {
+		_ = c.Error(ErrUnauthorized)
+		return
+	}
+
+	userID, ok := userIDStr.(float64)
+	if !ok {
+		_ = c.Error(ErrInvalidUserID)
+		return
+	}
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		_ = c.Error(ErrInvalidOrderID)
+		return
+	}
+
+	// an invalid id is treated like none, the stream then starts over
+	lastEventID, _ := strconv.ParseInt(c.GetHeader(headerLastEventID), 10, 64)
+
+	ctx := c.Request.Context()
+	stream, err := h.OrderUsecase.SubscribeOrderEvents(ctx, int64(userID), orderID, lastEventID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	defer stream.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// keeps nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	lastSentID := lastEventID
+	for _, event := range stream.Backlog {
+		err = writeOrderEvent(c.Writer, event)
+		if err != nil {
+			return
+		}
+
+		lastSentID = event.ID
+	}
+	c.Writer.Flush()
+
+	heartbeat := h.OrderEventsConfig.HeartbeatInterval
+	if heartbeat <= 0 {
+		heartbeat = defaultOrderEventsHeartbeat
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.streams.Done():
+			return
+		case <-ticker.C:
+			_, err = io.WriteString(c.Writer, orderEventsHeartbeatComment)
+		case event, ok := <-stream.Events:
+			// dropped for falling behind, the client reconnects and resumes
+			if !ok {
+				log.WithContext(ctx).WithFields(logrus.Fields{
+					"order_id": orderID,
+				}).Warn("order event stream closed, subscriber fell behind")
+				return
+			}
+
+			// already sent from the backlog
+			if event.ID <= lastSentID {
+				continue
+			}
+
+			err = writeOrderEvent(c.Writer, event)
+			lastSentID = event.ID
+		}
+
+		if err != nil {
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// CloseEventStreams close event streams.
+//
+// http.Server.Shutdown waits for active requests and never cancels them, so
+// it is registered with RegisterOnShutdown to end the open streams, whose
+// clients then reconnect to another instance.
+func (h *OrderHandler) CloseEventStreams() {
+	h.closeStreams()
+}
+
+// writeOrderEvent write order event by given w of io.Writer, and event of models.OrderStatusEvent.
+//
+// Events without an id, i.e. the status of an order that never changed,
+// leave the client's last event id unset.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func writeOrderEvent(w io.Writer, event models.OrderStatusEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if event.ID > 0 {
+		_, err = fmt.Fprintf(w, "id: %d\n", event.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", orderStatusEventName, data)
+	return err
+}
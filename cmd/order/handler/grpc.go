@@ -0,0 +1,234 @@
+package handler
+
+import (
+	// golang package
+	"context"
+	"orderfc/cmd/order/usecase"
+	"orderfc/infrastructure/apperror"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/metrics"
+	"orderfc/middleware"
+	"orderfc/models"
+	"orderfc/proto/orderpb"
+
+	// external package
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	errActAsOtherUser         = apperror.Forbidden("act_as_other_user", "user tokens can only act for their own user")
+	errServiceCannotActAsUser = apperror.Forbidden("forbidden", "service credentials need the "+constant.ScopeOrdersActAsUser+" scope to act for a user")
+	errUserIDRequired         = apperror.Validation("user_id_required", "user_id is required for service credentials")
+)
+
+type OrderGRPCHandler struct {
+	orderpb.UnimplementedOrderServiceServer
+	OrderUsecase usecase.OrderUsecase
+}
+
+// NewOrderGRPCHandler new order grpc handler by given OrderUsecase.
+//
+// It returns pointer of OrderGRPCHandler when successful.
+// Otherwise, nil pointer of OrderGRPCHandler will be returned.
+func NewOrderGRPCHandler(orderUsecase usecase.OrderUsecase) *OrderGRPCHandler {
+	return &OrderGRPCHandler{
+		OrderUsecase: orderUsecase,
+	}
+}
+
+// Checkout checkout by given req pointer of orderpb.CheckoutRequest.
+//
+// It returns pointer of orderpb.CheckoutResponse, and nil error when successful.
+// Otherwise, nil pointer of orderpb.CheckoutResponse, and error will be returned.
+func (h *OrderGRPCHandler) Checkout(ctx context.Context, req *orderpb.CheckoutRequest) (*orderpb.CheckoutResponse, error) {
+	userID, err := grpcUserID(ctx, req.GetUserId())
+	if err != nil {
+		metrics.CheckoutTotal.WithLabelValues(metrics.CheckoutUnauthorized).Inc()
+		return nil, err
+	}
+
+	items := make([]models.CheckoutItem, len(req.GetItems()))
+	for index, item := range req.GetItems() {
+		items[index] = models.CheckoutItem{
+			ProductID: item.GetProductId(),
+			Quantity:  int(item.GetQuantity()),
+			Price:     item.GetPrice(),
+		}
+	}
+
+	param := models.CheckoutRequest{
+		UserID:           userID,
+		Items:            items,
+		PaymentMethod:    req.GetPaymentMethod(),
+		ShippingAddress:  req.GetShippingAddress(),
+		IdempotencyToken: req.GetIdempotencyToken(),
+	}
+
+	orderID, err := h.OrderUsecase.CheckoutOrder(ctx, &param)
+	if err != nil {
+		log.WithContext(ctx).WithFields(logrus.Fields{
+			"param": param,
+		}).Errorf("h.OrderUsecase.CheckoutOrder() got error %v", err)
+		return nil, err
+	}
+
+	return &orderpb.CheckoutResponse{
+		OrderId: orderID,
+		Status:  "created",
+	}, nil
+}
+
+// GetOrder get order by given req pointer of orderpb.GetOrderRequest.
+//
+// It returns pointer of orderpb.Order, and nil error when successful.
+// Otherwise, nil pointer of orderpb.Order, and error will be returned.
+func (h *OrderGRPCHandler) GetOrder(ctx context.Context, req *orderpb.GetOrderRequest) (*orderpb.Order, error) {
+	userID, err := grpcUserID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetOrderId() <= 0 {
+		return nil, ErrInvalidOrderID
+	}
+
+	order, err := h.OrderUsecase.GetOrder(ctx, userID, req.GetOrderId())
+	if err != nil {
+		return nil, err
+	}
+
+	return toOrderMessage(order), nil
+}
+
+// ListOrders list orders by given req pointer of orderpb.ListOrdersRequest.
+//
+// It returns pointer of orderpb.ListOrdersResponse, and nil error when successful.
+// Otherwise, nil pointer of orderpb.ListOrdersResponse, and error will be returned.
+func (h *OrderGRPCHandler) ListOrders(ctx context.Context, req *orderpb.ListOrdersRequest) (*orderpb.ListOrdersResponse, error) {
+	userID, err := grpcUserID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	histories, err := h.OrderUsecase.GetOrderHistory(ctx, models.OrderHistoryParam{
+		UserID: userID,
+		Status: int(req.GetStatus()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]*orderpb.Order, len(histories))
+	for index, history := range histories {
+		orders[index] = toOrderMessage(history)
+	}
+
+	return &orderpb.ListOrdersResponse{
+		Orders: orders,
+	}, nil
+}
+
+// CancelOrder cancel order by given req pointer of orderpb.CancelOrderRequest.
+//
+// It returns pointer of orderpb.CancelOrderResponse, and nil error when successful.
+// Otherwise, nil pointer of orderpb.CancelOrderResponse, and error will be returned.
+func (h *OrderGRPCHandler) CancelOrder(ctx context.Context, req *orderpb.CancelOrderRequest) (*orderpb.CancelOrderResponse, error) {
+	userID, err := grpcUserID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetOrderId() <= 0 {
+		return nil, ErrInvalidOrderID
+	}
+
+	err = h.OrderUsecase.CancelOrder(ctx, userID, req.GetOrderId(), req.GetReason())
+	if err != nil {
+		log.WithContext(ctx).WithFields(logrus.Fields{
+			"order_id": req.GetOrderId(),
+		}).Errorf("h.OrderUsecase.CancelOrder() got error %v", err)
+		return nil, err
+	}
+
+	return &orderpb.CancelOrderResponse{
+		OrderId: req.GetOrderId(),
+		Status:  "cancelled",
+	}, nil
+}
+
+// grpcUserID grpc user id by given ctx, and requested user id of the request.
+//
+// Orders belong to users. A user token acts for its own user, and may only
+// repeat it in the request. A signed service request acts for the requested
+// user, which needs the orders:act_as_user scope.
+//
+// It returns int64 of the user id, and nil error when successful.
+// Otherwise, 0, and error will be returned.
+func grpcUserID(ctx context.Context, requested int64) (int64, error) {
+	principal, ok := middleware.PrincipalFromContext(ctx)
+	if !ok {
+		return 0, ErrUnauthorized
+	}
+
+	switch principal.Type {
+	case constant.PrincipalTypeUser:
+		if principal.UserID == 0 {
+			return 0, ErrInvalidSession
+		}
+
+		if requested != 0 && requested != principal.UserID {
+			return 0, errActAsOtherUser
+		}
+
+		return principal.UserID, nil
+	case constant.PrincipalTypeService:
+		if !principal.HasScope(constant.ScopeOrdersActAsUser) {
+			return 0, errServiceCannotActAsUser
+		}
+
+		if requested <= 0 {
+			return 0, errUserIDRequired
+		}
+
+		return requested, nil
+	default:
+		return 0, ErrUnauthorized
+	}
+}
+
+// toOrderMessage to order message by given order of models.OrderHistoryResponse.
+//
+// It returns pointer of orderpb.Order.
+func toOrderMessage(order models.OrderHistoryResponse) *orderpb.Order {
+	products := make([]*orderpb.CheckoutItem, len(order.Products))
+	for index, product := range order.Products {
+		products[index] = &orderpb.CheckoutItem{
+			ProductId: product.ProductID,
+			Quantity:  int32(product.Quantity),
+			Price:     product.Price,
+		}
+	}
+
+	history := make([]*orderpb.StatusHistory, len(order.History))
+	for index, entry := range order.History {
+		history[index] = &orderpb.StatusHistory{
+			Status:    entry.Status,
+			Timestamp: entry.Timestamp,
+			Reason:    entry.Reason,
+			ActorId:   entry.ActorID,
+			Actor:     entry.Actor,
+		}
+	}
+
+	return &orderpb.Order{
+		OrderId:         order.OrderID,
+		TotalAmount:     order.TotalAmount,
+		TotalQty:        int32(order.TotalQty),
+		Status:          order.Status,
+		PaymentMethod:   order.PaymentMethod,
+		ShippingAddress: order.ShippingAddress,
+		Products:        products,
+		History:         history,
+	}
+}
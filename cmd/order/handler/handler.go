@@ -2,9 +2,14 @@ package handler
 
 import (
 	// golang package
+	"context"
 	"net/http"
 	"orderfc/cmd/order/usecase"
+	"orderfc/config"
+	"orderfc/infrastructure/apperror"
 	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/metrics"
+	"orderfc/infrastructure/validation"
 	"orderfc/models"
 	"strconv"
 
@@ -13,17 +18,34 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+var (
+	ErrInvalidSession = apperror.Validation("invalid_session", "invalid session, please try to login again!")
+	ErrInvalidOrderID = apperror.Validation("invalid_order_id", "invalid order id")
+	ErrUnauthorized   = apperror.Unauthorized("unauthorized", "unauthorized")
+	ErrInvalidUserID  = apperror.Unauthorized("invalid_user_id", "invalid user id")
+)
+
 type OrderHandler struct {
-	OrderUsecase usecase.OrderUsecase
+	OrderUsecase      usecase.OrderUsecase
+	OrderEventsConfig config.OrderEventsConfig
+
+	// done once the server shuts down, see CloseEventStreams
+	streams      context.Context
+	closeStreams context.CancelFunc
 }
 
-// NewOrderHandler new orderhandler by given OrderUsecase.
+// NewOrderHandler new orderhandler by given OrderUsecase, and orderEventsConfig of config.OrderEventsConfig.
 //
 // It returns pointer of OrderHandler when successful.
 // Otherwise, nil pointer of OrderHandler will be returned.
-func NewOrderHandler(orderUsecase usecase.OrderUsecase) *OrderHandler {
+func NewOrderHandler(orderUsecase usecase.OrderUsecase, orderEventsConfig config.OrderEventsConfig) *OrderHandler {
+	streams, closeStreams := context.WithCancel(context.Background())
+
 	return &OrderHandler{
-		OrderUsecase: orderUsecase,
+		OrderUsecase:      orderUsecase,
+		OrderEventsConfig: orderEventsConfig,
+		streams:           streams,
+		closeStreams:      closeStreams,
 	}
 }
 
@@ -31,49 +53,44 @@ func NewOrderHandler(orderUsecase usecase.OrderUsecase) *OrderHandler {
 func (h *OrderHandler) Checkout(c *gin.Context) {
 	var param models.CheckoutRequest
 	if err := c.ShouldBindJSON(&param); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
-		return
-	}
-
-	if len(param.Items) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "items must not be empty"})
+		metrics.CheckoutTotal.WithLabelValues(metrics.CheckoutInvalidRequest).Inc()
+		_ = c.Error(validation.FromError(err))
 		return
 	}
 
 	userIDStr, isExist := c.Get("user_id")
 	if !isExist {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error_message": "Unauthorized",
-		})
+		metrics.CheckoutTotal.WithLabelValues(metrics.CheckoutUnauthorized).Inc()
+		_ = c.Error(ErrUnauthorized)
 		return
 	}
 
 	userID, ok := userIDStr.(float64)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error_message": "Invalid user id",
-		})
+		metrics.CheckoutTotal.WithLabelValues(metrics.CheckoutUnauthorized).Inc()
+		_ = c.Error(ErrInvalidUserID)
 		return
 	}
 
 	param.UserID = int64(userID)
 	if param.UserID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session, please try to login again!"})
+		metrics.CheckoutTotal.WithLabelValues(metrics.CheckoutUnauthorized).Inc()
+		_ = c.Error(ErrInvalidSession)
 		return
 	}
 
 	orderID, err := h.OrderUsecase.CheckoutOrder(c.Request.Context(), &param)
 	if err != nil {
-		log.Logger.WithFields(logrus.Fields{
+		log.WithContext(c.Request.Context()).WithFields(logrus.Fields{
 			"param": param,
 		}).Errorf("h.OrderUsecase.CheckoutOrder() got error %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"order_id": orderID,
-		"status":   "created",
+	c.JSON(http.StatusOK, models.CheckoutResponse{
+		OrderID: orderID,
+		Status:  "created",
 	})
 }
 
@@ -81,17 +98,13 @@ func (h *OrderHandler) Checkout(c *gin.Context) {
 func (h *OrderHandler) GetOrderHistory(c *gin.Context) {
 	userIDStr, isExist := c.Get("user_id")
 	if !isExist {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error_message": "Unauthorized",
-		})
+		_ = c.Error(ErrUnauthorized)
 		return
 	}
 
 	userID, ok := userIDStr.(float64)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error_message": "Invalid user id",
-		})
+		_ = c.Error(ErrInvalidUserID)
 		return
 	}
 
@@ -103,7 +116,7 @@ func (h *OrderHandler) GetOrderHistory(c *gin.Context) {
 
 	histories, err := h.OrderUsecase.GetOrderHistory(c.Request.Context(), orderHistoryParam)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		_ = c.Error(err)
 		return
 	}
 
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	// golang package
+	"net/http"
+	"orderfc/infrastructure/validation"
+	"orderfc/models"
+	"strconv"
+
+	// external package
+	"github.com/gin-gonic/gin"
+)
+
+// AdminListSagas admin list sagas by given c pointer of gin.Context.
+func (h *OrderHandler) AdminListSagas(c *gin.Context) {
+	var param models.AdminSagaSearchParam
+	if err := c.ShouldBindQuery(&param); err != nil {
+		_ = c.Error(validation.FromError(err))
+		return
+	}
+
+	result, err := h.OrderUsecase.SearchSagas(c.Request.Context(), param)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+	})
+}
+
+// AdminGetOrderSaga admin get order saga by given c pointer of gin.Context.
+func (h *OrderHandler) AdminGetOrderSaga(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		_ = c.Error(ErrInvalidOrderID)
+		return
+	}
+
+	result, err := h.OrderUsecase.GetOrderSaga(c.Request.Context(), orderID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+	})
+}
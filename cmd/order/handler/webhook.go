@@ -0,0 +1,131 @@
+package handler
+
+import (
+	// golang package
+	"net/http"
+	"orderfc/infrastructure/apperror"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/validation"
+	"orderfc/models"
+	"strconv"
+
+	// external package
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrInvalidWebhookID  = apperror.Validation("invalid_webhook_id", "invalid webhook id")
+	ErrInvalidDeliveryID = apperror.Validation("invalid_delivery_id", "invalid delivery id")
+)
+
+// AdminCreateWebhook admin create webhook by given c pointer of gin.Context.
+func (h *OrderHandler) AdminCreateWebhook(c *gin.Context) {
+	var param models.AdminCreateWebhookRequest
+	if err := c.ShouldBindJSON(&param); err != nil {
+		_ = c.Error(validation.FromError(err))
+		return
+	}
+
+	result, err := h.OrderUsecase.CreateWebhookSubscription(c.Request.Context(), param)
+	if err != nil {
+		log.WithContext(c.Request.Context()).WithFields(logrus.Fields{
+			"url":         param.URL,
+			"event_types": param.EventTypes,
+		}).Errorf("h.OrderUsecase.CreateWebhookSubscription() got error %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": result,
+	})
+}
+
+// AdminListWebhooks admin list webhooks by given c pointer of gin.Context.
+func (h *OrderHandler) AdminListWebhooks(c *gin.Context) {
+	result, err := h.OrderUsecase.GetWebhookSubscriptions(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+	})
+}
+
+// AdminDeleteWebhook admin delete webhook by given c pointer of gin.Context.
+func (h *OrderHandler) AdminDeleteWebhook(c *gin.Context) {
+	subscriptionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		_ = c.Error(ErrInvalidWebhookID)
+		return
+	}
+
+	err = h.OrderUsecase.DeleteWebhookSubscription(c.Request.Context(), subscriptionID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AdminDeleteWebhookResponse{
+		ID:     subscriptionID,
+		Status: "deactivated",
+	})
+}
+
+// AdminListWebhookDeliveries admin list webhook deliveries by given c pointer of gin.Context.
+func (h *OrderHandler) AdminListWebhookDeliveries(c *gin.Context) {
+	subscriptionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		_ = c.Error(ErrInvalidWebhookID)
+		return
+	}
+
+	var param models.AdminWebhookDeliveryParam
+	if err := c.ShouldBindQuery(&param); err != nil {
+		_ = c.Error(validation.FromError(err))
+		return
+	}
+
+	result, err := h.OrderUsecase.GetWebhookDeliveries(c.Request.Context(), subscriptionID, param)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+	})
+}
+
+// AdminReplayWebhookDelivery admin replay webhook delivery by given c pointer of gin.Context.
+func (h *OrderHandler) AdminReplayWebhookDelivery(c *gin.Context) {
+	subscriptionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		_ = c.Error(ErrInvalidWebhookID)
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		_ = c.Error(ErrInvalidDeliveryID)
+		return
+	}
+
+	err = h.OrderUsecase.ReplayWebhookDelivery(c.Request.Context(), subscriptionID, deliveryID)
+	if err != nil {
+		log.WithContext(c.Request.Context()).WithFields(logrus.Fields{
+			"webhook_id":  subscriptionID,
+			"delivery_id": deliveryID,
+		}).Errorf("h.OrderUsecase.ReplayWebhookDelivery() got error %v", err)
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AdminReplayWebhookDeliveryResponse{
+		DeliveryID: deliveryID,
+		Status:     "pending",
+	})
+}
@@ -11,6 +11,7 @@ import (
 
 	// external package
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // InsertOrderDetailTx insert order detail tx by given tx pointer of gorm.DB, and detail pointer of models.OrderDetail.
@@ -78,31 +79,20 @@ func (r *OrderRepository) SaveIdempotencyToken(ctx context.Context, token string
 	return r.Database.WithContext(ctx).Table("order_request_log").Create(&log).Error
 }
 
-// UpdateOrderStatus update order status by given orderID, and status.
-//
-// It returns nil error when successful.
-// Otherwise, error will be returned.
-func (r *OrderRepository) UpdateOrderStatus(ctx context.Context, orderID int64, status int) error {
-	err := r.Database.Table("orders").WithContext(ctx).Model(&models.Order{}).
-		Updates(map[string]interface{}{
-			"status":      status,
-			"update_time": time.Now(),
-		}).Where("order_id = ?", orderID).Error
-
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
 // GetOrderInfoByOrderID get order info by order id by given orderID.
 //
+// An order the replica does not have yet, or one written within the
+// read-your-writes window, is read from the primary.
+//
 // It returns models.Order, and nil error when successful.
 // Otherwise, empty models.Order, and error will be returned.
 func (r *OrderRepository) GetOrderInfoByOrderID(ctx context.Context, orderID int64) (models.Order, error) {
 	var result models.Order
-	err := r.Database.Table("orders").WithContext(ctx).Where("order_id = ?", orderID).Find(&result).Error
+	err := r.ReadRouter.ReadOrderRow(ctx, orderID, func(db *gorm.DB) (bool, error) {
+		result = models.Order{}
+		err := db.Table("orders").WithContext(ctx).Where("id = ?", orderID).Find(&result).Error
+		return result.ID != 0, err
+	})
 	if err != nil {
 		return models.Order{}, err
 	}
@@ -110,13 +100,20 @@ func (r *OrderRepository) GetOrderInfoByOrderID(ctx context.Context, orderID int
 	return result, nil
 }
 
-// GetOrderDetailByOrderDetailID get order detail by order detail id by given orderDetailID.
+// GetOrderDetailByOrderDetailID get order detail by order detail id by given userID owning the order, and orderDetailID.
+//
+// An order detail the replica does not have yet, or one of a user who wrote
+// within the read-your-writes window, is read from the primary.
 //
 // It returns models.OrderDetail, and nil error when successful.
 // Otherwise, empty models.OrderDetail, and error will be returned.
-func (r *OrderRepository) GetOrderDetailByOrderDetailID(ctx context.Context, orderDetailID int64) (models.OrderDetail, error) {
+func (r *OrderRepository) GetOrderDetailByOrderDetailID(ctx context.Context, userID int64, orderDetailID int64) (models.OrderDetail, error) {
 	var result models.OrderDetail
-	err := r.Database.Table("order_detail").WithContext(ctx).Where("id = ?", orderDetailID).Find(&result).Error
+	err := r.ReadRouter.ReadRow(ctx, userID, func(db *gorm.DB) (bool, error) {
+		result = models.OrderDetail{}
+		err := db.Table("order_detail").WithContext(ctx).Where("id = ?", orderDetailID).Find(&result).Error
+		return result.ID != 0, err
+	})
 	if err != nil {
 		return models.OrderDetail{}, err
 	}
@@ -131,20 +128,23 @@ func (r *OrderRepository) GetOrderDetailByOrderDetailID(ctx context.Context, ord
 func (r *OrderRepository) GetOrderHistoriesByUserID(ctx context.Context, param models.OrderHistoryParam) ([]models.OrderHistoryResponse, error) {
 	var results []models.OrderJoinResult
 
-	query := r.Database.WithContext(ctx).
-		Table("orders AS o").
-		Select(`o.id, o.amount, o.total_qty, o.status, o.payment_method, o.shipping_address,
+	err := r.ReadRouter.Read(ctx, param.UserID, func(db *gorm.DB) error {
+		results = nil
+		query := db.WithContext(ctx).
+			Table("orders AS o").
+			Select(`o.id, o.amount, o.total_qty, o.status, o.payment_method, o.shipping_address,
 	        d.products, d.order_history`).
-		Joins("JOIN order_detail d ON o.order_detail_id = d.id").
-		Where("o.user_id = ?", param.UserID)
+			Joins("JOIN order_detail d ON o.order_detail_id = d.id").
+			Where("o.user_id = ?", param.UserID)
 
-	if param.Status > 0 {
-		query = query.Where("o.status = ?", param.Status)
-	}
+		if param.Status > 0 {
+			query = query.Where("o.status = ?", param.Status)
+		}
 
-	err := query.
-		Order("o.id DESC").
-		Scan(&results).Error
+		return query.
+			Order("o.id DESC").
+			Scan(&results).Error
+	})
 
 	if err != nil {
 		return nil, err
@@ -173,3 +173,115 @@ func (r *OrderRepository) GetOrderHistoriesByUserID(ctx context.Context, param m
 
 	return response, nil
 }
+
+// SearchOrders search orders by given param of models.AdminOrderSearchParam.
+//
+// It returns slice of models.Order, int64, and nil error when successful.
+// Otherwise, nil value of models.Order slice, empty int64, and error will be returned.
+func (r *OrderRepository) SearchOrders(ctx context.Context, param models.AdminOrderSearchParam) ([]models.Order, int64, error) {
+	var total int64
+	var results []models.Order
+
+	err := r.ReadRouter.Read(ctx, 0, func(db *gorm.DB) error {
+		results = nil
+		query := db.WithContext(ctx).Table("orders")
+
+		if param.UserID > 0 {
+			query = query.Where("user_id = ?", param.UserID)
+		}
+
+		if param.Status != nil {
+			query = query.Where("status = ?", *param.Status)
+		}
+
+		if param.PaymentMethod != "" {
+			query = query.Where("payment_method = ?", param.PaymentMethod)
+		}
+
+		query = query.Session(&gorm.Session{})
+
+		err := query.Count(&total).Error
+		if err != nil {
+			return err
+		}
+
+		return query.
+			Order("id DESC").
+			Limit(param.PageSize).
+			Offset((param.Page - 1) * param.PageSize).
+			Find(&results).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return results, total, nil
+}
+
+// GetOrderDetailForUpdateTx get order detail for update tx by given tx pointer of gorm.DB, and orderDetailID.
+//
+// It returns models.OrderDetail, and nil error when successful.
+// Otherwise, empty models.OrderDetail, and error will be returned.
+func (r *OrderRepository) GetOrderDetailForUpdateTx(ctx context.Context, tx *gorm.DB, orderDetailID int64) (models.OrderDetail, error) {
+	var result models.OrderDetail
+	err := tx.WithContext(ctx).Table("order_detail").
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", orderDetailID).
+		First(&result).Error
+	if err != nil {
+		return models.OrderDetail{}, err
+	}
+
+	return result, nil
+}
+
+// UpdateOrderStatusTx update order status tx by given tx pointer of gorm.DB, orderID, and status.
+//
+// It returns int64 of the user id of the order, and nil error when successful.
+// Otherwise, 0, and error will be returned.
+func (r *OrderRepository) UpdateOrderStatusTx(ctx context.Context, tx *gorm.DB, orderID int64, status int) (int64, error) {
+	var order models.Order
+	err := tx.WithContext(ctx).Table("orders").Model(&order).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "user_id"}}}).
+		Where("id = ?", orderID).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"update_time": time.Now(),
+		}).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return order.UserID, nil
+}
+
+// UpdateOrderStatusFromTx update order status from tx by given tx pointer of gorm.DB, orderID, from, and to status.
+//
+// The order is only updated while it still has the from status, so a
+// concurrent change wins instead of being overwritten.
+//
+// It returns true, and nil error when the order was updated.
+// Otherwise, false, and error if any will be returned.
+func (r *OrderRepository) UpdateOrderStatusFromTx(ctx context.Context, tx *gorm.DB, orderID int64, from int, to int) (bool, error) {
+	result := tx.WithContext(ctx).Table("orders").
+		Where("id = ? AND status = ?", orderID, from).
+		Updates(map[string]interface{}{
+			"status":      to,
+			"update_time": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateOrderHistoryTx update order history tx by given tx pointer of gorm.DB, orderDetailID, and orderHistory.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *OrderRepository) UpdateOrderHistoryTx(ctx context.Context, tx *gorm.DB, orderDetailID int64, orderHistory string) error {
+	return tx.WithContext(ctx).Table("order_detail").
+		Where("id = ?", orderDetailID).
+		Update("order_history", orderHistory).Error
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	// golang package
+	"context"
+	"encoding/json"
+	"errors"
+	"orderfc/infrastructure/log"
+	"orderfc/models"
+	"strconv"
+	"sync"
+	"time"
+
+	// external package
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	orderEventsChannel          = "orderfc:order_events"
+	orderEventsKeyPrefix        = "orderfc:order_events:backlog:"
+	orderEventSequenceKeyPrefix = "orderfc:order_events:seq:"
+
+	defaultOrderEventBacklogSize = 50
+	defaultOrderEventBacklogTTL  = time.Hour
+
+	// a subscriber falling this far behind is dropped, its client resumes from the backlog
+	orderEventSubscriberBuffer = 16
+)
+
+var errOrderEventsNoRedis = errors.New("order events need redis")
+
+// publishOrderEventScript numbers the event with the order's next sequence,
+// keeps it in the order's backlog and publishes it, all in one step so every
+// instance sees the events of an order in the order of their ids.
+var publishOrderEventScript = redis.NewScript(`
+local id = redis.call('INCR', KEYS[1])
+local event = '{"id":' .. id .. ',' .. string.sub(ARGV[1], 2)
+local ttl = tonumber(ARGV[3])
+
+redis.call('ZADD', KEYS[2], id, event)
+redis.call('ZREMRANGEBYRANK', KEYS[2], 0, -(tonumber(ARGV[2]) + 1))
+redis.call('PEXPIRE', KEYS[1], ttl)
+redis.call('PEXPIRE', KEYS[2], ttl)
+redis.call('PUBLISH', ARGV[4], event)
+
+return id
+`)
+
+// PublishOrderStatusEvent publish order status event by given event of models.OrderStatusEvent.
+//
+// The event gets the next id of its order, is added to the order's backlog
+// for clients resuming with Last-Event-ID and is published to every instance.
+//
+// It returns int64 of the event id, and nil error when successful.
+// Otherwise, 0, and error will be returned.
+func (r *OrderRepository) PublishOrderStatusEvent(ctx context.Context, event models.OrderStatusEvent) (int64, error) {
+	if r.Redis == nil {
+		return 0, errOrderEventsNoRedis
+	}
+
+	// the id is assigned by the script
+	event.ID = 0
+	data, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+
+	keys := []string{orderEventSequenceKey(event.OrderID), orderEventsKey(event.OrderID)}
+	return publishOrderEventScript.Run(ctx, r.Redis, keys,
+		string(data), r.orderEventBacklogSize(), r.orderEventBacklogTTL().Milliseconds(), orderEventsChannel).Int64()
+}
+
+// GetOrderStatusEventID get order status event id by given orderID.
+//
+// It returns int64 of the id of the latest event of the order, 0 when it has none, and nil error when successful.
+// Otherwise, 0, and error will be returned.
+func (r *OrderRepository) GetOrderStatusEventID(ctx context.Context, orderID int64) (int64, error) {
+	if r.Redis == nil {
+		return 0, errOrderEventsNoRedis
+	}
+
+	id, err := r.Redis.Get(ctx, orderEventSequenceKey(orderID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	return id, err
+}
+
+// GetOrderStatusEvents get order status events by given orderID, and afterID.
+//
+// Only the events still in the order's backlog are returned, oldest first.
+//
+// It returns slice of models.OrderStatusEvent with an id above afterID, and nil error when successful.
+// Otherwise, nil value of models.OrderStatusEvent slice, and error will be returned.
+func (r *OrderRepository) GetOrderStatusEvents(ctx context.Context, orderID int64, afterID int64) ([]models.OrderStatusEvent, error) {
+	if r.Redis == nil {
+		return nil, errOrderEventsNoRedis
+	}
+
+	values, err := r.Redis.ZRangeByScore(ctx, orderEventsKey(orderID), &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(afterID, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]models.OrderStatusEvent, 0, len(values))
+	for _, value := range values {
+		var event models.OrderStatusEvent
+		err = json.Unmarshal([]byte(value), &event)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// SubscribeOrderStatusEvents subscribe order status events by given orderID.
+//
+// The instance shares one Redis subscription between all its subscribers,
+// which is opened by the first one. Events are delivered from the moment
+// this returns. The channel is closed by the returned func, or early when
+// the subscriber does not keep up.
+//
+// It returns receive only channel of models.OrderStatusEvent, func to unsubscribe, and nil error when successful.
+// Otherwise, nil channel, nil func, and error will be returned.
+func (r *OrderRepository) SubscribeOrderStatusEvents(ctx context.Context, orderID int64) (<-chan models.OrderStatusEvent, func(), error) {
+	if r.Redis == nil {
+		return nil, nil, errOrderEventsNoRedis
+	}
+
+	return r.orderEvents.subscribe(ctx, r.Redis, orderID)
+}
+
+// orderEventBacklogSize order event backlog size.
+//
+// It returns int of the configured backlog size, or its default.
+func (r *OrderRepository) orderEventBacklogSize() int {
+	if r.OrderEventsConfig.BacklogSize > 0 {
+		return r.OrderEventsConfig.BacklogSize
+	}
+
+	return defaultOrderEventBacklogSize
+}
+
+// orderEventBacklogTTL order event backlog ttl.
+//
+// It returns time.Duration of the configured backlog ttl, or its default.
+func (r *OrderRepository) orderEventBacklogTTL() time.Duration {
+	if r.OrderEventsConfig.BacklogTTL > 0 {
+		return r.OrderEventsConfig.BacklogTTL
+	}
+
+	return defaultOrderEventBacklogTTL
+}
+
+// orderEventHub fans the events of the shared Redis subscription out to the
+// subscribers of their order.
+type orderEventHub struct {
+	mu          sync.Mutex
+	pubsub      *redis.PubSub
+	subscribers map[int64]map[chan models.OrderStatusEvent]struct{}
+}
+
+// newOrderEventHub new order event hub.
+//
+// It returns pointer of orderEventHub.
+func newOrderEventHub() *orderEventHub {
+	return &orderEventHub{
+		subscribers: map[int64]map[chan models.OrderStatusEvent]struct{}{},
+	}
+}
+
+// subscribe subscribe by given client pointer of redis.Client, and orderID.
+//
+// It returns receive only channel of models.OrderStatusEvent, func to unsubscribe, and nil error when successful.
+// Otherwise, nil channel, nil func, and error will be returned.
+func (h *orderEventHub) subscribe(ctx context.Context, client *redis.Client, orderID int64) (<-chan models.OrderStatusEvent, func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.pubsub == nil {
+		pubsub := client.Subscribe(ctx, orderEventsChannel)
+
+		// wait for the confirmation, so no event published after this returns is missed
+		_, err := pubsub.Receive(ctx)
+		if err != nil {
+			_ = pubsub.Close()
+			return nil, nil, err
+		}
+
+		h.pubsub = pubsub
+		go h.run(pubsub)
+	}
+
+	events := make(chan models.OrderStatusEvent, orderEventSubscriberBuffer)
+	if h.subscribers[orderID] == nil {
+		h.subscribers[orderID] = map[chan models.OrderStatusEvent]struct{}{}
+	}
+	h.subscribers[orderID][events] = struct{}{}
+
+	return events, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(orderID, events)
+	}, nil
+}
+
+// run run by given pubsub pointer of redis.PubSub.
+//
+// It dispatches the published events until the subscription is closed.
+// go-redis resubscribes by itself after a lost connection.
+func (h *orderEventHub) run(pubsub *redis.PubSub) {
+	for message := range pubsub.Channel() {
+		var event models.OrderStatusEvent
+		err := json.Unmarshal([]byte(message.Payload), &event)
+		if err != nil {
+			log.Logger.Errorf("failed to decode order event %q: %v", message.Payload, err)
+			continue
+		}
+
+		h.dispatch(event)
+	}
+}
+
+// dispatch dispatch by given event of models.OrderStatusEvent.
+func (h *orderEventHub) dispatch(event models.OrderStatusEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for events := range h.subscribers[event.OrderID] {
+		select {
+		case events <- event:
+		default:
+			h.remove(event.OrderID, events)
+		}
+	}
+}
+
+// remove remove by given orderID, and events channel.
+//
+// It must be called with the lock held.
+func (h *orderEventHub) remove(orderID int64, events chan models.OrderStatusEvent) {
+	if _, ok := h.subscribers[orderID][events]; !ok {
+		return
+	}
+
+	delete(h.subscribers[orderID], events)
+	if len(h.subscribers[orderID]) == 0 {
+		delete(h.subscribers, orderID)
+	}
+
+	close(events)
+}
+
+// orderEventsKey order events key by given orderID.
+//
+// It returns string of the redis key of the order's backlog.
+func orderEventsKey(orderID int64) string {
+	return orderEventsKeyPrefix + strconv.FormatInt(orderID, 10)
+}
+
+// orderEventSequenceKey order event sequence key by given orderID.
+//
+// It returns string of the redis key of the order's latest event id.
+func orderEventSequenceKey(orderID int64) string {
+	return orderEventSequenceKeyPrefix + strconv.FormatInt(orderID, 10)
+}
@@ -1 +1,211 @@
 package repository
+
+import (
+	// golang package
+	"context"
+	"encoding/json"
+	"errors"
+	"orderfc/infrastructure/breaker"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/metrics"
+	"orderfc/models"
+	"strconv"
+
+	// external package
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	orderHistoryCacheKeyPrefix = "orderfc:cache:order_history:"
+	orderCacheKeyPrefix        = "orderfc:cache:order:"
+
+	cacheOrderHistory = "order_history"
+	cacheOrder        = "order"
+)
+
+// GetCachedOrderHistoriesByUserID get cached order histories by user id by given param of models.OrderHistoryParam.
+//
+// A miss is loaded from the database once per key no matter how many
+// requests are waiting for it, then written back to Redis. Every status
+// filter of a user lives in one hash so a single delete invalidates them all.
+// Results read while the user has a recent write are not cached, since the
+// load may have started before the write and its invalidation.
+//
+// It returns slice of models.OrderHistoryResponse, and nil error when successful.
+// Otherwise, nil value of models.OrderHistoryResponse slice, and error will be returned.
+func (r *OrderRepository) GetCachedOrderHistoriesByUserID(ctx context.Context, param models.OrderHistoryParam) ([]models.OrderHistoryResponse, error) {
+	if !r.cacheEnabled() {
+		return r.GetOrderHistoriesByUserID(ctx, param)
+	}
+
+	key := orderHistoryCacheKey(param.UserID)
+	field := strconv.Itoa(param.Status)
+
+	var cached []models.OrderHistoryResponse
+	hit, _ := r.getCache(ctx, cacheOrderHistory, func() (string, error) {
+		return r.Redis.HGet(ctx, key, field).Result()
+	}, &cached)
+	if hit {
+		return cached, nil
+	}
+
+	value, err, _ := r.loadGroup.Do(key+":"+field, func() (interface{}, error) {
+		// shared by every waiting request, so it must not be cancelled by the first one
+		loadCtx := context.WithoutCancel(ctx)
+		histories, err := r.GetOrderHistoriesByUserID(loadCtx, param)
+		if err != nil {
+			return nil, err
+		}
+
+		if !r.ReadRouter.wroteRecently(loadCtx, userWriteKey(param.UserID)) {
+			r.setCache(loadCtx, histories, func(data []byte) error {
+				_, err := r.Redis.TxPipelined(loadCtx, func(pipe redis.Pipeliner) error {
+					pipe.HSet(loadCtx, key, field, data)
+					pipe.Expire(loadCtx, key, r.CacheConfig.OrderHistoryTTL)
+					return nil
+				})
+				return err
+			})
+		}
+
+		return histories, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.([]models.OrderHistoryResponse), nil
+}
+
+// GetCachedOrderInfoByOrderID get cached order info by order id by given orderID.
+//
+// Reads asking for the primary bypass the cache.
+//
+// It returns models.Order, and nil error when successful.
+// Otherwise, empty models.Order, and error will be returned.
+func (r *OrderRepository) GetCachedOrderInfoByOrderID(ctx context.Context, orderID int64) (models.Order, error) {
+	if !r.cacheEnabled() || primaryReadRequested(ctx) {
+		return r.GetOrderInfoByOrderID(ctx, orderID)
+	}
+
+	key := orderCacheKey(orderID)
+
+	var cached models.Order
+	hit, _ := r.getCache(ctx, cacheOrder, func() (string, error) {
+		return r.Redis.Get(ctx, key).Result()
+	}, &cached)
+	if hit {
+		return cached, nil
+	}
+
+	value, err, _ := r.loadGroup.Do(key, func() (interface{}, error) {
+		loadCtx := context.WithoutCancel(ctx)
+		order, err := r.GetOrderInfoByOrderID(loadCtx, orderID)
+		if err != nil {
+			return nil, err
+		}
+
+		// missing orders are not cached so a freshly created one is found right away
+		if order.ID != 0 && !r.ReadRouter.wroteRecently(loadCtx, userWriteKey(order.UserID)) {
+			r.setCache(loadCtx, order, func(data []byte) error {
+				return r.Redis.Set(loadCtx, key, data, r.CacheConfig.OrderTTL).Err()
+			})
+		}
+
+		return order, nil
+	})
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	return value.(models.Order), nil
+}
+
+// OnOrderWritten on order written by given orderID, and userID.
+//
+// It must be called after every committed change to an order. The reads of
+// the user and of the order are pinned to the primary for the read-your-writes
+// window and the cached order and order history are dropped.
+func (r *OrderRepository) OnOrderWritten(ctx context.Context, orderID int64, userID int64) {
+	r.ReadRouter.MarkWrite(ctx, userID, orderID)
+
+	if !r.cacheEnabled() {
+		return
+	}
+
+	keys := []string{orderHistoryCacheKey(userID)}
+	if orderID > 0 {
+		keys = append(keys, orderCacheKey(orderID))
+	}
+
+	err := r.Redis.Del(ctx, keys...).Err()
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to invalidate order cache %v: %v", keys, err)
+	}
+}
+
+// getCache get cache by given name, get func, and dest.
+//
+// Redis errors and undecodable entries are treated as a miss so the cache
+// never fails a request.
+//
+// It returns true when dest was filled from the cache.
+// Otherwise, false, and the redis error if any will be returned.
+func (r *OrderRepository) getCache(ctx context.Context, name string, get func() (string, error), dest interface{}) (bool, error) {
+	data, err := get()
+	if errors.Is(err, redis.Nil) {
+		metrics.CacheRequestsTotal.WithLabelValues(name, metrics.CacheMiss).Inc()
+		return false, nil
+	}
+
+	if err == nil {
+		err = json.Unmarshal([]byte(data), dest)
+	}
+
+	if err != nil {
+		// an open breaker is already reported by its own metrics
+		if !errors.Is(err, breaker.ErrOpen) {
+			log.WithContext(ctx).Warnf("failed to read %s cache: %v", name, err)
+		}
+
+		metrics.CacheRequestsTotal.WithLabelValues(name, metrics.CacheError).Inc()
+		return false, err
+	}
+
+	metrics.CacheRequestsTotal.WithLabelValues(name, metrics.CacheHit).Inc()
+	return true, nil
+}
+
+// setCache set cache by given value, and set func.
+func (r *OrderRepository) setCache(ctx context.Context, value interface{}, set func(data []byte) error) {
+	data, err := json.Marshal(value)
+	if err == nil {
+		err = set(data)
+	}
+
+	if err != nil {
+		log.WithContext(ctx).Warnf("failed to write cache: %v", err)
+	}
+}
+
+// cacheEnabled cache enabled.
+//
+// It returns true when caching is enabled and redis is configured.
+// Otherwise, false will be returned.
+func (r *OrderRepository) cacheEnabled() bool {
+	return r.CacheConfig.Enabled && r.Redis != nil
+}
+
+// orderHistoryCacheKey order history cache key by given userID.
+//
+// It returns string of the redis key.
+func orderHistoryCacheKey(userID int64) string {
+	return orderHistoryCacheKeyPrefix + strconv.FormatInt(userID, 10)
+}
+
+// orderCacheKey order cache key by given orderID.
+//
+// It returns string of the redis key.
+func orderCacheKey(orderID int64) string {
+	return orderCacheKeyPrefix + strconv.FormatInt(orderID, 10)
+}
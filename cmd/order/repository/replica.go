@@ -0,0 +1,257 @@
+package repository
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/metrics"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	// external package
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	recentWriteKeyPrefix = "orderfc:recent_write:"
+	replicaCooldown      = 10 * time.Second
+)
+
+type primaryReadKey struct{}
+
+type replica struct {
+	db *gorm.DB
+
+	// downUntil holds the unix nano time until which the replica is skipped after a failure
+	downUntil atomic.Int64
+}
+
+type ReadRouter struct {
+	primary  *gorm.DB
+	replicas []*replica
+	next     atomic.Uint64
+
+	redis  *redis.Client
+	window time.Duration
+}
+
+// NewReadRouter new read router by given primary pointer of gorm.DB, slice of replicas, redis pointer of redis.Client, and readYourWritesWindow.
+//
+// It returns pointer of ReadRouter when successful.
+// Otherwise, nil pointer of ReadRouter will be returned.
+func NewReadRouter(primary *gorm.DB, replicas []*gorm.DB, redis *redis.Client, readYourWritesWindow time.Duration) *ReadRouter {
+	router := &ReadRouter{
+		primary: primary,
+		redis:   redis,
+		window:  readYourWritesWindow,
+	}
+
+	for _, db := range replicas {
+		router.replicas = append(router.replicas, &replica{db: db})
+	}
+
+	return router
+}
+
+// ContextWithPrimaryRead context with primary read by given ctx.
+//
+// Reads with the returned context skip the replicas and the cache, for
+// callers that must see a write committed just before, e.g. by another
+// instance.
+//
+// It returns context.Context.
+func ContextWithPrimaryRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadKey{}, true)
+}
+
+// primaryReadRequested primary read requested by given ctx.
+//
+// It returns true when ctx was made by ContextWithPrimaryRead.
+// Otherwise, false will be returned.
+func primaryReadRequested(ctx context.Context) bool {
+	requested, _ := ctx.Value(primaryReadKey{}).(bool)
+	return requested
+}
+
+// Read read by given userID, and fn.
+//
+// fn runs on the next healthy replica in round robin order and is retried on
+// the primary when it fails. Reads stay on the primary when no replica is
+// configured, when ctx asks for it, or when userID wrote within the
+// read-your-writes window. A replica lagging behind answers without an error,
+// so lookups of a single row use ReadRow instead.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *ReadRouter) Read(ctx context.Context, userID int64, fn func(db *gorm.DB) error) error {
+	target := r.pick(ctx, userWriteKey(userID))
+	if target == nil {
+		return fn(r.primary)
+	}
+
+	err := fn(target.db)
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) || ctx.Err() != nil {
+		return err
+	}
+
+	r.markDown(ctx, target, err)
+	return fn(r.primary)
+}
+
+// ReadRow read row by given userID, and fn reporting whether it found the row.
+//
+// Like Read, but a row missing on the replica is looked up again on the
+// primary, since it may have been written before the replica caught up.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *ReadRouter) ReadRow(ctx context.Context, userID int64, fn func(db *gorm.DB) (bool, error)) error {
+	return r.readRow(ctx, userWriteKey(userID), fn)
+}
+
+// ReadOrderRow read order row by given orderID, and fn reporting whether it found the row.
+//
+// Like ReadRow, for lookups by id that do not know the order's user yet. The
+// read stays on the primary while the order itself was written within the
+// read-your-writes window.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *ReadRouter) ReadOrderRow(ctx context.Context, orderID int64, fn func(db *gorm.DB) (bool, error)) error {
+	return r.readRow(ctx, orderWriteKey(orderID), fn)
+}
+
+// readRow read row by given writeKey marking recent writes, and fn reporting whether it found the row.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *ReadRouter) readRow(ctx context.Context, writeKey string, fn func(db *gorm.DB) (bool, error)) error {
+	target := r.pick(ctx, writeKey)
+	if target == nil {
+		_, err := fn(r.primary)
+		return err
+	}
+
+	found, err := fn(target.db)
+	switch {
+	case ctx.Err() != nil, err == nil && found, errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	case err == nil:
+		metrics.DBReplicaMissTotal.Inc()
+	default:
+		r.markDown(ctx, target, err)
+	}
+
+	_, err = fn(r.primary)
+	return err
+}
+
+// markDown mark down by given target pointer of replica, and err of the failed read.
+//
+// The replica is skipped for the cooldown, its reads go to the other
+// replicas or the primary.
+func (r *ReadRouter) markDown(ctx context.Context, target *replica, err error) {
+	log.WithContext(ctx).Warnf("DB replica read failed, falling back to primary: %v", err)
+	target.downUntil.Store(time.Now().Add(replicaCooldown).UnixNano())
+	metrics.DBReplicaFallbackTotal.Inc()
+}
+
+// MarkWrite mark write by given userID, and orderID.
+//
+// It pins the reads of the user and of the order by id to the primary for the
+// read-your-writes window and keeps reads started before the write out of the
+// cache. The markers live in Redis so every instance sees them; when Redis is
+// unavailable the user may briefly read stale data from a replica.
+func (r *ReadRouter) MarkWrite(ctx context.Context, userID int64, orderID int64) {
+	if r.window <= 0 || r.redis == nil {
+		return
+	}
+
+	var keys []string
+	for _, key := range []string{userWriteKey(userID), orderWriteKey(orderID)} {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return
+	}
+
+	_, err := r.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Set(ctx, key, 1, r.window)
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.WithContext(ctx).Warnf("failed to mark recent write for user %d, order %d: %v", userID, orderID, err)
+	}
+}
+
+// pick pick by given writeKey marking recent writes.
+//
+// It returns pointer of replica to read from.
+// Otherwise, nil pointer of replica will be returned when the primary must be used.
+func (r *ReadRouter) pick(ctx context.Context, writeKey string) *replica {
+	if len(r.replicas) == 0 || primaryReadRequested(ctx) || r.wroteRecently(ctx, writeKey) {
+		return nil
+	}
+
+	now := time.Now().UnixNano()
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		candidate := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if now >= candidate.downUntil.Load() {
+			return candidate
+		}
+	}
+
+	return nil
+}
+
+// wroteRecently wrote recently by given writeKey of userWriteKey or orderWriteKey.
+//
+// It returns true when writeKey was marked within the read-your-writes
+// window, or when that cannot be checked.
+// Otherwise, false will be returned.
+func (r *ReadRouter) wroteRecently(ctx context.Context, writeKey string) bool {
+	if r.window <= 0 || writeKey == "" || r.redis == nil {
+		return false
+	}
+
+	count, err := r.redis.Exists(ctx, writeKey).Result()
+	if err != nil {
+		return true
+	}
+
+	return count > 0
+}
+
+// userWriteKey user write key by given userID.
+//
+// It returns string of the redis key.
+// Otherwise, empty string will be returned when userID is unknown.
+func userWriteKey(userID int64) string {
+	if userID <= 0 {
+		return ""
+	}
+
+	return recentWriteKeyPrefix + "user:" + strconv.FormatInt(userID, 10)
+}
+
+// orderWriteKey order write key by given orderID.
+//
+// It returns string of the redis key.
+// Otherwise, empty string will be returned when orderID is unknown.
+func orderWriteKey(orderID int64) string {
+	if orderID <= 0 {
+		return ""
+	}
+
+	return recentWriteKeyPrefix + "order:" + strconv.FormatInt(orderID, 10)
+}
@@ -1,23 +1,41 @@
 package repository
 
 import (
+	// golang package
+	"orderfc/config"
+	"time"
+
 	// external package
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
 )
 
 type OrderRepository struct {
-	Database *gorm.DB
-	Redis    *redis.Client
+	Database          *gorm.DB
+	Redis             *redis.Client
+	ReadRouter        *ReadRouter
+	CacheConfig       config.CacheConfig
+	OrderEventsConfig config.OrderEventsConfig
+	WebhookConfig     config.WebhookConfig
+
+	loadGroup   *singleflight.Group
+	orderEvents *orderEventHub
 }
 
-// NewOrderRepository new order repository by given db pointer of gorm.DB, and redis pointer of redis.Client.
+// NewOrderRepository new order repository by given db pointer of gorm.DB, slice of replicas, redis pointer of redis.Client, readYourWritesWindow, cacheConfig of config.CacheConfig, orderEventsConfig of config.OrderEventsConfig, and webhookConfig of config.WebhookConfig.
 //
 // It returns pointer of OrderRepository when successful.
 // Otherwise, nil pointer of OrderRepository will be returned.
-func NewOrderRepository(db *gorm.DB, redis *redis.Client) *OrderRepository {
+func NewOrderRepository(db *gorm.DB, replicas []*gorm.DB, redis *redis.Client, readYourWritesWindow time.Duration, cacheConfig config.CacheConfig, orderEventsConfig config.OrderEventsConfig, webhookConfig config.WebhookConfig) *OrderRepository {
 	return &OrderRepository{
-		Database: db,
-		Redis:    redis,
+		Database:          db,
+		Redis:             redis,
+		ReadRouter:        NewReadRouter(db, replicas, redis, readYourWritesWindow),
+		CacheConfig:       cacheConfig,
+		OrderEventsConfig: orderEventsConfig,
+		WebhookConfig:     webhookConfig,
+		loadGroup:         &singleflight.Group{},
+		orderEvents:       newOrderEventHub(),
 	}
 }
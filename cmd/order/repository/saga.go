@@ -0,0 +1,139 @@
+package repository
+
+import (
+	// golang package
+	"context"
+	"orderfc/infrastructure/constant"
+	"orderfc/models"
+	"time"
+
+	// external package
+	"gorm.io/gorm"
+)
+
+// claimCheckoutSagasQuery takes the due sagas that have not finished and
+// pushes their deadline past the lease, so another worker only picks them up
+// again once the lease ran out without a recorded result.
+const claimCheckoutSagasQuery = `
+UPDATE checkout_sagas
+SET version = version + 1, deadline_time = ?, update_time = ?
+WHERE id IN (
+	SELECT id FROM checkout_sagas
+	WHERE state IN (?, ?) AND deadline_time <= ?
+	ORDER BY deadline_time
+	LIMIT ?
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *`
+
+// InsertCheckoutSagaTx insert checkout saga tx by given tx pointer of gorm.DB, and saga pointer of models.CheckoutSaga.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *OrderRepository) InsertCheckoutSagaTx(ctx context.Context, tx *gorm.DB, saga *models.CheckoutSaga) error {
+	return tx.WithContext(ctx).Table("checkout_sagas").Create(saga).Error
+}
+
+// GetCheckoutSagaByOrderID get checkout saga by order id by given orderID.
+//
+// The saga is always read from the primary, its version guards the next write.
+//
+// It returns models.CheckoutSaga, which is empty when it does not exist, and nil error when successful.
+// Otherwise, empty models.CheckoutSaga, and error will be returned.
+func (r *OrderRepository) GetCheckoutSagaByOrderID(ctx context.Context, orderID int64) (models.CheckoutSaga, error) {
+	var result models.CheckoutSaga
+	err := r.Database.WithContext(ctx).Table("checkout_sagas").Where("order_id = ?", orderID).Find(&result).Error
+	if err != nil {
+		return models.CheckoutSaga{}, err
+	}
+
+	return result, nil
+}
+
+// ClaimCheckoutSagas claim checkout sagas by given limit, and lease.
+//
+// Sagas claimed by other workers are skipped instead of waited for.
+//
+// It returns slice of models.CheckoutSaga with their claimed version, and nil error when successful.
+// Otherwise, nil value of models.CheckoutSaga slice, and error will be returned.
+func (r *OrderRepository) ClaimCheckoutSagas(ctx context.Context, limit int, lease time.Duration) ([]models.CheckoutSaga, error) {
+	now := time.Now()
+
+	var results []models.CheckoutSaga
+	err := r.Database.WithContext(ctx).
+		Raw(claimCheckoutSagasQuery, now.Add(lease), now, constant.SagaStateRunning, constant.SagaStateCompensating, now, limit).
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// UpdateCheckoutSaga update checkout saga by given saga of models.CheckoutSaga.
+//
+// The saga is only written while it still has the version it was read with,
+// and its version is incremented, so of two concurrent writers only the
+// first one wins.
+//
+// It returns true, and nil error when the saga was updated.
+// Otherwise, false, and error if any will be returned.
+func (r *OrderRepository) UpdateCheckoutSaga(ctx context.Context, saga models.CheckoutSaga) (bool, error) {
+	result := r.Database.WithContext(ctx).Table("checkout_sagas").
+		Where("id = ? AND version = ?", saga.ID, saga.Version).
+		Updates(map[string]interface{}{
+			"state":         saga.State,
+			"step":          saga.Step,
+			"steps":         saga.Steps,
+			"attempts":      saga.Attempts,
+			"last_error":    saga.LastError,
+			"reason":        saga.Reason,
+			"deadline_time": saga.DeadlineTime,
+			"version":       saga.Version + 1,
+			"update_time":   time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// SearchCheckoutSagas search checkout sagas by given param of models.AdminSagaSearchParam.
+//
+// Overdue sagas have not finished although their deadline passed, i.e. no
+// worker picked them up in time.
+//
+// It returns slice of models.CheckoutSaga, newest first, int64 of the total, and nil error when successful.
+// Otherwise, nil value of models.CheckoutSaga slice, empty int64, and error will be returned.
+func (r *OrderRepository) SearchCheckoutSagas(ctx context.Context, param models.AdminSagaSearchParam) ([]models.CheckoutSaga, int64, error) {
+	var total int64
+	var results []models.CheckoutSaga
+
+	query := r.Database.WithContext(ctx).Table("checkout_sagas")
+	if param.State != "" {
+		query = query.Where("state = ?", param.State)
+	}
+
+	if param.Overdue {
+		query = query.Where("state IN ? AND deadline_time < ?", []string{constant.SagaStateRunning, constant.SagaStateCompensating}, time.Now())
+	}
+
+	query = query.Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.
+		Order("id DESC").
+		Limit(param.PageSize).
+		Offset((param.Page - 1) * param.PageSize).
+		Find(&results).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return results, total, nil
+}
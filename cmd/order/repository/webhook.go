@@ -0,0 +1,215 @@
+package repository
+
+import (
+	// golang package
+	"context"
+	"encoding/json"
+	"orderfc/infrastructure/constant"
+	"orderfc/models"
+	"time"
+
+	// external package
+	"gorm.io/gorm"
+)
+
+// claimWebhookDeliveriesQuery takes the due deliveries and pushes their next
+// attempt past the lease, so another worker only picks them up again once
+// the lease ran out without a recorded result.
+const claimWebhookDeliveriesQuery = `
+UPDATE webhook_deliveries
+SET attempts = attempts + 1, next_attempt_time = ?, update_time = ?
+WHERE id IN (
+	SELECT id FROM webhook_deliveries
+	WHERE status = ? AND next_attempt_time <= ?
+	ORDER BY next_attempt_time
+	LIMIT ?
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *`
+
+// InsertWebhookSubscription insert webhook subscription by given subscription pointer of models.WebhookSubscription.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *OrderRepository) InsertWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	return r.Database.WithContext(ctx).Table("webhook_subscriptions").Create(subscription).Error
+}
+
+// GetWebhookSubscriptions get webhook subscriptions.
+//
+// It returns slice of models.WebhookSubscription, and nil error when successful.
+// Otherwise, nil value of models.WebhookSubscription slice, and error will be returned.
+func (r *OrderRepository) GetWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	var results []models.WebhookSubscription
+	err := r.Database.WithContext(ctx).Table("webhook_subscriptions").Order("id").Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetWebhookSubscriptionByID get webhook subscription by id by given subscriptionID.
+//
+// It returns models.WebhookSubscription, which is empty when it does not exist, and nil error when successful.
+// Otherwise, empty models.WebhookSubscription, and error will be returned.
+func (r *OrderRepository) GetWebhookSubscriptionByID(ctx context.Context, subscriptionID int64) (models.WebhookSubscription, error) {
+	var result models.WebhookSubscription
+	err := r.Database.WithContext(ctx).Table("webhook_subscriptions").Where("id = ?", subscriptionID).Find(&result).Error
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	return result, nil
+}
+
+// DeactivateWebhookSubscription deactivate webhook subscription by given subscriptionID.
+//
+// Its pending deliveries are kept and fail once the worker picks them up.
+//
+// It returns true, and nil error when an active subscription was deactivated.
+// Otherwise, false, and error if any will be returned.
+func (r *OrderRepository) DeactivateWebhookSubscription(ctx context.Context, subscriptionID int64) (bool, error) {
+	result := r.Database.WithContext(ctx).Table("webhook_subscriptions").
+		Where("id = ? AND active", subscriptionID).
+		Updates(map[string]interface{}{
+			"active":      false,
+			"update_time": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// GetWebhookSubscriptionsByEventTypeTx get webhook subscriptions by event type tx by given tx pointer of gorm.DB, and eventType.
+//
+// It returns slice of the active models.WebhookSubscription subscribed to eventType, and nil error when successful.
+// Otherwise, nil value of models.WebhookSubscription slice, and error will be returned.
+func (r *OrderRepository) GetWebhookSubscriptionsByEventTypeTx(ctx context.Context, tx *gorm.DB, eventType string) ([]models.WebhookSubscription, error) {
+	eventTypes, err := json.Marshal([]string{eventType})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []models.WebhookSubscription
+	err = tx.WithContext(ctx).Table("webhook_subscriptions").
+		Where("active AND event_types @> ?::jsonb", string(eventTypes)).
+		Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// InsertWebhookDeliveriesTx insert webhook deliveries tx by given tx pointer of gorm.DB, and deliveries slice of models.WebhookDelivery.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *OrderRepository) InsertWebhookDeliveriesTx(ctx context.Context, tx *gorm.DB, deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return tx.WithContext(ctx).Table("webhook_deliveries").Create(&deliveries).Error
+}
+
+// ClaimWebhookDeliveries claim webhook deliveries by given limit, and lease.
+//
+// Every claimed delivery counts as an attempt. Deliveries claimed by other
+// workers are skipped instead of waited for.
+//
+// It returns slice of models.WebhookDelivery, and nil error when successful.
+// Otherwise, nil value of models.WebhookDelivery slice, and error will be returned.
+func (r *OrderRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	now := time.Now()
+
+	var results []models.WebhookDelivery
+	err := r.Database.WithContext(ctx).
+		Raw(claimWebhookDeliveriesQuery, now.Add(lease), now, constant.WebhookDeliveryPending, now, limit).
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// UpdateWebhookDeliveryResult update webhook delivery result by given delivery of models.WebhookDelivery.
+//
+// The result is only recorded for the attempt that was claimed, a result
+// arriving after the lease ran out and the delivery was claimed again is
+// dropped.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (r *OrderRepository) UpdateWebhookDeliveryResult(ctx context.Context, delivery models.WebhookDelivery) error {
+	return r.Database.WithContext(ctx).Table("webhook_deliveries").
+		Where("id = ? AND attempts = ?", delivery.ID, delivery.Attempts).
+		Updates(map[string]interface{}{
+			"status":            delivery.Status,
+			"next_attempt_time": delivery.NextAttemptTime,
+			"response_code":     delivery.ResponseCode,
+			"last_error":        delivery.LastError,
+			"update_time":       time.Now(),
+		}).Error
+}
+
+// SearchWebhookDeliveries search webhook deliveries by given subscriptionID, and param of models.AdminWebhookDeliveryParam.
+//
+// It returns slice of models.WebhookDelivery, newest first, int64 of the total, and nil error when successful.
+// Otherwise, nil value of models.WebhookDelivery slice, empty int64, and error will be returned.
+func (r *OrderRepository) SearchWebhookDeliveries(ctx context.Context, subscriptionID int64, param models.AdminWebhookDeliveryParam) ([]models.WebhookDelivery, int64, error) {
+	var total int64
+	var results []models.WebhookDelivery
+
+	query := r.Database.WithContext(ctx).Table("webhook_deliveries").Where("subscription_id = ?", subscriptionID)
+	if param.Status != "" {
+		query = query.Where("status = ?", param.Status)
+	}
+
+	query = query.Session(&gorm.Session{})
+
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = query.
+		Order("id DESC").
+		Limit(param.PageSize).
+		Offset((param.Page - 1) * param.PageSize).
+		Find(&results).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return results, total, nil
+}
+
+// ReplayWebhookDelivery replay webhook delivery by given subscriptionID, and deliveryID.
+//
+// A failed delivery is made due again with a fresh set of attempts. Its
+// event id and payload are unchanged, so receivers can recognise it.
+//
+// It returns true, and nil error when a failed delivery was replayed.
+// Otherwise, false, and error if any will be returned.
+func (r *OrderRepository) ReplayWebhookDelivery(ctx context.Context, subscriptionID int64, deliveryID int64) (bool, error) {
+	now := time.Now()
+
+	result := r.Database.WithContext(ctx).Table("webhook_deliveries").
+		Where("id = ? AND subscription_id = ? AND status = ?", deliveryID, subscriptionID, constant.WebhookDeliveryFailed).
+		Updates(map[string]interface{}{
+			"status":            constant.WebhookDeliveryPending,
+			"attempts":          0,
+			"next_attempt_time": now,
+			"update_time":       now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -3,8 +3,13 @@ package resource
 import (
 	// golang package
 	"fmt"
-	"log"
 	"orderfc/config"
+	"orderfc/infrastructure/breaker"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/metrics"
+	"orderfc/infrastructure/tracing"
+	"strings"
+	"time"
 
 	// external package
 	"gorm.io/driver/postgres"
@@ -12,22 +17,219 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-// InitDB init db by given cfg pointer of config.Config.
+const (
+	defaultSSLMode           = "disable"
+	defaultSlowThreshold     = 200 * time.Millisecond
+	defaultConnectBackoff    = time.Second
+	defaultConnectMaxBackoff = 30 * time.Second
+)
+
+// InitDB init db by given cfg pointer of config.Config, and dbBreaker pointer of breaker.Breaker.
+//
+// The connection is retried with exponential backoff so the service survives
+// the database starting after it, and only exits once every retry failed.
 //
 // It returns pointer of gorm.DB when successful.
 // Otherwise, nil pointer of gorm.DB will be returned.
-func InitDB(cfg *config.Config) *gorm.DB {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.Name)
+func InitDB(cfg *config.Config, dbBreaker *breaker.Breaker) *gorm.DB {
+	db, err := openDB(cfg.Database)
+	if err != nil {
+		log.Logger.Fatalf("Failed to connect to DB: %v", err)
+	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	})
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Logger.Fatalf("Failed to get DB pool: %v", err)
+	}
+
+	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
 
+	err = registerPlugins(db)
 	if err != nil {
-		log.Fatalf("Failed to connect to DB: %v", err)
+		log.Logger.Fatalf("Failed to register DB plugins: %v", err)
 	}
 
-	log.Println("Connected to DB")
+	err = db.Use(breaker.GormPlugin{Breaker: dbBreaker})
+	if err != nil {
+		log.Logger.Fatalf("Failed to register DB circuit breaker: %v", err)
+	}
+
+	log.Logger.Info("Connected to DB")
 	return db
 }
+
+// InitReplicas init replicas by given cfg pointer of config.Config.
+//
+// Replicas are connected lazily so a replica that is down at startup does
+// not block the service; reads fall back to the primary until it is back.
+//
+// It returns slice of pointer of gorm.DB when successful.
+// Otherwise, nil value of gorm.DB slice will be returned.
+func InitReplicas(cfg *config.Config) []*gorm.DB {
+	replicas := make([]*gorm.DB, 0, len(cfg.Database.Replicas))
+	for _, replica := range cfg.Database.Replicas {
+		replicaConfig := cfg.Database
+		replicaConfig.Host = replica.Host
+		replicaConfig.Port = replica.Port
+
+		db, err := gorm.Open(postgres.Open(databaseDSN(replicaConfig)), &gorm.Config{
+			Logger:               newGormLogger(replicaConfig),
+			DisableAutomaticPing: true,
+		})
+		if err != nil {
+			log.Logger.Fatalf("Failed to open DB replica %s:%s: %v", replica.Host, replica.Port, err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Logger.Fatalf("Failed to get DB replica pool: %v", err)
+		}
+
+		sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+		sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+		sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+		sqlDB.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
+
+		err = registerPlugins(db)
+		if err != nil {
+			log.Logger.Fatalf("Failed to register DB replica plugins: %v", err)
+		}
+
+		replicas = append(replicas, db)
+	}
+
+	if len(replicas) > 0 {
+		log.Logger.Infof("Configured %d DB replica(s)", len(replicas))
+	}
+
+	return replicas
+}
+
+// registerPlugins register plugins by given db pointer of gorm.DB.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func registerPlugins(db *gorm.DB) error {
+	err := db.Use(metrics.GormPlugin{})
+	if err != nil {
+		return err
+	}
+
+	return db.Use(tracing.GormPlugin{})
+}
+
+// openDB open db by given cfg of config.DatabaseConfig.
+//
+// It returns pointer of gorm.DB, and nil error when successful.
+// Otherwise, nil pointer of gorm.DB, and the last connection error will be returned.
+func openDB(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	backoff := cfg.ConnectBackoff
+	if backoff <= 0 {
+		backoff = defaultConnectBackoff
+	}
+
+	maxBackoff := cfg.ConnectMaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultConnectMaxBackoff
+	}
+
+	gormConfig := &gorm.Config{
+		Logger: newGormLogger(cfg),
+	}
+
+	for attempt := 0; ; attempt++ {
+		db, err := gorm.Open(postgres.Open(databaseDSN(cfg)), gormConfig)
+		if err == nil {
+			return db, nil
+		}
+
+		if db != nil {
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+		}
+
+		if attempt >= cfg.ConnectRetries {
+			return nil, err
+		}
+
+		log.Logger.Warnf("Failed to connect to DB, retrying in %s (%d/%d): %v", backoff, attempt+1, cfg.ConnectRetries, err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// databaseDSN database dsn by given cfg of config.DatabaseConfig.
+//
+// It returns string of the key/value connection string.
+func databaseDSN(cfg config.DatabaseConfig) string {
+	sslMode := cfg.SSLMode
+	if sslMode == "" {
+		sslMode = defaultSSLMode
+	}
+
+	params := []string{
+		"host=" + dsnValue(cfg.Host),
+		"port=" + dsnValue(cfg.Port),
+		"user=" + dsnValue(cfg.User),
+		"password=" + dsnValue(cfg.Password),
+		"dbname=" + dsnValue(cfg.Name),
+		"sslmode=" + dsnValue(sslMode),
+	}
+
+	if cfg.SSLRootCert != "" {
+		params = append(params, "sslrootcert="+dsnValue(cfg.SSLRootCert))
+	}
+
+	// unknown keys are sent to the server as run-time parameters
+	if cfg.StatementTimeout > 0 {
+		params = append(params, fmt.Sprintf("statement_timeout=%d", cfg.StatementTimeout.Milliseconds()))
+	}
+
+	return strings.Join(params, " ")
+}
+
+// dsnValue dsn value by given value.
+//
+// It returns string of value quoted for a key/value connection string.
+func dsnValue(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "'" + value + "'"
+}
+
+// newGormLogger new gorm logger by given cfg of config.DatabaseConfig.
+//
+// Queries are written through the service logger so they share its format.
+//
+// It returns logger.Interface.
+func newGormLogger(cfg config.DatabaseConfig) logger.Interface {
+	slowThreshold := cfg.SlowThreshold
+	if slowThreshold <= 0 {
+		slowThreshold = defaultSlowThreshold
+	}
+
+	return logger.New(log.Logger, logger.Config{
+		SlowThreshold:             slowThreshold,
+		LogLevel:                  gormLogLevel(cfg.LogLevel),
+		IgnoreRecordNotFoundError: true,
+	})
+}
+
+// gormLogLevel gorm log level by given level.
+//
+// It returns logger.LogLevel, defaulting to warn which logs errors and slow queries.
+func gormLogLevel(level string) logger.LogLevel {
+	switch level {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "info":
+		return logger.Info
+	default:
+		return logger.Warn
+	}
+}
@@ -4,8 +4,10 @@ import (
 	// golang package
 	"context"
 	"fmt"
-	"log"
 	"orderfc/config"
+	"orderfc/infrastructure/breaker"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/tracing"
 
 	// external package
 	"github.com/redis/go-redis/v9"
@@ -13,22 +15,24 @@ import (
 
 var RedisClient *redis.Client
 
-// InitRedis init redis by given cfg pointer of config.Config.
+// InitRedis init redis by given cfg pointer of config.Config, and redisBreaker pointer of breaker.Breaker.
 //
 // It returns pointer of redis.Client when successful.
 // Otherwise, nil pointer of redis.Client will be returned.
-func InitRedis(cfg *config.Config) *redis.Client {
+func InitRedis(cfg *config.Config, redisBreaker *breaker.Breaker) *redis.Client {
 	RedisClient = redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port),
 		Password: cfg.Redis.Password,
 	})
+	RedisClient.AddHook(tracing.RedisHook{})
+	RedisClient.AddHook(breaker.RedisHook{Breaker: redisBreaker})
 
 	ctx := context.Background()
 	_, err := RedisClient.Ping(ctx).Result()
 	if err != nil {
-		log.Fatalf("failed connect to redis: %v", err)
+		log.Logger.Fatalf("failed connect to redis: %v", err)
 	}
 
-	log.Println("Connected to Redis")
+	log.Logger.Info("Connected to Redis")
 	return RedisClient
 }
@@ -0,0 +1,93 @@
+package service
+
+import (
+	// golang package
+	"context"
+	"encoding/json"
+	"orderfc/infrastructure/constant"
+	"orderfc/models"
+	"time"
+
+	// external package
+	"gorm.io/gorm"
+)
+
+// GetCheckoutSagaByOrderID get checkout saga by order id by given orderID.
+//
+// It returns models.CheckoutSaga, which is empty when the order has none, and nil error when successful.
+// Otherwise, empty models.CheckoutSaga, and error will be returned.
+func (s *OrderService) GetCheckoutSagaByOrderID(ctx context.Context, orderID int64) (models.CheckoutSaga, error) {
+	saga, err := s.OrderRepository.GetCheckoutSagaByOrderID(ctx, orderID)
+	if err != nil {
+		return models.CheckoutSaga{}, err
+	}
+
+	return saga, nil
+}
+
+// ClaimCheckoutSagas claim checkout sagas by given limit, and lease.
+//
+// It returns slice of models.CheckoutSaga, and nil error when successful.
+// Otherwise, nil value of models.CheckoutSaga slice, and error will be returned.
+func (s *OrderService) ClaimCheckoutSagas(ctx context.Context, limit int, lease time.Duration) ([]models.CheckoutSaga, error) {
+	sagas, err := s.OrderRepository.ClaimCheckoutSagas(ctx, limit, lease)
+	if err != nil {
+		return nil, err
+	}
+
+	return sagas, nil
+}
+
+// UpdateCheckoutSaga update checkout saga by given saga of models.CheckoutSaga.
+//
+// It returns true, and nil error when the saga still had its version and was updated.
+// Otherwise, false, and error if any will be returned.
+func (s *OrderService) UpdateCheckoutSaga(ctx context.Context, saga models.CheckoutSaga) (bool, error) {
+	updated, err := s.OrderRepository.UpdateCheckoutSaga(ctx, saga)
+	if err != nil {
+		return false, err
+	}
+
+	return updated, nil
+}
+
+// SearchCheckoutSagas search checkout sagas by given param of models.AdminSagaSearchParam.
+//
+// It returns slice of models.CheckoutSaga, int64, and nil error when successful.
+// Otherwise, nil value of models.CheckoutSaga slice, empty int64, and error will be returned.
+func (s *OrderService) SearchCheckoutSagas(ctx context.Context, param models.AdminSagaSearchParam) ([]models.CheckoutSaga, int64, error) {
+	sagas, total, err := s.OrderRepository.SearchCheckoutSagas(ctx, param)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return sagas, total, nil
+}
+
+// startCheckoutSagaTx start checkout saga tx by given tx pointer of gorm.DB, and orderID.
+//
+// The saga starts with the order already created and is due right away, so
+// the worker runs it even when the checkout does not get to.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (s *OrderService) startCheckoutSagaTx(ctx context.Context, tx *gorm.DB, orderID int64) error {
+	now := time.Now()
+
+	steps, err := json.Marshal([]models.SagaStep{
+		{Name: constant.SagaStepCreateOrder, Status: constant.SagaStepSucceeded, Time: now},
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.OrderRepository.InsertCheckoutSagaTx(ctx, tx, &models.CheckoutSaga{
+		OrderID:      orderID,
+		State:        constant.SagaStateRunning,
+		Step:         constant.SagaStepReserveStock,
+		Steps:        string(steps),
+		DeadlineTime: now,
+		CreateTime:   now,
+		UpdateTime:   now,
+	})
+}
@@ -3,8 +3,13 @@ package service
 import (
 	// golang package
 	"context"
+	"encoding/json"
 	"orderfc/cmd/order/repository"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/log"
 	"orderfc/models"
+	"strings"
+	"time"
 
 	// external package
 	"gorm.io/gorm"
@@ -55,7 +60,7 @@ func (s *OrderService) SaveIdempotencyToken(ctx context.Context, token string) e
 // It returns models.Order, and nil error when successful.
 // Otherwise, empty models.Order, and error will be returned.
 func (s *OrderService) GetOrderInfoByOrderID(ctx context.Context, orderID int64) (models.Order, error) {
-	orderInfo, err := s.OrderRepository.GetOrderInfoByOrderID(ctx, orderID)
+	orderInfo, err := s.OrderRepository.GetCachedOrderInfoByOrderID(ctx, orderID)
 	if err != nil {
 		return models.Order{}, err
 	}
@@ -63,12 +68,43 @@ func (s *OrderService) GetOrderInfoByOrderID(ctx context.Context, orderID int64)
 	return orderInfo, nil
 }
 
-// GetOrderDetailByOrderDetailID get order detail by order detail id by given orderDetailID.
+// GetPrimaryOrderInfoByOrderID get primary order info by order id by given orderID.
+//
+// The order is read from the primary, bypassing the cache and the replicas,
+// so it reflects every committed write.
+//
+// It returns models.Order, and nil error when successful.
+// Otherwise, empty models.Order, and error will be returned.
+func (s *OrderService) GetPrimaryOrderInfoByOrderID(ctx context.Context, orderID int64) (models.Order, error) {
+	orderInfo, err := s.OrderRepository.GetCachedOrderInfoByOrderID(repository.ContextWithPrimaryRead(ctx), orderID)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	return orderInfo, nil
+}
+
+// GetPrimaryOrderDetailByOrderDetailID get primary order detail by order detail id by given orderDetailID.
+//
+// The order detail is read from the primary, bypassing the replicas.
 //
 // It returns models.OrderDetail, and nil error when successful.
 // Otherwise, empty models.OrderDetail, and error will be returned.
-func (s *OrderService) GetOrderDetailByOrderDetailID(ctx context.Context, orderDetailID int64) (models.OrderDetail, error) {
-	orderDetail, err := s.OrderRepository.GetOrderDetailByOrderDetailID(ctx, orderDetailID)
+func (s *OrderService) GetPrimaryOrderDetailByOrderDetailID(ctx context.Context, orderDetailID int64) (models.OrderDetail, error) {
+	orderDetail, err := s.OrderRepository.GetOrderDetailByOrderDetailID(repository.ContextWithPrimaryRead(ctx), 0, orderDetailID)
+	if err != nil {
+		return models.OrderDetail{}, err
+	}
+
+	return orderDetail, nil
+}
+
+// GetOrderDetailByOrderDetailID get order detail by order detail id by given userID owning the order, and orderDetailID.
+//
+// It returns models.OrderDetail, and nil error when successful.
+// Otherwise, empty models.OrderDetail, and error will be returned.
+func (s *OrderService) GetOrderDetailByOrderDetailID(ctx context.Context, userID int64, orderDetailID int64) (models.OrderDetail, error) {
+	orderDetail, err := s.OrderRepository.GetOrderDetailByOrderDetailID(ctx, userID, orderDetailID)
 	if err != nil {
 		return models.OrderDetail{}, err
 	}
@@ -78,19 +114,38 @@ func (s *OrderService) GetOrderDetailByOrderDetailID(ctx context.Context, orderD
 
 // UpdateOrderStatus update order status by given orderID, and status.
 //
+// The webhook deliveries of the change are queued in the same transaction.
+// The order's cache entries are invalidated and its user is pinned to the
+// primary for the read-your-writes window.
+//
 // It returns nil error when successful.
 // Otherwise, error will be returned.
 func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID int64, status int) error {
-	err := s.OrderRepository.UpdateOrderStatus(ctx, orderID, status)
+	var userID int64
+
+	err := s.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		userID, err = s.OrderRepository.UpdateOrderStatusTx(ctx, tx, orderID, status)
+		// no order was updated
+		if err != nil || userID == 0 {
+			return err
+		}
+
+		return s.enqueueWebhookDeliveriesTx(ctx, tx, orderID, status, "")
+	})
 	if err != nil {
 		return err
 	}
 
+	s.OrderRepository.OnOrderWritten(ctx, orderID, userID)
+	s.publishOrderStatusEvent(ctx, orderID, status, "")
 	return nil
 }
 
 // SaveOrderWithDetail save order with detail by given order pointer of models.Order, and detail pointer of models.OrderDetail.
 //
+// The checkout saga of the order is started in the same transaction.
+//
 // It returns int64, and nil error when successful.
 // Otherwise, empty int64, and error will be returned.
 func (s *OrderService) SaveOrderAndOrderDetail(ctx context.Context, order *models.Order, orderDetail *models.OrderDetail) (int64, error) {
@@ -110,13 +165,19 @@ func (s *OrderService) SaveOrderAndOrderDetail(ctx context.Context, order *model
 		}
 
 		orderID = order.ID
-		return nil
+		err = s.startCheckoutSagaTx(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+
+		return s.enqueueWebhookDeliveriesTx(ctx, tx, orderID, order.Status, "")
 	})
 
 	if err != nil {
 		return 0, err
 	}
 
+	s.OrderRepository.OnOrderWritten(ctx, orderID, order.UserID)
 	return orderID, nil
 }
 
@@ -125,9 +186,176 @@ func (s *OrderService) SaveOrderAndOrderDetail(ctx context.Context, order *model
 // It returns slice of models.OrderHistoryResponse, and nil error when successful.
 // Otherwise, nil value of models.OrderHistoryResponse slice, and error will be returned.
 func (s *OrderService) GetOrderHistoriesByUserID(ctx context.Context, param models.OrderHistoryParam) ([]models.OrderHistoryResponse, error) {
-	orderHistories, err := s.OrderRepository.GetOrderHistoriesByUserID(ctx, param)
+	orderHistories, err := s.OrderRepository.GetCachedOrderHistoriesByUserID(ctx, param)
 	if err != nil {
 		return nil, err
 	}
 	return orderHistories, nil
 }
+
+// SearchOrders search orders by given param of models.AdminOrderSearchParam.
+//
+// It returns slice of models.Order, int64, and nil error when successful.
+// Otherwise, nil value of models.Order slice, empty int64, and error will be returned.
+func (s *OrderService) SearchOrders(ctx context.Context, param models.AdminOrderSearchParam) ([]models.Order, int64, error) {
+	orders, total, err := s.OrderRepository.SearchOrders(ctx, param)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+// ForceUpdateOrderStatus force update order status by given order, status, and history entry.
+//
+// The status change, the appended history entry and the webhook deliveries
+// are written in one transaction.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (s *OrderService) ForceUpdateOrderStatus(ctx context.Context, order models.Order, status int, entry models.StatusHistory) error {
+	err := s.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		err := s.appendOrderHistoryTx(ctx, tx, order.OrderDetailID, entry)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.OrderRepository.UpdateOrderStatusTx(ctx, tx, order.ID, status)
+		if err != nil {
+			return err
+		}
+
+		return s.enqueueWebhookDeliveriesTx(ctx, tx, order.ID, status, entry.Reason)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.OrderRepository.OnOrderWritten(ctx, order.ID, order.UserID)
+	s.publishOrderStatusEvent(ctx, order.ID, status, entry.Reason)
+	return nil
+}
+
+// TransitionOrderStatus transition order status by given order, from, and to status, and history entry.
+//
+// Unlike ForceUpdateOrderStatus the order is only changed while it still has
+// the from status, so it cannot override a status set concurrently, e.g. by
+// a payment event.
+//
+// It returns true, and nil error when the order was updated.
+// Otherwise, false, and error if any will be returned.
+func (s *OrderService) TransitionOrderStatus(ctx context.Context, order models.Order, from int, to int, entry models.StatusHistory) (bool, error) {
+	var updated bool
+
+	err := s.OrderRepository.WithTransaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		updated, err = s.OrderRepository.UpdateOrderStatusFromTx(ctx, tx, order.ID, from, to)
+		if err != nil || !updated {
+			return err
+		}
+
+		err = s.appendOrderHistoryTx(ctx, tx, order.OrderDetailID, entry)
+		if err != nil {
+			return err
+		}
+
+		return s.enqueueWebhookDeliveriesTx(ctx, tx, order.ID, to, entry.Reason)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if updated {
+		s.OrderRepository.OnOrderWritten(ctx, order.ID, order.UserID)
+		s.publishOrderStatusEvent(ctx, order.ID, to, entry.Reason)
+	}
+
+	return updated, nil
+}
+
+// GetOrderStatusEventID get order status event id by given orderID.
+//
+// It returns int64 of the id of the latest status event of the order, and nil error when successful.
+// Otherwise, 0, and error will be returned.
+func (s *OrderService) GetOrderStatusEventID(ctx context.Context, orderID int64) (int64, error) {
+	id, err := s.OrderRepository.GetOrderStatusEventID(ctx, orderID)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetOrderStatusEvents get order status events by given orderID, and afterID.
+//
+// It returns slice of models.OrderStatusEvent, and nil error when successful.
+// Otherwise, nil value of models.OrderStatusEvent slice, and error will be returned.
+func (s *OrderService) GetOrderStatusEvents(ctx context.Context, orderID int64, afterID int64) ([]models.OrderStatusEvent, error) {
+	events, err := s.OrderRepository.GetOrderStatusEvents(ctx, orderID, afterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// SubscribeOrderStatusEvents subscribe order status events by given orderID.
+//
+// It returns receive only channel of models.OrderStatusEvent, func to unsubscribe, and nil error when successful.
+// Otherwise, nil channel, nil func, and error will be returned.
+func (s *OrderService) SubscribeOrderStatusEvents(ctx context.Context, orderID int64) (<-chan models.OrderStatusEvent, func(), error) {
+	events, unsubscribe, err := s.OrderRepository.SubscribeOrderStatusEvents(ctx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return events, unsubscribe, nil
+}
+
+// publishOrderStatusEvent publish order status event by given orderID, status, and reason.
+//
+// The status change is already committed, so a failure is only logged. The
+// new status is still served by the order endpoints and to newly connected
+// streams.
+func (s *OrderService) publishOrderStatusEvent(ctx context.Context, orderID int64, status int, reason string) {
+	_, err := s.OrderRepository.PublishOrderStatusEvent(ctx, models.OrderStatusEvent{
+		OrderID:   orderID,
+		Status:    strings.ToLower(constant.OrderStatusTranslated[status]),
+		Reason:    reason,
+		EventTime: time.Now(),
+	})
+	if err != nil {
+		log.WithContext(ctx).Errorf("failed to publish status event of order %d: %v", orderID, err)
+	}
+}
+
+// appendOrderHistoryTx append order history tx by given tx pointer of gorm.DB, orderDetailID, and history entry.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (s *OrderService) appendOrderHistoryTx(ctx context.Context, tx *gorm.DB, orderDetailID int64, entry models.StatusHistory) error {
+	orderDetail, err := s.OrderRepository.GetOrderDetailForUpdateTx(ctx, tx, orderDetailID)
+	if err != nil {
+		return err
+	}
+
+	var history []json.RawMessage
+	if orderDetail.OrderHistory != "" {
+		err = json.Unmarshal([]byte(orderDetail.OrderHistory), &history)
+		if err != nil {
+			return err
+		}
+	}
+
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	historyJSON, err := json.Marshal(append(history, entryJSON))
+	if err != nil {
+		return err
+	}
+
+	return s.OrderRepository.UpdateOrderHistoryTx(ctx, tx, orderDetail.ID, string(historyJSON))
+}
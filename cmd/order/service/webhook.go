@@ -0,0 +1,177 @@
+package service
+
+import (
+	// golang package
+	"context"
+	"encoding/json"
+	"orderfc/infrastructure/constant"
+	"orderfc/models"
+	"strings"
+	"time"
+
+	// external package
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateWebhookSubscription create webhook subscription by given subscription pointer of models.WebhookSubscription.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (s *OrderService) CreateWebhookSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	err := s.OrderRepository.InsertWebhookSubscription(ctx, subscription)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetWebhookSubscriptions get webhook subscriptions.
+//
+// It returns slice of models.WebhookSubscription, and nil error when successful.
+// Otherwise, nil value of models.WebhookSubscription slice, and error will be returned.
+func (s *OrderService) GetWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	subscriptions, err := s.OrderRepository.GetWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// GetWebhookSubscriptionByID get webhook subscription by id by given subscriptionID.
+//
+// It returns models.WebhookSubscription, and nil error when successful.
+// Otherwise, empty models.WebhookSubscription, and error will be returned.
+func (s *OrderService) GetWebhookSubscriptionByID(ctx context.Context, subscriptionID int64) (models.WebhookSubscription, error) {
+	subscription, err := s.OrderRepository.GetWebhookSubscriptionByID(ctx, subscriptionID)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+// DeactivateWebhookSubscription deactivate webhook subscription by given subscriptionID.
+//
+// It returns true, and nil error when an active subscription was deactivated.
+// Otherwise, false, and error if any will be returned.
+func (s *OrderService) DeactivateWebhookSubscription(ctx context.Context, subscriptionID int64) (bool, error) {
+	deactivated, err := s.OrderRepository.DeactivateWebhookSubscription(ctx, subscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	return deactivated, nil
+}
+
+// ClaimWebhookDeliveries claim webhook deliveries by given limit, and lease.
+//
+// It returns slice of models.WebhookDelivery, and nil error when successful.
+// Otherwise, nil value of models.WebhookDelivery slice, and error will be returned.
+func (s *OrderService) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	deliveries, err := s.OrderRepository.ClaimWebhookDeliveries(ctx, limit, lease)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// UpdateWebhookDeliveryResult update webhook delivery result by given delivery of models.WebhookDelivery.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (s *OrderService) UpdateWebhookDeliveryResult(ctx context.Context, delivery models.WebhookDelivery) error {
+	err := s.OrderRepository.UpdateWebhookDeliveryResult(ctx, delivery)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SearchWebhookDeliveries search webhook deliveries by given subscriptionID, and param of models.AdminWebhookDeliveryParam.
+//
+// It returns slice of models.WebhookDelivery, int64, and nil error when successful.
+// Otherwise, nil value of models.WebhookDelivery slice, empty int64, and error will be returned.
+func (s *OrderService) SearchWebhookDeliveries(ctx context.Context, subscriptionID int64, param models.AdminWebhookDeliveryParam) ([]models.WebhookDelivery, int64, error) {
+	deliveries, total, err := s.OrderRepository.SearchWebhookDeliveries(ctx, subscriptionID, param)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// ReplayWebhookDelivery replay webhook delivery by given subscriptionID, and deliveryID.
+//
+// It returns true, and nil error when a failed delivery was replayed.
+// Otherwise, false, and error if any will be returned.
+func (s *OrderService) ReplayWebhookDelivery(ctx context.Context, subscriptionID int64, deliveryID int64) (bool, error) {
+	replayed, err := s.OrderRepository.ReplayWebhookDelivery(ctx, subscriptionID, deliveryID)
+	if err != nil {
+		return false, err
+	}
+
+	return replayed, nil
+}
+
+// enqueueWebhookDeliveriesTx enqueue webhook deliveries tx by given tx pointer of gorm.DB, orderID, status, and reason.
+//
+// One event is created for the status change and a delivery of it is queued
+// for every active subscription to its event type. Sharing the event id
+// lets receivers drop deliveries they already processed.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (s *OrderService) enqueueWebhookDeliveriesTx(ctx context.Context, tx *gorm.DB, orderID int64, status int, reason string) error {
+	if !s.OrderRepository.WebhookConfig.Enabled {
+		return nil
+	}
+
+	eventType, ok := constant.WebhookEventTypes[status]
+	if !ok {
+		return nil
+	}
+
+	subscriptions, err := s.OrderRepository.GetWebhookSubscriptionsByEventTypeTx(ctx, tx, eventType)
+	if err != nil || len(subscriptions) == 0 {
+		return err
+	}
+
+	now := time.Now()
+	event := models.WebhookEvent{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		EventTime: now,
+		Data: models.WebhookEventData{
+			OrderID: orderID,
+			Status:  strings.ToLower(constant.OrderStatusTranslated[status]),
+			Reason:  reason,
+		},
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]models.WebhookDelivery, len(subscriptions))
+	for index, subscription := range subscriptions {
+		deliveries[index] = models.WebhookDelivery{
+			SubscriptionID:  subscription.ID,
+			EventID:         event.ID,
+			EventType:       eventType,
+			OrderID:         orderID,
+			Payload:         string(payload),
+			Status:          constant.WebhookDeliveryPending,
+			NextAttemptTime: now,
+			CreateTime:      now,
+			UpdateTime:      now,
+		}
+	}
+
+	return s.OrderRepository.InsertWebhookDeliveriesTx(ctx, tx, deliveries)
+}
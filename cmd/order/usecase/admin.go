@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	// golang package
+	"context"
+	"encoding/json"
+	"orderfc/infrastructure/apperror"
+	"orderfc/infrastructure/constant"
+	"orderfc/models"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAdminPageSize = 20
+	maxAdminPageSize     = 100
+)
+
+var (
+	ErrOrderNotFound      = apperror.NotFound("order_not_found", "order not found")
+	ErrInvalidOrderStatus = apperror.Validation("invalid_order_status", "invalid order status")
+	ErrReasonRequired     = apperror.Validation("reason_required", "reason is required")
+	ErrUnsupportedEvent   = apperror.Validation("unsupported_event", "unsupported event")
+)
+
+// SearchOrders search orders by given param of models.AdminOrderSearchParam.
+//
+// It returns models.AdminOrderSearchResponse, and nil error when successful.
+// Otherwise, empty models.AdminOrderSearchResponse, and error will be returned.
+func (uc *OrderUsecase) SearchOrders(ctx context.Context, param models.AdminOrderSearchParam) (models.AdminOrderSearchResponse, error) {
+	if param.Page <= 0 {
+		param.Page = 1
+	}
+
+	if param.PageSize <= 0 {
+		param.PageSize = defaultAdminPageSize
+	}
+
+	if param.PageSize > maxAdminPageSize {
+		param.PageSize = maxAdminPageSize
+	}
+
+	orders, total, err := uc.OrderService.SearchOrders(ctx, param)
+	if err != nil {
+		return models.AdminOrderSearchResponse{}, err
+	}
+
+	result := make([]models.AdminOrderResponse, len(orders))
+	for index, order := range orders {
+		result[index] = models.AdminOrderResponse{
+			OrderID:         order.ID,
+			UserID:          order.UserID,
+			Amount:          order.Amount,
+			TotalQty:        order.TotalQty,
+			OrderDetailID:   order.OrderDetailID,
+			Status:          constant.OrderStatusTranslated[order.Status],
+			PaymentMethod:   order.PaymentMethod,
+			ShippingAddress: order.ShippingAddress,
+		}
+	}
+
+	return models.AdminOrderSearchResponse{
+		Orders:   result,
+		Total:    total,
+		Page:     param.Page,
+		PageSize: param.PageSize,
+	}, nil
+}
+
+// ForceUpdateOrderStatus force update order status by given orderID, actor of models.Principal, and param of models.AdminUpdateOrderStatusRequest.
+//
+// The history entry records the user id of a user actor, or the name of a
+// service actor.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (uc *OrderUsecase) ForceUpdateOrderStatus(ctx context.Context, orderID int64, actor models.Principal, param models.AdminUpdateOrderStatusRequest) error {
+	statusName, ok := constant.OrderStatusTranslated[param.Status]
+	if !ok {
+		return ErrInvalidOrderStatus
+	}
+
+	reason := strings.TrimSpace(param.Reason)
+	if reason == "" {
+		return ErrReasonRequired
+	}
+
+	order, err := uc.getOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	entry := models.StatusHistory{
+		Status:    strings.ToLower(statusName),
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Reason:    reason,
+	}
+
+	switch actor.Type {
+	case constant.PrincipalTypeUser:
+		entry.ActorID = actor.UserID
+	case constant.PrincipalTypeService:
+		entry.Actor = actor.Subject
+	}
+
+	return uc.OrderService.ForceUpdateOrderStatus(ctx, order, param.Status, entry)
+}
+
+// ResendOrderEvent resend order event by given orderID, and event name.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (uc *OrderUsecase) ResendOrderEvent(ctx context.Context, orderID int64, event string) error {
+	order, err := uc.getOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	switch event {
+	case constant.EventOrderCreated:
+		return uc.Producer.PublishOrderCreated(ctx, models.OrderCreatedEvent{
+			OrderID:         order.ID,
+			UserID:          order.UserID,
+			TotalAmount:     order.Amount,
+			PaymentMethod:   order.PaymentMethod,
+			ShippingAddress: order.ShippingAddress,
+		})
+	case constant.EventProductStockUpdate, constant.EventProductStockRollback:
+		orderDetail, err := uc.OrderService.GetOrderDetailByOrderDetailID(ctx, order.UserID, order.OrderDetailID)
+		if err != nil {
+			return err
+		}
+
+		var products []models.CheckoutItem
+		err = json.Unmarshal([]byte(orderDetail.Products), &products)
+		if err != nil {
+			return err
+		}
+
+		stockEvent := models.ProductStockUpdateEvent{
+			OrderID:   order.ID,
+			Products:  models.ConvertCheckoutItemToProductItems(products),
+			EventTime: time.Now(),
+		}
+
+		if event == constant.EventProductStockRollback {
+			return uc.Producer.PublishProductStockRollback(ctx, stockEvent)
+		}
+
+		return uc.Producer.PublishProductStockUpdate(ctx, stockEvent)
+	default:
+		return ErrUnsupportedEvent
+	}
+}
+
+// GetRawOrderDetail get raw order detail by given orderID.
+//
+// It returns models.AdminOrderDetailResponse, and nil error when successful.
+// Otherwise, empty models.AdminOrderDetailResponse, and error will be returned.
+func (uc *OrderUsecase) GetRawOrderDetail(ctx context.Context, orderID int64) (models.AdminOrderDetailResponse, error) {
+	order, err := uc.getOrder(ctx, orderID)
+	if err != nil {
+		return models.AdminOrderDetailResponse{}, err
+	}
+
+	orderDetail, err := uc.OrderService.GetOrderDetailByOrderDetailID(ctx, order.UserID, order.OrderDetailID)
+	if err != nil {
+		return models.AdminOrderDetailResponse{}, err
+	}
+
+	return models.AdminOrderDetailResponse{
+		OrderID:       order.ID,
+		OrderDetailID: orderDetail.ID,
+		Products:      rawJSON(orderDetail.Products),
+		OrderHistory:  rawJSON(orderDetail.OrderHistory),
+	}, nil
+}
+
+// getOrder get order by given orderID.
+//
+// It returns models.Order, and nil error when successful.
+// Otherwise, empty models.Order, and error will be returned.
+func (uc *OrderUsecase) getOrder(ctx context.Context, orderID int64) (models.Order, error) {
+	order, err := uc.OrderService.GetOrderInfoByOrderID(ctx, orderID)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	if order.ID == 0 {
+		return models.Order{}, ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+// rawJSON raw json by given value.
+//
+// It returns value as json.RawMessage when it is valid JSON.
+// Otherwise, value encoded as a JSON string will be returned.
+func rawJSON(value string) json.RawMessage {
+	if json.Valid([]byte(value)) {
+		return json.RawMessage(value)
+	}
+
+	encoded, _ := json.Marshal(value)
+	return encoded
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	// golang package
+	"context"
+	"orderfc/infrastructure/apperror"
+	"orderfc/infrastructure/constant"
+	"orderfc/models"
+	"strings"
+	"time"
+)
+
+var ErrOrderEventsUnavailable = apperror.Unavailable("order_events_unavailable", "order events are temporarily unavailable", nil)
+
+// SubscribeOrderEvents subscribe order events by given userID, orderID, and lastEventID.
+//
+// The stream is subscribed before its backlog is read, so no event is lost
+// in between; events in both have an id not above the backlog's last one.
+// The backlog holds the events after lastEventID, or when some of them are
+// gone, or lastEventID is 0, the current status of the order carrying the
+// latest event id. Orders of other users are reported as not found.
+//
+// It returns models.OrderEventStream which must be closed, and nil error when successful.
+// Otherwise, empty models.OrderEventStream, and error will be returned.
+func (uc *OrderUsecase) SubscribeOrderEvents(ctx context.Context, userID int64, orderID int64, lastEventID int64) (models.OrderEventStream, error) {
+	_, err := uc.getUserOrder(ctx, userID, orderID)
+	if err != nil {
+		return models.OrderEventStream{}, err
+	}
+
+	events, unsubscribe, err := uc.OrderService.SubscribeOrderStatusEvents(ctx, orderID)
+	if err != nil {
+		return models.OrderEventStream{}, ErrOrderEventsUnavailable.Wrap(err)
+	}
+
+	backlog, err := uc.orderEventBacklog(ctx, orderID, lastEventID)
+	if err != nil {
+		unsubscribe()
+		return models.OrderEventStream{}, err
+	}
+
+	return models.OrderEventStream{
+		Backlog: backlog,
+		Events:  events,
+		Close:   unsubscribe,
+	}, nil
+}
+
+// orderEventBacklog order event backlog by given orderID, and lastEventID.
+//
+// It returns slice of models.OrderStatusEvent, and nil error when successful.
+// Otherwise, nil value of models.OrderStatusEvent slice, and error will be returned.
+func (uc *OrderUsecase) orderEventBacklog(ctx context.Context, orderID int64, lastEventID int64) ([]models.OrderStatusEvent, error) {
+	latestID, err := uc.OrderService.GetOrderStatusEventID(ctx, orderID)
+	if err != nil {
+		return nil, ErrOrderEventsUnavailable.Wrap(err)
+	}
+
+	if lastEventID > 0 && lastEventID <= latestID {
+		events, err := uc.OrderService.GetOrderStatusEvents(ctx, orderID, lastEventID)
+		if err != nil {
+			return nil, ErrOrderEventsUnavailable.Wrap(err)
+		}
+
+		// nothing was trimmed from the backlog since lastEventID
+		if int64(len(events)) >= latestID-lastEventID {
+			return events, nil
+		}
+	}
+
+	// read from the primary after the event id, so the status is at least as recent as the id
+	order, err := uc.OrderService.GetPrimaryOrderInfoByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.ID == 0 {
+		return nil, ErrOrderNotFound
+	}
+
+	return []models.OrderStatusEvent{{
+		ID:        latestID,
+		OrderID:   order.ID,
+		Status:    strings.ToLower(constant.OrderStatusTranslated[order.Status]),
+		EventTime: time.Now(),
+	}}, nil
+}
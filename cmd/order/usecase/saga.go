@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	// golang package
+	"context"
+	"encoding/json"
+	"orderfc/infrastructure/apperror"
+	"orderfc/infrastructure/constant"
+	"orderfc/models"
+	"time"
+)
+
+var ErrSagaNotFound = apperror.NotFound("saga_not_found", "checkout saga not found")
+
+// SearchSagas search sagas by given param of models.AdminSagaSearchParam.
+//
+// It returns models.AdminSagaListResponse, and nil error when successful.
+// Otherwise, empty models.AdminSagaListResponse, and error will be returned.
+func (uc *OrderUsecase) SearchSagas(ctx context.Context, param models.AdminSagaSearchParam) (models.AdminSagaListResponse, error) {
+	if param.Page <= 0 {
+		param.Page = 1
+	}
+
+	if param.PageSize <= 0 {
+		param.PageSize = defaultAdminPageSize
+	}
+
+	if param.PageSize > maxAdminPageSize {
+		param.PageSize = maxAdminPageSize
+	}
+
+	sagas, total, err := uc.OrderService.SearchCheckoutSagas(ctx, param)
+	if err != nil {
+		return models.AdminSagaListResponse{}, err
+	}
+
+	result := make([]models.AdminSagaResponse, len(sagas))
+	for index, saga := range sagas {
+		result[index] = toAdminSagaResponse(saga)
+	}
+
+	return models.AdminSagaListResponse{
+		Sagas:    result,
+		Total:    total,
+		Page:     param.Page,
+		PageSize: param.PageSize,
+	}, nil
+}
+
+// GetOrderSaga get order saga by given orderID.
+//
+// It returns models.AdminSagaResponse, and nil error when successful.
+// Otherwise, empty models.AdminSagaResponse, and error will be returned.
+func (uc *OrderUsecase) GetOrderSaga(ctx context.Context, orderID int64) (models.AdminSagaResponse, error) {
+	order, err := uc.getOrder(ctx, orderID)
+	if err != nil {
+		return models.AdminSagaResponse{}, err
+	}
+
+	saga, err := uc.OrderService.GetCheckoutSagaByOrderID(ctx, order.ID)
+	if err != nil {
+		return models.AdminSagaResponse{}, err
+	}
+
+	if saga.ID == 0 {
+		return models.AdminSagaResponse{}, ErrSagaNotFound
+	}
+
+	return toAdminSagaResponse(saga), nil
+}
+
+// toAdminSagaResponse to admin saga response by given saga of models.CheckoutSaga.
+//
+// A saga is overdue when it has not finished although its deadline passed,
+// i.e. no worker moved it on in time.
+//
+// It returns models.AdminSagaResponse.
+func toAdminSagaResponse(saga models.CheckoutSaga) models.AdminSagaResponse {
+	steps := []models.SagaStep{}
+	_ = json.Unmarshal([]byte(saga.Steps), &steps)
+
+	active := saga.State == constant.SagaStateRunning || saga.State == constant.SagaStateCompensating
+
+	return models.AdminSagaResponse{
+		OrderID:      saga.OrderID,
+		State:        saga.State,
+		Step:         saga.Step,
+		Attempts:     saga.Attempts,
+		LastError:    saga.LastError,
+		Reason:       saga.Reason,
+		DeadlineTime: saga.DeadlineTime,
+		Overdue:      active && saga.DeadlineTime.Before(time.Now()),
+		Steps:        steps,
+		CreateTime:   saga.CreateTime,
+		UpdateTime:   saga.UpdateTime,
+	}
+}
@@ -5,53 +5,73 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"orderfc/cmd/order/service"
+	"orderfc/infrastructure/apperror"
 	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/metrics"
+	"orderfc/infrastructure/validation"
 	"orderfc/kafka"
 	"orderfc/models"
+	"orderfc/saga"
+	"strings"
 	"time"
 )
 
+var (
+	ErrDuplicateRequest    = apperror.Conflict("duplicate_request", "order already created, please check again!")
+	ErrOrderNotCancellable = apperror.Conflict("order_not_cancellable", "order can no longer be cancelled")
+)
+
 type OrderUsecase struct {
 	OrderService service.OrderService
 	Producer     kafka.KafkaProducer
+	Saga         *saga.Orchestrator
 }
 
-// NewOrderUsecase new orderusecase by given OrderService.
+// NewOrderUsecase new orderusecase by given OrderService, KafkaProducer, and orchestrator pointer of saga.Orchestrator.
 //
 // It returns pointer of OrderUsecase when successful.
 // Otherwise, nil pointer of OrderUsecase will be returned.
-func NewOrderUsecase(orderService service.OrderService, kafkaProducer kafka.KafkaProducer) *OrderUsecase {
+func NewOrderUsecase(orderService service.OrderService, kafkaProducer kafka.KafkaProducer, orchestrator *saga.Orchestrator) *OrderUsecase {
 	return &OrderUsecase{
 		OrderService: orderService,
 		Producer:     kafkaProducer,
+		Saga:         orchestrator,
 	}
 }
 
 // CheckoutOrder checkout order by given CheckoutRequest.
 //
+// The order is saved with its checkout saga, which reserves the stock and
+// requests the payment in the background.
+//
 // It returns int64, and nil error when successful.
 // Otherwise, empty int64, and error will be returned.
 func (uc *OrderUsecase) CheckoutOrder(ctx context.Context, param *models.CheckoutRequest) (int64, error) {
 	if param.IdempotencyToken != "" {
 		isExist, err := uc.OrderService.CheckIdempotency(ctx, param.IdempotencyToken)
 		if err != nil {
+			metrics.CheckoutTotal.WithLabelValues(metrics.CheckoutIdempotencyError).Inc()
 			return 0, err
 		}
 
 		if isExist {
-			return 0, errors.New("order already created, please check again!")
+			metrics.CheckoutTotal.WithLabelValues(metrics.CheckoutDuplicateRequest).Inc()
+			return 0, ErrDuplicateRequest
 		}
 	}
 
-	if err := uc.validateProducts(param.Items); err != nil {
+	// the HTTP handler already validated while binding, other callers rely on this check
+	if err := validation.Struct(param); err != nil {
+		metrics.CheckoutTotal.WithLabelValues(metrics.CheckoutInvalidProducts).Inc()
 		return 0, err
 	}
 
 	totalQty, totalAmount := uc.calculateOrderSummary(param.Items)
 	productJSON, historyJSON, err := uc.constructOrderDetail(param.Items)
 	if err != nil {
+		metrics.CheckoutTotal.WithLabelValues(metrics.CheckoutOrderDetailError).Inc()
 		return 0, err
 	}
 
@@ -71,6 +91,7 @@ func (uc *OrderUsecase) CheckoutOrder(ctx context.Context, param *models.Checkou
 
 	orderID, err := uc.OrderService.SaveOrderAndOrderDetail(ctx, order, orderDetail)
 	if err != nil {
+		metrics.CheckoutTotal.WithLabelValues(metrics.CheckoutSaveOrderError).Inc()
 		return 0, err
 	}
 
@@ -78,62 +99,20 @@ func (uc *OrderUsecase) CheckoutOrder(ctx context.Context, param *models.Checkou
 		_ = uc.OrderService.SaveIdempotencyToken(ctx, param.IdempotencyToken)
 	}
 
-	event := models.OrderCreatedEvent{
-		OrderID:         orderID,
-		UserID:          param.UserID,
-		TotalAmount:     order.Amount,
-		PaymentMethod:   param.PaymentMethod,
-		ShippingAddress: param.ShippingAddress,
-	}
+	// keep the trace and request id of the checkout, but not its cancellation
+	sagaCtx := log.ContextWithOrderID(context.WithoutCancel(ctx), orderID)
 
 	go func() {
-		if err := uc.Producer.PublishOrderCreated(context.Background(), event); err != nil {
-			fmt.Println("Failed to send Kafka event:", err)
-		} else {
-			fmt.Println("OrderCreated event sent to Kafka")
-		}
-	}()
-
-	updateStockEvent := models.ProductStockUpdateEvent{
-		OrderID:   orderID,
-		Products:  convertCheckoutItemToProductItems(param.Items),
-		EventTime: time.Now(),
-	}
-
-	go func() {
-		if err := uc.Producer.PublishProductStockUpdate(context.Background(), updateStockEvent); err != nil {
-			fmt.Println("Failed to send kafka event: ", err)
-		} else {
-			fmt.Println("StockUpdate event sent to kafka")
+		// the saga worker picks it up when it does not run here
+		if err := uc.Saga.Resume(sagaCtx, orderID); err != nil {
+			log.WithContext(sagaCtx).Errorf("Failed to run checkout saga: %v", err)
 		}
 	}()
 
+	metrics.CheckoutTotal.WithLabelValues(metrics.CheckoutSuccess).Inc()
 	return orderID, nil
 }
 
-// validateProducts validate products by given items slice of CheckoutItem.
-//
-// It returns nil error when successful.
-// Otherwise, error will be returned.
-func (uc *OrderUsecase) validateProducts(items []models.CheckoutItem) error {
-	seen := map[int64]bool{}
-	for _, item := range items {
-		if seen[item.ProductID] {
-			return fmt.Errorf("duplicate product: %d", item.ProductID)
-		}
-		seen[item.ProductID] = true
-
-		if item.Quantity <= 0 || item.Quantity > 10000 {
-			return fmt.Errorf("invalid quantity for %d", item.ProductID)
-		}
-
-		if item.Price <= 0 {
-			return fmt.Errorf("invalid price for %d", item.ProductID)
-		}
-	}
-	return nil
-}
-
 // calculateOrderSummary calculate order summary by given items slice of CheckoutItem.
 //
 // It returns int, and float64 when successful.
@@ -175,18 +154,144 @@ func (uc *OrderUsecase) GetOrderHistory(ctx context.Context, param models.OrderH
 	return orderHistory, nil
 }
 
-// convertCheckoutItemToProductItems convert checkout item to product items by given source slice of CheckoutItem.
+// GetOrder get order by given userID, and orderID.
+//
+// Orders of other users are reported as not found so their ids are not leaked.
+//
+// It returns models.OrderHistoryResponse, and nil error when successful.
+// Otherwise, empty models.OrderHistoryResponse, and error will be returned.
+func (uc *OrderUsecase) GetOrder(ctx context.Context, userID int64, orderID int64) (models.OrderHistoryResponse, error) {
+	order, err := uc.getUserOrder(ctx, userID, orderID)
+	if err != nil {
+		return models.OrderHistoryResponse{}, err
+	}
+
+	orderDetail, err := uc.OrderService.GetOrderDetailByOrderDetailID(ctx, order.UserID, order.OrderDetailID)
+	if err != nil {
+		return models.OrderHistoryResponse{}, err
+	}
+
+	var products []models.CheckoutItem
+	err = json.Unmarshal([]byte(orderDetail.Products), &products)
+	if err != nil {
+		return models.OrderHistoryResponse{}, err
+	}
+
+	var history []models.StatusHistory
+	err = json.Unmarshal([]byte(orderDetail.OrderHistory), &history)
+	if err != nil {
+		return models.OrderHistoryResponse{}, err
+	}
+
+	return models.OrderHistoryResponse{
+		OrderID:         order.ID,
+		TotalAmount:     order.Amount,
+		TotalQty:        order.TotalQty,
+		Status:          constant.OrderStatusTranslated[order.Status],
+		PaymentMethod:   order.PaymentMethod,
+		ShippingAddress: order.ShippingAddress,
+		Products:        products,
+		History:         history,
+	}, nil
+}
+
+// CancelOrder cancel order by given userID, orderID, and reason.
+//
+// Only orders that are still created, i.e. not paid yet, can be cancelled.
+// The checkout saga of the order then voids the payment and releases the
+// reserved stock.
 //
-// It returns slice of models.ProductItem when successful.
-// Otherwise, nil value of models.ProductItem slice will be returned.
-func convertCheckoutItemToProductItems(source []models.CheckoutItem) []models.ProductItem {
-	result := make([]models.ProductItem, len(source))
-	for index, item := range source {
-		result[index] = models.ProductItem{
-			ProductID: item.ProductID,
-			Qty:       item.Quantity,
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (uc *OrderUsecase) CancelOrder(ctx context.Context, userID int64, orderID int64, reason string) error {
+	order, err := uc.getUserOrder(ctx, userID, orderID)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != constant.OrderStatusCreated {
+		return ErrOrderNotCancellable
+	}
+
+	entry := models.StatusHistory{
+		Status:    strings.ToLower(constant.OrderStatusTranslated[constant.OrderStatusCancelled]),
+		Timestamp: time.Now().Format(time.RFC3339Nano),
+		Reason:    strings.TrimSpace(reason),
+		ActorID:   userID,
+	}
+
+	updated, err := uc.OrderService.TransitionOrderStatus(ctx, order, constant.OrderStatusCreated, constant.OrderStatusCancelled, entry)
+	if err != nil {
+		return err
+	}
+
+	// the order was paid or cancelled since it was read
+	if !updated {
+		return ErrOrderNotCancellable
+	}
+
+	sagaCtx := log.ContextWithOrderID(context.WithoutCancel(ctx), order.ID)
+
+	go func() {
+		err := uc.Saga.OrderCancelled(sagaCtx, order.ID, entry.Reason)
+		if errors.Is(err, saga.ErrSagaNotFound) {
+			// placed before checkouts ran as a saga
+			err = uc.rollbackOrderStock(sagaCtx, order)
 		}
+
+		// otherwise the saga compensates once it times out waiting for the payment
+		if err != nil {
+			log.WithContext(sagaCtx).Errorf("Failed to compensate cancelled order: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// rollbackOrderStock rollback order stock by given order of models.Order.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (uc *OrderUsecase) rollbackOrderStock(ctx context.Context, order models.Order) error {
+	orderDetail, err := uc.OrderService.GetOrderDetailByOrderDetailID(ctx, order.UserID, order.OrderDetailID)
+	if err != nil {
+		return err
+	}
+
+	var products []models.CheckoutItem
+	err = json.Unmarshal([]byte(orderDetail.Products), &products)
+	if err != nil {
+		return err
+	}
+
+	rollbackEvent := models.ProductStockUpdateEvent{
+		OrderID:   order.ID,
+		Products:  models.ConvertCheckoutItemToProductItems(products),
+		EventTime: time.Now(),
+	}
+
+	err = uc.Producer.PublishProductStockRollback(ctx, rollbackEvent)
+	if err != nil {
+		return err
+	}
+
+	log.WithContext(ctx).Info("StockRollback event sent to kafka")
+	return nil
+}
+
+// getUserOrder get user order by given userID, and orderID.
+//
+// It returns models.Order, and nil error when successful.
+// Otherwise, empty models.Order, and error will be returned.
+func (uc *OrderUsecase) getUserOrder(ctx context.Context, userID int64, orderID int64) (models.Order, error) {
+	order, err := uc.getOrder(ctx, orderID)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	if order.UserID != userID {
+		return models.Order{}, ErrOrderNotFound
 	}
 
-	return result
+	return order, nil
 }
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	// golang package
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"orderfc/infrastructure/apperror"
+	"orderfc/models"
+	"orderfc/webhook"
+	"slices"
+	"time"
+)
+
+const webhookSecretPrefix = "whsec_"
+
+var (
+	ErrWebhookNotFound              = apperror.NotFound("webhook_not_found", "webhook subscription not found")
+	ErrWebhookDeliveryNotReplayable = apperror.Conflict("webhook_delivery_not_replayable", "only failed deliveries of the subscription can be replayed")
+	ErrWebhookURLNotPublic          = apperror.Validation("webhook_url_not_public", "webhook url must point to a public address")
+)
+
+// CreateWebhookSubscription create webhook subscription by given param of models.AdminCreateWebhookRequest.
+//
+// A secret is generated when none is given. The secret is only part of this
+// response, later reads leave it out. URLs of internal addresses are refused,
+// deliveries check the resolved address again.
+//
+// It returns models.AdminWebhookResponse, and nil error when successful.
+// Otherwise, empty models.AdminWebhookResponse, and error will be returned.
+func (uc *OrderUsecase) CreateWebhookSubscription(ctx context.Context, param models.AdminCreateWebhookRequest) (models.AdminWebhookResponse, error) {
+	err := webhook.CheckURL(param.URL)
+	if err != nil {
+		return models.AdminWebhookResponse{}, ErrWebhookURLNotPublic.Wrap(err)
+	}
+
+	secret := param.Secret
+	if secret == "" {
+		random := make([]byte, 32)
+		_, err = rand.Read(random)
+		if err != nil {
+			return models.AdminWebhookResponse{}, err
+		}
+
+		secret = webhookSecretPrefix + hex.EncodeToString(random)
+	}
+
+	eventTypes := slices.Compact(slices.Sorted(slices.Values(param.EventTypes)))
+	eventTypesJSON, err := json.Marshal(eventTypes)
+	if err != nil {
+		return models.AdminWebhookResponse{}, err
+	}
+
+	now := time.Now()
+	subscription := models.WebhookSubscription{
+		URL:        param.URL,
+		Secret:     secret,
+		EventTypes: string(eventTypesJSON),
+		Active:     true,
+		CreateTime: now,
+		UpdateTime: now,
+	}
+
+	err = uc.OrderService.CreateWebhookSubscription(ctx, &subscription)
+	if err != nil {
+		return models.AdminWebhookResponse{}, err
+	}
+
+	response := toWebhookResponse(subscription)
+	response.Secret = secret
+	return response, nil
+}
+
+// GetWebhookSubscriptions get webhook subscriptions.
+//
+// It returns slice of models.AdminWebhookResponse, and nil error when successful.
+// Otherwise, nil value of models.AdminWebhookResponse slice, and error will be returned.
+func (uc *OrderUsecase) GetWebhookSubscriptions(ctx context.Context) ([]models.AdminWebhookResponse, error) {
+	subscriptions, err := uc.OrderService.GetWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.AdminWebhookResponse, len(subscriptions))
+	for index, subscription := range subscriptions {
+		result[index] = toWebhookResponse(subscription)
+	}
+
+	return result, nil
+}
+
+// DeleteWebhookSubscription delete webhook subscription by given subscriptionID.
+//
+// The subscription is deactivated rather than deleted so its delivery log
+// stays available.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (uc *OrderUsecase) DeleteWebhookSubscription(ctx context.Context, subscriptionID int64) error {
+	deactivated, err := uc.OrderService.DeactivateWebhookSubscription(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	if !deactivated {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveries get webhook deliveries by given subscriptionID, and param of models.AdminWebhookDeliveryParam.
+//
+// It returns models.AdminWebhookDeliveryListResponse, and nil error when successful.
+// Otherwise, empty models.AdminWebhookDeliveryListResponse, and error will be returned.
+func (uc *OrderUsecase) GetWebhookDeliveries(ctx context.Context, subscriptionID int64, param models.AdminWebhookDeliveryParam) (models.AdminWebhookDeliveryListResponse, error) {
+	_, err := uc.getWebhookSubscription(ctx, subscriptionID)
+	if err != nil {
+		return models.AdminWebhookDeliveryListResponse{}, err
+	}
+
+	if param.Page <= 0 {
+		param.Page = 1
+	}
+
+	if param.PageSize <= 0 {
+		param.PageSize = defaultAdminPageSize
+	}
+
+	if param.PageSize > maxAdminPageSize {
+		param.PageSize = maxAdminPageSize
+	}
+
+	deliveries, total, err := uc.OrderService.SearchWebhookDeliveries(ctx, subscriptionID, param)
+	if err != nil {
+		return models.AdminWebhookDeliveryListResponse{}, err
+	}
+
+	result := make([]models.AdminWebhookDeliveryResponse, len(deliveries))
+	for index, delivery := range deliveries {
+		result[index] = models.AdminWebhookDeliveryResponse{
+			ID:              delivery.ID,
+			EventID:         delivery.EventID,
+			EventType:       delivery.EventType,
+			OrderID:         delivery.OrderID,
+			Status:          delivery.Status,
+			Attempts:        delivery.Attempts,
+			NextAttemptTime: delivery.NextAttemptTime,
+			ResponseCode:    delivery.ResponseCode,
+			LastError:       delivery.LastError,
+			Payload:         rawJSON(delivery.Payload),
+			CreateTime:      delivery.CreateTime,
+			UpdateTime:      delivery.UpdateTime,
+		}
+	}
+
+	return models.AdminWebhookDeliveryListResponse{
+		Deliveries: result,
+		Total:      total,
+		Page:       param.Page,
+		PageSize:   param.PageSize,
+	}, nil
+}
+
+// ReplayWebhookDelivery replay webhook delivery by given subscriptionID, and deliveryID.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (uc *OrderUsecase) ReplayWebhookDelivery(ctx context.Context, subscriptionID int64, deliveryID int64) error {
+	_, err := uc.getWebhookSubscription(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	replayed, err := uc.OrderService.ReplayWebhookDelivery(ctx, subscriptionID, deliveryID)
+	if err != nil {
+		return err
+	}
+
+	if !replayed {
+		return ErrWebhookDeliveryNotReplayable
+	}
+
+	return nil
+}
+
+// getWebhookSubscription get webhook subscription by given subscriptionID.
+//
+// It returns models.WebhookSubscription, and nil error when successful.
+// Otherwise, empty models.WebhookSubscription, and error will be returned.
+func (uc *OrderUsecase) getWebhookSubscription(ctx context.Context, subscriptionID int64) (models.WebhookSubscription, error) {
+	subscription, err := uc.OrderService.GetWebhookSubscriptionByID(ctx, subscriptionID)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+
+	if subscription.ID == 0 {
+		return models.WebhookSubscription{}, ErrWebhookNotFound
+	}
+
+	return subscription, nil
+}
+
+// toWebhookResponse to webhook response by given subscription of models.WebhookSubscription.
+//
+// It returns models.AdminWebhookResponse without the secret.
+func toWebhookResponse(subscription models.WebhookSubscription) models.AdminWebhookResponse {
+	var eventTypes []string
+	_ = json.Unmarshal([]byte(subscription.EventTypes), &eventTypes)
+
+	return models.AdminWebhookResponse{
+		ID:         subscription.ID,
+		URL:        subscription.URL,
+		EventTypes: eventTypes,
+		Active:     subscription.Active,
+		CreateTime: subscription.CreateTime,
+	}
+}
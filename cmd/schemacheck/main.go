@@ -0,0 +1,47 @@
+package main
+
+import (
+	// golang package
+	"fmt"
+	"orderfc/kafka/schema"
+	"os"
+
+	// external package
+	"github.com/spf13/pflag"
+)
+
+// main main.
+//
+// It loads the event schemas and exits non zero when a schema version is not
+// backward compatible with the version before it. With --dir the schemas are
+// read from that directory instead of the ones built into the service.
+func main() {
+	dir := pflag.String("dir", "", "read the event schemas from this directory instead of the built in ones")
+	pflag.Parse()
+
+	var registry *schema.Registry
+	var err error
+	if *dir == "" {
+		registry, err = schema.NewRegistry()
+	} else {
+		registry, err = schema.Load(os.DirFS(*dir))
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load event schemas: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = registry.CheckCompatibility()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "incompatible event schemas:")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for _, eventType := range registry.EventTypes() {
+		fmt.Printf("%s: v%d\n", eventType, registry.Latest(eventType))
+	}
+
+	fmt.Printf("Event schemas of %d event type(s) are backward compatible\n", len(registry.EventTypes()))
+}
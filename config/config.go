@@ -2,31 +2,208 @@ package config
 
 import (
 	// golang package
+	"encoding/json"
+	"fmt"
 	"log"
+	"os"
+	"reflect"
+	"strings"
 
 	// external package
 	"github.com/spf13/viper"
 )
 
-// LoadConfig load config.
+const (
+	DefaultConfigFile = "./files/config/config.yaml"
+
+	// DefaultJWTAlgorithm is accepted when auth.algorithms is not set
+	DefaultJWTAlgorithm = "HS256"
+
+	envPrefix     = "ORDERFC"
+	envFileSuffix = "_FILE"
+)
+
+// LoadConfig load config by given path of the config file.
+//
+// Every key can be overridden by an ORDERFC_ prefixed environment variable
+// with dots replaced by underscores, e.g. ORDERFC_DATABASE_PASSWORD. Appending
+// _FILE to the variable name reads the value from that file instead, which is
+// how mounted secrets are passed in. Lists of settings, e.g. the service API
+// keys, are passed as a JSON array, e.g. ORDERFC_SERVICE_AUTH_KEYS_FILE.
 //
 // It returns Config when successful.
-// Otherwise, empty Config will be returned.
-func LoadConfig() Config {
+// Otherwise, the process exits with the load or validation errors.
+func LoadConfig(path string) Config {
 	var cfg Config
 
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath("./files/config")
-	err := viper.ReadInConfig()
+	if path == "" {
+		path = DefaultConfigFile
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	err := v.ReadInConfig()
 	if err != nil {
 		log.Fatalf("error read config file: %v", err)
 	}
 
-	err = viper.Unmarshal(&cfg)
+	err = bindEnvs(v, reflect.TypeOf(cfg), "")
+	if err != nil {
+		log.Fatalf("error read config from environment: %v", err)
+	}
+
+	err = v.Unmarshal(&cfg)
 	if err != nil {
 		log.Fatalf("error unmarshal config: %v", err)
 	}
 
+	err = Validate(cfg)
+	if err != nil {
+		log.Fatalf("error validate config %s: %v", path, err)
+	}
+
 	return cfg
 }
+
+// bindEnvs bind envs by given v pointer of viper.Viper, t of the config struct type, and prefix of the parent key.
+//
+// AutomaticEnv only resolves keys viper already knows about, so every leaf
+// key of the struct is bound explicitly to let the environment set keys
+// missing from the config file. Slices of structs are read as a JSON array,
+// maps are left to the config file since they have no flat key.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func bindEnvs(v *viper.Viper, t reflect.Type, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := keyName(field)
+		if name == "" {
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		env := envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			err := bindEnvs(v, field.Type, key)
+			if err != nil {
+				return err
+			}
+
+			continue
+		case reflect.Map:
+			continue
+		case reflect.Slice:
+			if field.Type.Elem().Kind() == reflect.Struct {
+				err := bindEnvJSON(v, key, env)
+				if err != nil {
+					return err
+				}
+
+				continue
+			}
+		}
+
+		err := v.BindEnv(key, env)
+		if err != nil {
+			return err
+		}
+
+		err = bindEnvFile(v, key, env)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bindEnvFile bind env file by given v pointer of viper.Viper, key, and env.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func bindEnvFile(v *viper.Viper, key string, env string) error {
+	file, ok := os.LookupEnv(env + envFileSuffix)
+	if !ok {
+		return nil
+	}
+
+	if _, ok := os.LookupEnv(env); ok {
+		return fmt.Errorf("both %s and %s are set", env, env+envFileSuffix)
+	}
+
+	value, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", env+envFileSuffix, err)
+	}
+
+	v.Set(key, strings.TrimRight(string(value), "\r\n"))
+	return nil
+}
+
+// bindEnvJSON bind env json by given v pointer of viper.Viper, key, and env.
+//
+// The JSON array is read from env, or from the file named by env with the
+// _FILE suffix, and replaces the list of the config file.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func bindEnvJSON(v *viper.Viper, key string, env string) error {
+	value, ok := os.LookupEnv(env)
+	name := env
+
+	if file, fileOK := os.LookupEnv(env + envFileSuffix); fileOK {
+		if ok {
+			return fmt.Errorf("both %s and %s are set", env, env+envFileSuffix)
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", env+envFileSuffix, err)
+		}
+
+		value, ok, name = string(content), true, env+envFileSuffix
+	}
+
+	if !ok {
+		return nil
+	}
+
+	var items []map[string]interface{}
+	err := json.Unmarshal([]byte(value), &items)
+	if err != nil {
+		return fmt.Errorf("%s must be a JSON array: %w", name, err)
+	}
+
+	v.Set(key, items)
+	return nil
+}
+
+// keyName key name by given field of reflect.StructField.
+//
+// It returns string of the config key, preferring the mapstructure tag
+// viper decodes with over the yaml tag.
+func keyName(field reflect.StructField) string {
+	for _, tag := range []string{"mapstructure", "yaml"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+
+		if name != "" {
+			return name
+		}
+	}
+
+	return strings.ToLower(field.Name)
+}
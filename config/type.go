@@ -1,30 +1,219 @@
 package config
 
+import "time"
+
 type Config struct {
-	App      AppConfig      `yaml:"app" validate:"required"`
-	Database DatabaseConfig `yaml:"database" validate:"required"`
-	Redis    RedisConfig    `yaml:"redis" validate:"required"`
-	Secret   SecretConfig   `yaml:"secret" validate:"required"`
+	App         AppConfig         `yaml:"app" validate:"required"`
+	GRPC        GRPCConfig        `yaml:"grpc"`
+	Database    DatabaseConfig    `yaml:"database" validate:"required"`
+	Redis       RedisConfig       `yaml:"redis" validate:"required"`
+	Secret      SecretConfig      `yaml:"secret" validate:"required"`
+	Kafka       KafkaConfig       `yaml:"kafka" validate:"required"`
+	Cache       CacheConfig       `yaml:"cache"`
+	OrderEvents OrderEventsConfig `yaml:"order_events" mapstructure:"order_events"`
+	Webhook     WebhookConfig     `yaml:"webhook"`
+	Saga        SagaConfig        `yaml:"saga"`
+	Breaker     BreakersConfig    `yaml:"circuit_breaker" mapstructure:"circuit_breaker"`
+	Auth        AuthConfig        `yaml:"auth"`
+	ServiceAuth ServiceAuthConfig `yaml:"service_auth" mapstructure:"service_auth"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit" mapstructure:"rate_limit"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Log         LogConfig         `yaml:"log"`
 }
 
 type AppConfig struct {
-	Port string `yaml:"port" validate:"required"`
+	Port               string        `yaml:"port" validate:"required"`
+	HealthCheckTimeout time.Duration `yaml:"health_check_timeout" mapstructure:"health_check_timeout"`
+	ShutdownTimeout    time.Duration `yaml:"shutdown_timeout" mapstructure:"shutdown_timeout" validate:"gte=0"`
+	TrustedProxies     []string      `yaml:"trusted_proxies" mapstructure:"trusted_proxies" validate:"dive,cidr|ip"`
+}
+
+type GRPCConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Port       string `yaml:"port" validate:"required_if=Enabled true"`
+	Reflection bool   `yaml:"reflection"`
 }
 
 type DatabaseConfig struct {
-	Host     string `yaml:"host" validate:"required"`
-	User     string `yaml:"user" validate:"required"`
-	Password string `yaml:"password" validate:"required"`
-	Name     string `yaml:"name" validate:"required"`
-	Port     string `yaml:"port" validate:"required"`
+	Host              string        `yaml:"host" validate:"required"`
+	User              string        `yaml:"user" validate:"required"`
+	Password          string        `yaml:"password" validate:"required"`
+	Name              string        `yaml:"name" validate:"required"`
+	Port              string        `yaml:"port" validate:"required"`
+	SSLMode           string        `yaml:"ssl_mode" mapstructure:"ssl_mode" validate:"omitempty,oneof=disable allow prefer require verify-ca verify-full"`
+	SSLRootCert       string        `yaml:"ssl_root_cert" mapstructure:"ssl_root_cert" validate:"required_if=SSLMode verify-ca,required_if=SSLMode verify-full"`
+	MaxOpenConns      int           `yaml:"max_open_conns" mapstructure:"max_open_conns" validate:"gte=0"`
+	MaxIdleConns      int           `yaml:"max_idle_conns" mapstructure:"max_idle_conns" validate:"gte=0"`
+	ConnMaxLifetime   time.Duration `yaml:"conn_max_lifetime" mapstructure:"conn_max_lifetime" validate:"gte=0"`
+	ConnMaxIdleTime   time.Duration `yaml:"conn_max_idle_time" mapstructure:"conn_max_idle_time" validate:"gte=0"`
+	StatementTimeout  time.Duration `yaml:"statement_timeout" mapstructure:"statement_timeout" validate:"gte=0"`
+	LogLevel          string        `yaml:"log_level" mapstructure:"log_level" validate:"omitempty,oneof=silent error warn info"`
+	SlowThreshold     time.Duration `yaml:"slow_threshold" mapstructure:"slow_threshold" validate:"gte=0"`
+	ConnectRetries    int           `yaml:"connect_retries" mapstructure:"connect_retries" validate:"gte=0"`
+	ConnectBackoff    time.Duration `yaml:"connect_backoff" mapstructure:"connect_backoff" validate:"gte=0"`
+	ConnectMaxBackoff time.Duration `yaml:"connect_max_backoff" mapstructure:"connect_max_backoff" validate:"gte=0"`
+
+	Replicas             []DatabaseReplicaConfig `yaml:"replicas" mapstructure:"replicas" validate:"dive"`
+	ReadYourWritesWindow time.Duration           `yaml:"read_your_writes_window" mapstructure:"read_your_writes_window" validate:"gte=0"`
+}
+
+type DatabaseReplicaConfig struct {
+	Host string `yaml:"host" mapstructure:"host" validate:"required"`
+	Port string `yaml:"port" mapstructure:"port" validate:"required"`
 }
 
 type RedisConfig struct {
 	Host     string `yaml:"host" validate:"required"`
 	Port     string `yaml:"port" validate:"required"`
-	Password string `yaml:"password" validate:"required"`
+	Password string `yaml:"password"`
 }
 
 type SecretConfig struct {
-	JWTSecret string `yaml:"jwtsecret" validate:"required"`
+	JWTSecret string `yaml:"jwtsecret" validate:"jwtsecret"`
+}
+
+type BreakersConfig struct {
+	Database BreakerConfig `yaml:"database" mapstructure:"database"`
+	Redis    BreakerConfig `yaml:"redis" mapstructure:"redis"`
+	Kafka    BreakerConfig `yaml:"kafka" mapstructure:"kafka"`
+}
+
+type BreakerConfig struct {
+	Enabled          bool          `yaml:"enabled" mapstructure:"enabled"`
+	FailureThreshold int           `yaml:"failure_threshold" mapstructure:"failure_threshold" validate:"gte=0"`
+	OpenTimeout      time.Duration `yaml:"open_timeout" mapstructure:"open_timeout" validate:"gte=0"`
+	HalfOpenRequests int           `yaml:"half_open_requests" mapstructure:"half_open_requests" validate:"gte=0"`
+}
+
+type CacheConfig struct {
+	Enabled         bool          `yaml:"enabled" mapstructure:"enabled"`
+	OrderHistoryTTL time.Duration `yaml:"order_history_ttl" mapstructure:"order_history_ttl" validate:"required_if=Enabled true,gte=0"`
+	OrderTTL        time.Duration `yaml:"order_ttl" mapstructure:"order_ttl" validate:"required_if=Enabled true,gte=0"`
+}
+
+type OrderEventsConfig struct {
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval" mapstructure:"heartbeat_interval" validate:"gte=0"`
+	BacklogSize       int           `yaml:"backlog_size" mapstructure:"backlog_size" validate:"gte=0"`
+	BacklogTTL        time.Duration `yaml:"backlog_ttl" mapstructure:"backlog_ttl" validate:"gte=0"`
+}
+
+type WebhookConfig struct {
+	Enabled        bool          `yaml:"enabled" mapstructure:"enabled"`
+	PollInterval   time.Duration `yaml:"poll_interval" mapstructure:"poll_interval" validate:"gte=0"`
+	BatchSize      int           `yaml:"batch_size" mapstructure:"batch_size" validate:"gte=0"`
+	Timeout        time.Duration `yaml:"timeout" mapstructure:"timeout" validate:"gte=0"`
+	MaxAttempts    int           `yaml:"max_attempts" mapstructure:"max_attempts" validate:"gte=0"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" mapstructure:"initial_backoff" validate:"gte=0"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" mapstructure:"max_backoff" validate:"gte=0"`
+}
+
+type SagaConfig struct {
+	PollInterval   time.Duration `yaml:"poll_interval" mapstructure:"poll_interval" validate:"gte=0"`
+	BatchSize      int           `yaml:"batch_size" mapstructure:"batch_size" validate:"gte=0"`
+	Lease          time.Duration `yaml:"lease" mapstructure:"lease" validate:"gte=0"`
+	PaymentTimeout time.Duration `yaml:"payment_timeout" mapstructure:"payment_timeout" validate:"gte=0"`
+	MaxAttempts    int           `yaml:"max_attempts" mapstructure:"max_attempts" validate:"gte=0"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" mapstructure:"initial_backoff" validate:"gte=0"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" mapstructure:"max_backoff" validate:"gte=0"`
+}
+
+type KafkaConfig struct {
+	Brokers        []string            `yaml:"brokers" mapstructure:"brokers" validate:"required,min=1"`
+	TopicPrefix    string              `yaml:"topic_prefix" mapstructure:"topic_prefix"`
+	Topics         KafkaTopicsConfig   `yaml:"topics" mapstructure:"topics"`
+	GroupIDs       KafkaGroupIDConfig  `yaml:"group_ids" mapstructure:"group_ids"`
+	Consumer       KafkaConsumerConfig `yaml:"consumer" mapstructure:"consumer"`
+	SASL           KafkaSASLConfig     `yaml:"sasl" mapstructure:"sasl"`
+	TLS            KafkaTLSConfig      `yaml:"tls" mapstructure:"tls"`
+	BatchSize      int                 `yaml:"batch_size" mapstructure:"batch_size" validate:"gte=0"`
+	BatchTimeout   time.Duration       `yaml:"batch_timeout" mapstructure:"batch_timeout" validate:"gte=0"`
+	RequiredAcks   string              `yaml:"required_acks" mapstructure:"required_acks" validate:"omitempty,oneof=none one all"`
+	PublishTimeout time.Duration       `yaml:"publish_timeout" mapstructure:"publish_timeout" validate:"gte=0"`
+}
+
+type KafkaTopicsConfig struct {
+	OrderCreated   string `yaml:"order_created" mapstructure:"order_created" validate:"required"`
+	StockUpdate    string `yaml:"stock_update" mapstructure:"stock_update" validate:"required"`
+	StockRollback  string `yaml:"stock_rollback" mapstructure:"stock_rollback" validate:"required"`
+	PaymentSuccess string `yaml:"payment_success" mapstructure:"payment_success" validate:"required"`
+	PaymentFailed  string `yaml:"payment_failed" mapstructure:"payment_failed" validate:"required"`
+	PaymentVoid    string `yaml:"payment_void" mapstructure:"payment_void" validate:"required"`
+	DeadLetter     string `yaml:"dead_letter" mapstructure:"dead_letter" validate:"required"`
+}
+
+type KafkaGroupIDConfig struct {
+	PaymentSuccess string `yaml:"payment_success" mapstructure:"payment_success" validate:"required"`
+	PaymentFailed  string `yaml:"payment_failed" mapstructure:"payment_failed" validate:"required"`
+}
+
+type KafkaConsumerConfig struct {
+	Concurrency     int           `yaml:"concurrency" mapstructure:"concurrency" validate:"gte=0"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" mapstructure:"shutdown_timeout" validate:"gte=0"`
+	MaxAttempts     int           `yaml:"max_attempts" mapstructure:"max_attempts" validate:"gte=0"`
+	InitialBackoff  time.Duration `yaml:"initial_backoff" mapstructure:"initial_backoff" validate:"gte=0"`
+	MaxBackoff      time.Duration `yaml:"max_backoff" mapstructure:"max_backoff" validate:"gte=0"`
+}
+
+type KafkaSASLConfig struct {
+	Mechanism string `yaml:"mechanism" mapstructure:"mechanism" validate:"omitempty,oneof=plain scram-sha-256 scram-sha-512"`
+	Username  string `yaml:"username" mapstructure:"username" validate:"required_with=Mechanism"`
+	Password  string `yaml:"password" mapstructure:"password" validate:"required_with=Mechanism"`
+}
+
+type KafkaTLSConfig struct {
+	Enabled            bool   `yaml:"enabled" mapstructure:"enabled"`
+	CAFile             string `yaml:"ca_file" mapstructure:"ca_file"`
+	CertFile           string `yaml:"cert_file" mapstructure:"cert_file" validate:"required_with=KeyFile"`
+	KeyFile            string `yaml:"key_file" mapstructure:"key_file" validate:"required_with=CertFile"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" mapstructure:"insecure_skip_verify"`
+}
+
+type AuthConfig struct {
+	Algorithms          []string      `yaml:"algorithms" mapstructure:"algorithms"`
+	Issuer              string        `yaml:"issuer" mapstructure:"issuer" validate:"required"`
+	Audience            string        `yaml:"audience" mapstructure:"audience" validate:"required"`
+	Leeway              time.Duration `yaml:"leeway" mapstructure:"leeway"`
+	JWKSFile            string        `yaml:"jwks_file" mapstructure:"jwks_file"`
+	JWKSURL             string        `yaml:"jwks_url" mapstructure:"jwks_url"`
+	JWKSRefreshInterval time.Duration `yaml:"jwks_refresh_interval" mapstructure:"jwks_refresh_interval"`
+}
+
+type ServiceAuthConfig struct {
+	MaxClockSkew time.Duration  `yaml:"max_clock_skew" mapstructure:"max_clock_skew"`
+	Keys         []APIKeyConfig `yaml:"keys" mapstructure:"keys" validate:"dive"`
+}
+
+type APIKeyConfig struct {
+	ID      string   `yaml:"id" mapstructure:"id" validate:"required"`
+	Service string   `yaml:"service" mapstructure:"service" validate:"required"`
+	Secret  string   `yaml:"secret" mapstructure:"secret" validate:"required,secret"`
+	Roles   []string `yaml:"roles" mapstructure:"roles"`
+	Scopes  []string `yaml:"scopes" mapstructure:"scopes"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool                       `yaml:"enabled" mapstructure:"enabled"`
+	Routes  map[string][]RateLimitRule `yaml:"routes" mapstructure:"routes" validate:"dive,dive"`
+}
+
+type RateLimitRule struct {
+	By     string        `yaml:"by" mapstructure:"by" validate:"oneof=user ip"`
+	Limit  int           `yaml:"limit" mapstructure:"limit" validate:"gt=0"`
+	Window time.Duration `yaml:"window" mapstructure:"window" validate:"gt=0"`
+}
+
+type TracingConfig struct {
+	Enabled     bool    `yaml:"enabled" mapstructure:"enabled"`
+	ServiceName string  `yaml:"service_name" mapstructure:"service_name"`
+	Exporter    string  `yaml:"exporter" mapstructure:"exporter" validate:"omitempty,oneof=otlp stdout"`
+	Endpoint    string  `yaml:"endpoint" mapstructure:"endpoint"`
+	Insecure    bool    `yaml:"insecure" mapstructure:"insecure"`
+	File        string  `yaml:"file" mapstructure:"file"`
+	SampleRatio float64 `yaml:"sample_ratio" mapstructure:"sample_ratio" validate:"gte=0,lte=1"`
+}
+
+type LogConfig struct {
+	Format       string   `yaml:"format" mapstructure:"format" validate:"omitempty,oneof=json text"`
+	Level        string   `yaml:"level" mapstructure:"level" validate:"omitempty,oneof=trace debug info warn warning error fatal panic"`
+	RedactFields []string `yaml:"redact_fields" mapstructure:"redact_fields"`
 }
@@ -0,0 +1,155 @@
+package config
+
+import (
+	// golang package
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	// external package
+	"github.com/go-playground/validator/v10"
+)
+
+const minSecretLength = 32
+
+// placeholderSecrets are words found in example secrets, which are never
+// deployed on purpose.
+var placeholderSecrets = []string{"secret", "changeme", "change-me", "example", "placeholder", "password"}
+
+// Validate validate by given cfg of Config.
+//
+// Every failing field is reported at once using its config key, e.g.
+// "database.password is required", so a broken deployment can be fixed in
+// one pass.
+//
+// It returns nil error when successful.
+// Otherwise, error listing every invalid field will be returned.
+func Validate(cfg Config) error {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(keyName)
+
+	err := validate.RegisterValidation("secret", validateSecret)
+	if err != nil {
+		return err
+	}
+
+	err = validate.RegisterValidation("jwtsecret", validateJWTSecret)
+	if err != nil {
+		return err
+	}
+
+	err = validate.Struct(cfg)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	messages := make([]string, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		messages = append(messages, fieldErrorMessage(fieldError))
+	}
+
+	return fmt.Errorf("%d invalid field(s):\n  - %s", len(messages), strings.Join(messages, "\n  - "))
+}
+
+// fieldErrorMessage field error message by given fieldError of validator.FieldError.
+//
+// It returns string of the readable message prefixed by the config key.
+func fieldErrorMessage(fieldError validator.FieldError) string {
+	// drop the root struct name from the namespace
+	_, key, _ := strings.Cut(fieldError.Namespace(), ".")
+
+	switch fieldError.Tag() {
+	case "required":
+		return key + " is required"
+	case "required_if":
+		return fmt.Sprintf("%s is required when %s", key, strings.ToLower(strings.Replace(fieldError.Param(), " ", " is ", 1)))
+	case "required_with":
+		return fmt.Sprintf("%s is required when %s is set", key, strings.ToLower(fieldError.Param()))
+	case "min":
+		return fmt.Sprintf("%s must have at least %s item(s)", key, fieldError.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s], got %q", key, fieldError.Param(), fmt.Sprint(fieldError.Value()))
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", key, formatParam(fieldError))
+	case "gte":
+		return fmt.Sprintf("%s must be at least %s", key, formatParam(fieldError))
+	case "lte":
+		return fmt.Sprintf("%s must be at most %s", key, formatParam(fieldError))
+	case "cidr|ip":
+		return fmt.Sprintf("%s must be an IP address or CIDR, got %q", key, fmt.Sprint(fieldError.Value()))
+	case "secret":
+		return fmt.Sprintf("%s must be a generated secret of at least %d characters, not a placeholder", key, minSecretLength)
+	case "jwtsecret":
+		return fmt.Sprintf("%s must be a generated secret of at least %d characters, not a placeholder, when an HS* algorithm is enabled", key, minSecretLength)
+	default:
+		return fmt.Sprintf("%s failed on the %s validation", key, fieldError.Tag())
+	}
+}
+
+// validateSecret validate secret by given fieldLevel of validator.FieldLevel.
+//
+// It returns true when the secret is long enough and has no placeholder word.
+// Otherwise, false will be returned.
+func validateSecret(fieldLevel validator.FieldLevel) bool {
+	secret := fieldLevel.Field().String()
+	if len(secret) < minSecretLength {
+		return false
+	}
+
+	lower := strings.ToLower(secret)
+	for _, placeholder := range placeholderSecrets {
+		if strings.Contains(lower, placeholder) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateJWTSecret validate jwt secret by given fieldLevel of validator.FieldLevel.
+//
+// Anyone holding the secret can sign tokens for any user or role, so it is
+// held to the API key rules whenever HS* tokens are accepted.
+//
+// It returns true when no HS* algorithm is enabled or the secret is valid.
+// Otherwise, false will be returned.
+func validateJWTSecret(fieldLevel validator.FieldLevel) bool {
+	cfg, ok := fieldLevel.Top().Interface().(Config)
+	if !ok {
+		return validateSecret(fieldLevel)
+	}
+
+	algorithms := cfg.Auth.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []string{DefaultJWTAlgorithm}
+	}
+
+	for _, alg := range algorithms {
+		if strings.HasPrefix(alg, "HS") {
+			return validateSecret(fieldLevel)
+		}
+	}
+
+	return true
+}
+
+// formatParam format param by given fieldError of validator.FieldError.
+//
+// It returns string of the tag parameter, rendered as a duration for duration fields.
+func formatParam(fieldError validator.FieldError) string {
+	if fieldError.Type() == reflect.TypeOf(time.Duration(0)) {
+		if value, err := strconv.ParseInt(fieldError.Param(), 10, 64); err == nil {
+			return time.Duration(value).String()
+		}
+	}
+
+	return fieldError.Param()
+}
@@ -0,0 +1,28 @@
+-- the checkout saga of every order, see saga/orchestrator.go
+
+CREATE TABLE IF NOT EXISTS checkout_sagas (
+    id            BIGSERIAL PRIMARY KEY,
+    order_id      BIGINT      NOT NULL UNIQUE,
+    state         TEXT        NOT NULL DEFAULT 'running',
+    step          TEXT        NOT NULL,
+    -- JSON array of the finished steps and compensations, oldest first
+    steps         TEXT        NOT NULL DEFAULT '[]',
+    attempts      INT         NOT NULL DEFAULT 0,
+    last_error    TEXT        NOT NULL DEFAULT '',
+    -- why the saga is compensating
+    reason        TEXT        NOT NULL DEFAULT '',
+    -- when the worker picks the saga up: the next attempt, the end of a claim or the payment timeout
+    deadline_time TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- every write is guarded by the version it read
+    version       INT         NOT NULL DEFAULT 0,
+    create_time   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    update_time   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- the worker polls the due sagas that have not finished
+CREATE INDEX IF NOT EXISTS checkout_sagas_due_idx
+    ON checkout_sagas (deadline_time)
+    WHERE state IN ('running', 'compensating');
+
+CREATE INDEX IF NOT EXISTS checkout_sagas_state_idx
+    ON checkout_sagas (state, id DESC);
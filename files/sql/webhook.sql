@@ -0,0 +1,35 @@
+-- webhook subscriptions and their delivery log
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id          BIGSERIAL PRIMARY KEY,
+    url         TEXT        NOT NULL,
+    secret      TEXT        NOT NULL,
+    event_types JSONB       NOT NULL,
+    active      BOOLEAN     NOT NULL DEFAULT TRUE,
+    create_time TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    update_time TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id                BIGSERIAL PRIMARY KEY,
+    subscription_id   BIGINT      NOT NULL REFERENCES webhook_subscriptions (id),
+    event_id          UUID        NOT NULL,
+    event_type        TEXT        NOT NULL,
+    order_id          BIGINT      NOT NULL,
+    payload           TEXT        NOT NULL,
+    status            TEXT        NOT NULL DEFAULT 'pending',
+    attempts          INT         NOT NULL DEFAULT 0,
+    next_attempt_time TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    response_code     INT         NOT NULL DEFAULT 0,
+    last_error        TEXT        NOT NULL DEFAULT '',
+    create_time       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    update_time       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- the worker polls the due pending deliveries
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx
+    ON webhook_deliveries (next_attempt_time)
+    WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_subscription_idx
+    ON webhook_deliveries (subscription_id, id DESC);
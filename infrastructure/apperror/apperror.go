@@ -0,0 +1,236 @@
+package apperror
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"net/http"
+	"orderfc/infrastructure/breaker"
+)
+
+type Kind string
+
+const (
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindTooLarge     Kind = "too_large"
+	KindRateLimited  Kind = "rate_limited"
+	KindUnavailable  Kind = "unavailable"
+	KindInternal     Kind = "internal"
+)
+
+const (
+	CodeInternal    = "internal_error"
+	CodeUnavailable = "service_unavailable"
+	CodeTimeout     = "timeout"
+)
+
+// Error is a domain error carrying a stable machine readable code. Message
+// and Details are returned to clients, the wrapped cause is only logged.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details interface{}
+	Err     error
+}
+
+type Response struct {
+	Error     Body   `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+type Body struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Validation validation by given code, and message.
+//
+// It returns pointer of Error answered with 400.
+func Validation(code string, message string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message}
+}
+
+// Unauthorized unauthorized by given code, and message.
+//
+// It returns pointer of Error answered with 401.
+func Unauthorized(code string, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+// Forbidden forbidden by given code, and message.
+//
+// It returns pointer of Error answered with 403.
+func Forbidden(code string, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+// NotFound not found by given code, and message.
+//
+// It returns pointer of Error answered with 404.
+func NotFound(code string, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+// Conflict conflict by given code, and message.
+//
+// It returns pointer of Error answered with 409.
+func Conflict(code string, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+// TooLarge too large by given code, and message.
+//
+// It returns pointer of Error answered with 413.
+func TooLarge(code string, message string) *Error {
+	return &Error{Kind: KindTooLarge, Code: code, Message: message}
+}
+
+// RateLimited rate limited by given code, and message.
+//
+// It returns pointer of Error answered with 429.
+func RateLimited(code string, message string) *Error {
+	return &Error{Kind: KindRateLimited, Code: code, Message: message}
+}
+
+// Unavailable unavailable by given code, message, and err of the cause.
+//
+// It returns pointer of Error answered with 503.
+func Unavailable(code string, message string, err error) *Error {
+	return &Error{Kind: KindUnavailable, Code: code, Message: message, Err: err}
+}
+
+// Internal internal by given err of the cause.
+//
+// It returns pointer of Error answered with 500 and a generic message.
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: CodeInternal, Message: "internal server error", Err: err}
+}
+
+// Error error.
+//
+// It returns string of the message, followed by the cause when there is one.
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+
+	return e.Message
+}
+
+// Unwrap unwrap.
+//
+// It returns the cause error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is is by given target.
+//
+// Errors with the same kind and code match, so a sentinel still matches after
+// WithMessage or WithDetails.
+//
+// It returns true when target is an Error with the same kind and code.
+func (e *Error) Is(target error) bool {
+	var other *Error
+	if !errors.As(target, &other) {
+		return false
+	}
+
+	return e.Kind == other.Kind && e.Code == other.Code
+}
+
+// WithMessage with message by given message.
+//
+// It returns pointer of a copy of Error with message.
+func (e *Error) WithMessage(message string) *Error {
+	copied := *e
+	copied.Message = message
+	return &copied
+}
+
+// WithDetails with details by given details.
+//
+// It returns pointer of a copy of Error with details.
+func (e *Error) WithDetails(details interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// Wrap wrap by given err of the cause.
+//
+// It returns pointer of a copy of Error wrapping err.
+func (e *Error) Wrap(err error) *Error {
+	copied := *e
+	copied.Err = err
+	return &copied
+}
+
+// HTTPStatus http status.
+//
+// It returns int of the http status code for the error kind.
+func (e *Error) HTTPStatus() int {
+	switch e.Kind {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Response response by given requestID.
+//
+// It returns Response of the error envelope sent to clients.
+func (e *Error) Response(requestID string) Response {
+	return Response{
+		Error: Body{
+			Code:    e.Code,
+			Message: e.Message,
+			Details: e.Details,
+		},
+		RequestID: requestID,
+	}
+}
+
+// From from by given err.
+//
+// Errors that are not domain errors are classified by cause: open circuit
+// breakers and deadlines become unavailable, everything else internal, so raw
+// database or driver messages never reach clients.
+//
+// It returns pointer of Error.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	switch {
+	case errors.Is(err, breaker.ErrOpen):
+		return Unavailable(CodeUnavailable, "service temporarily unavailable, please retry later", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return Unavailable(CodeTimeout, "request timed out, please retry later", err)
+	default:
+		return Internal(err)
+	}
+}
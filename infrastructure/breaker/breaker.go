@@ -0,0 +1,275 @@
+package breaker
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"fmt"
+	"orderfc/config"
+	"orderfc/infrastructure/metrics"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+	defaultHalfOpenRequests = 1
+)
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+// ErrOpen is matched by every error returned for a rejected call.
+var ErrOpen = errors.New("circuit breaker is open")
+
+type OpenError struct {
+	Name string
+}
+
+// Error error.
+//
+// It returns string of the error message.
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s unavailable: %v", e.Name, ErrOpen)
+}
+
+// Unwrap unwrap.
+//
+// It returns ErrOpen so callers can match with errors.Is.
+func (e *OpenError) Unwrap() error {
+	return ErrOpen
+}
+
+type Breaker struct {
+	name      string
+	enabled   bool
+	threshold int
+	timeout   time.Duration
+	probes    int
+	isFailure func(error) bool
+	now       func() time.Time
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openedAt  time.Time
+	inFlight  int
+	successes int
+
+	// generation changes on every transition so late results of calls admitted in an earlier state are ignored
+	generation uint64
+}
+
+// New new by given name, and cfg of config.BreakerConfig.
+//
+// The breaker opens after the configured number of consecutive failures and
+// rejects calls until the open timeout passed. It then lets a few probe calls
+// through and closes once all of them succeeded, or opens again on the first
+// failed probe.
+//
+// It returns pointer of Breaker when successful.
+// Otherwise, nil pointer of Breaker will be returned.
+func New(name string, cfg config.BreakerConfig) *Breaker {
+	b := &Breaker{
+		name:      name,
+		enabled:   cfg.Enabled,
+		threshold: cfg.FailureThreshold,
+		timeout:   cfg.OpenTimeout,
+		probes:    cfg.HalfOpenRequests,
+		isFailure: defaultIsFailure,
+		now:       time.Now,
+	}
+
+	if b.threshold <= 0 {
+		b.threshold = defaultFailureThreshold
+	}
+
+	if b.timeout <= 0 {
+		b.timeout = defaultOpenTimeout
+	}
+
+	if b.probes <= 0 {
+		b.probes = defaultHalfOpenRequests
+	}
+
+	metrics.CircuitBreakerState.WithLabelValues(name).Set(float64(StateClosed))
+	return b
+}
+
+// WithFailureFunc with failure func by given isFailure.
+//
+// isFailure decides which errors count against the dependency, e.g. a
+// missing record is a valid answer and must not open the breaker.
+//
+// It returns pointer of Breaker.
+func (b *Breaker) WithFailureFunc(isFailure func(error) bool) *Breaker {
+	b.isFailure = isFailure
+	return b
+}
+
+// Name name.
+//
+// It returns string of the dependency name.
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Execute execute by given fn.
+//
+// It returns the error of fn.
+// Otherwise, pointer of OpenError will be returned without calling fn.
+func (b *Breaker) Execute(fn func() error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+	done(err)
+	return err
+}
+
+// Allow allow.
+//
+// It is the two step form of Execute for callers that cannot wrap the call in
+// a func, such as GORM and Redis hooks.
+//
+// It returns the done func which must be called with the call's result, and nil error when the call may proceed.
+// Otherwise, nil func, and pointer of OpenError will be returned.
+func (b *Breaker) Allow() (func(err error), error) {
+	if !b.enabled {
+		return func(error) {}, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.timeout {
+		b.setState(StateHalfOpen)
+	}
+
+	switch b.state {
+	case StateOpen:
+		metrics.CircuitBreakerRejectedTotal.WithLabelValues(b.name).Inc()
+		return nil, &OpenError{Name: b.name}
+	case StateHalfOpen:
+		if b.inFlight >= b.probes {
+			metrics.CircuitBreakerRejectedTotal.WithLabelValues(b.name).Inc()
+			return nil, &OpenError{Name: b.name}
+		}
+
+		b.inFlight++
+		return b.doneFunc(b.generation), nil
+	default:
+		return b.doneFunc(b.generation), nil
+	}
+}
+
+// State state.
+//
+// It returns State of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.timeout {
+		return StateHalfOpen
+	}
+
+	return b.state
+}
+
+// doneFunc done func by given generation the call was admitted in.
+//
+// It returns func recording the call's result exactly once.
+func (b *Breaker) doneFunc(generation uint64) func(err error) {
+	var once sync.Once
+
+	return func(err error) {
+		once.Do(func() {
+			b.record(generation, err != nil && b.isFailure(err))
+		})
+	}
+}
+
+// record record by given generation, and failed.
+func (b *Breaker) record(generation uint64, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	if b.state == StateHalfOpen {
+		b.inFlight--
+	}
+
+	switch b.state {
+	case StateClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+
+		b.failures++
+		if b.failures >= b.threshold {
+			b.open()
+		}
+	case StateHalfOpen:
+		if failed {
+			b.open()
+			return
+		}
+
+		b.successes++
+		if b.successes >= b.probes {
+			b.setState(StateClosed)
+		}
+	}
+}
+
+// open open.
+func (b *Breaker) open() {
+	b.openedAt = b.now()
+	b.setState(StateOpen)
+}
+
+// setState set state by given state, resetting the counters of the previous one.
+func (b *Breaker) setState(state State) {
+	b.state = state
+	b.failures = 0
+	b.successes = 0
+	b.inFlight = 0
+	b.generation++
+	metrics.CircuitBreakerState.WithLabelValues(b.name).Set(float64(state))
+}
+
+// String string.
+//
+// It returns string of the state name.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "open"
+	}
+}
+
+// defaultIsFailure default is failure by given err.
+//
+// Cancellations come from the caller giving up, not from the dependency.
+//
+// It returns true when err counts as a dependency failure.
+func defaultIsFailure(err error) bool {
+	return !errors.Is(err, context.Canceled)
+}
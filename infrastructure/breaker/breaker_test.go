@@ -0,0 +1,305 @@
+package breaker
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"orderfc/config"
+	"testing"
+	"time"
+
+	// external package
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+var errDependency = errors.New("connection refused")
+
+func TestBreaker(t *testing.T) {
+	type op struct {
+		kind  string
+		call  string
+		err   error
+		wait  time.Duration
+		state State
+	}
+
+	// allow admits call, reject expects the breaker to refuse the next one
+	allow := func(call string) op { return op{kind: "allow", call: call} }
+	reject := func() op { return op{kind: "reject"} }
+	done := func(call string, err error) op { return op{kind: "done", call: call, err: err} }
+	wait := func(d time.Duration) op { return op{kind: "wait", wait: d} }
+	state := func(s State) op { return op{kind: "state", state: s} }
+
+	// trip fails as many calls as the threshold of the test config
+	trip := []op{
+		allow("f1"), done("f1", errDependency),
+		allow("f2"), done("f2", errDependency),
+		allow("f3"), done("f3", errDependency),
+	}
+
+	with := func(groups ...[]op) []op {
+		var ops []op
+		for _, group := range groups {
+			ops = append(ops, group...)
+		}
+
+		return ops
+	}
+
+	tests := []struct {
+		name      string
+		disabled  bool
+		isFailure func(error) bool
+		ops       []op
+	}{
+		{
+			name: "opens after consecutive failures",
+			ops: with(
+				[]op{allow("a"), done("a", errDependency), state(StateClosed)},
+				[]op{allow("b"), done("b", errDependency), state(StateClosed)},
+				[]op{allow("c"), done("c", errDependency), state(StateOpen)},
+				[]op{reject()},
+			),
+		},
+		{
+			name: "success resets the failure count",
+			ops: []op{
+				allow("a"), done("a", errDependency),
+				allow("b"), done("b", errDependency),
+				allow("c"), done("c", nil),
+				allow("d"), done("d", errDependency),
+				allow("e"), done("e", errDependency),
+				state(StateClosed),
+			},
+		},
+		{
+			name: "half open once the open timeout passed",
+			ops: with(trip, []op{
+				wait(9 * time.Second), state(StateOpen), reject(),
+				wait(time.Second), state(StateHalfOpen),
+			}),
+		},
+		{
+			name: "closes after every probe succeeded",
+			ops: with(trip, []op{
+				wait(10 * time.Second),
+				allow("p1"), allow("p2"),
+				done("p1", nil), state(StateHalfOpen),
+				done("p2", nil), state(StateClosed),
+				allow("a"),
+			}),
+		},
+		{
+			name: "half open admits half_open_requests probes at a time",
+			ops: with(trip, []op{
+				wait(10 * time.Second),
+				allow("p1"), allow("p2"), reject(),
+				done("p1", nil), allow("p3"), reject(),
+			}),
+		},
+		{
+			name: "failed probe opens again",
+			ops: with(trip, []op{
+				wait(10 * time.Second),
+				allow("p1"), done("p1", errDependency),
+				state(StateOpen), reject(),
+				wait(10 * time.Second), state(StateHalfOpen),
+			}),
+		},
+		{
+			name: "late result from the closed state is not a probe",
+			ops: with([]op{allow("old")}, trip, []op{
+				wait(10 * time.Second),
+				allow("p1"),
+				done("old", nil),
+				allow("p2"), reject(),
+				done("p1", nil), state(StateHalfOpen),
+			}),
+		},
+		{
+			name: "late failure of a probe does not reopen again",
+			ops: with(trip, []op{
+				wait(10 * time.Second),
+				allow("p1"), allow("p2"),
+				done("p1", errDependency),
+				wait(5 * time.Second),
+				done("p2", errDependency),
+				wait(5 * time.Second), state(StateHalfOpen),
+			}),
+		},
+		{
+			name: "late failure from the closed state does not count after closing",
+			ops: with([]op{allow("old1"), allow("old2")}, trip, []op{
+				wait(10 * time.Second),
+				allow("p1"), done("p1", nil),
+				allow("p2"), done("p2", nil),
+				state(StateClosed),
+				done("old1", errDependency),
+				done("old2", errDependency),
+				allow("a"), done("a", errDependency),
+				state(StateClosed),
+			}),
+		},
+		{
+			name: "done records once",
+			ops: []op{
+				allow("a"), done("a", errDependency), done("a", errDependency), done("a", errDependency),
+				allow("b"), done("b", errDependency),
+				state(StateClosed),
+			},
+		},
+		{
+			name: "cancelled calls are not failures",
+			ops: []op{
+				allow("a"), done("a", context.Canceled),
+				allow("b"), done("b", context.Canceled),
+				allow("c"), done("c", context.Canceled),
+				state(StateClosed),
+			},
+		},
+		{
+			name:      "failure func decides what counts",
+			isFailure: IsDatabaseFailure,
+			ops: []op{
+				allow("a"), done("a", gorm.ErrRecordNotFound),
+				allow("b"), done("b", gorm.ErrRecordNotFound),
+				allow("c"), done("c", gorm.ErrRecordNotFound),
+				state(StateClosed),
+			},
+		},
+		{
+			name:     "disabled never opens",
+			disabled: true,
+			ops: with(trip, []op{
+				allow("a"), state(StateClosed),
+			}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			b := New("test", config.BreakerConfig{
+				Enabled:          !test.disabled,
+				FailureThreshold: 3,
+				OpenTimeout:      10 * time.Second,
+				HalfOpenRequests: 2,
+			})
+			b.now = func() time.Time { return now }
+
+			if test.isFailure != nil {
+				b.WithFailureFunc(test.isFailure)
+			}
+
+			calls := map[string]func(error){}
+			for index, op := range test.ops {
+				switch op.kind {
+				case "allow":
+					finish, err := b.Allow()
+					if err != nil {
+						t.Fatalf("op %d: Allow() for %s got error %v", index, op.call, err)
+					}
+
+					calls[op.call] = finish
+				case "reject":
+					_, err := b.Allow()
+					var openErr *OpenError
+					if !errors.As(err, &openErr) || !errors.Is(err, ErrOpen) || openErr.Name != "test" {
+						t.Fatalf("op %d: Allow() got error %v, want OpenError of test", index, err)
+					}
+				case "done":
+					calls[op.call](op.err)
+				case "wait":
+					now = now.Add(op.wait)
+				case "state":
+					if got := b.State(); got != op.state {
+						t.Fatalf("op %d: State() = %s, want %s", index, got, op.state)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBreakerExecute(t *testing.T) {
+	b := New("test", config.BreakerConfig{Enabled: true, FailureThreshold: 1})
+
+	err := b.Execute(func() error { return errDependency })
+	if !errors.Is(err, errDependency) {
+		t.Fatalf("Execute() got error %v, want %v", err, errDependency)
+	}
+
+	called := false
+	err = b.Execute(func() error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, ErrOpen) || called {
+		t.Fatalf("Execute() got error %v and called %t, want ErrOpen without calling", err, called)
+	}
+}
+
+// redisReply is an error reply from a healthy server, like redis.Nil.
+type redisReply string
+
+func (e redisReply) Error() string { return string(e) }
+
+func (redisReply) RedisError() {}
+
+func TestIsDatabaseFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "record not found", err: gorm.ErrRecordNotFound, want: false},
+		{name: "wrapped record not found", err: fmt.Errorf("get order: %w", gorm.ErrRecordNotFound), want: false},
+		{name: "cancelled", err: context.Canceled, want: false},
+		{name: "breaker open", err: &OpenError{Name: "database"}, want: false},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, want: false},
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, want: false},
+		{name: "connection failure", err: &pgconn.PgError{Code: "08006"}, want: true},
+		{name: "too many connections", err: &pgconn.PgError{Code: "53300"}, want: true},
+		{name: "admin shutdown", err: &pgconn.PgError{Code: "57P01"}, want: true},
+		{name: "io error", err: &pgconn.PgError{Code: "58030"}, want: true},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, want: true},
+		{name: "network error", err: io.ErrUnexpectedEOF, want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsDatabaseFailure(test.err); got != test.want {
+				t.Errorf("IsDatabaseFailure(%v) = %t, want %t", test.err, got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsRedisFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "missing key", err: redis.Nil, want: false},
+		{name: "wrong type", err: redisReply("WRONGTYPE Operation against a key holding the wrong kind of value"), want: false},
+		{name: "wrapped reply", err: fmt.Errorf("get cache: %w", redis.Nil), want: false},
+		{name: "cancelled", err: context.Canceled, want: false},
+		{name: "breaker open", err: &OpenError{Name: "redis"}, want: false},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, want: true},
+		{name: "connection refused", err: errDependency, want: true},
+		{name: "pool closed", err: redis.ErrClosed, want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsRedisFailure(test.err); got != test.want {
+				t.Errorf("IsRedisFailure(%v) = %t, want %t", test.err, got, test.want)
+			}
+		})
+	}
+}
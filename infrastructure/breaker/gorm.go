@@ -0,0 +1,116 @@
+package breaker
+
+import (
+	// golang package
+	"context"
+	"errors"
+
+	// external package
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const gormDoneKey = "breaker:done"
+
+type GormPlugin struct {
+	Breaker *Breaker
+}
+
+// Name name.
+//
+// It returns string of the plugin name.
+func (GormPlugin) Name() string {
+	return "orderfc:breaker"
+}
+
+// Initialize initialize by given db pointer of gorm.DB.
+//
+// It registers callbacks that reject every create, query, update, delete, row
+// and raw statement while the breaker is open and record the result of the
+// ones let through.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	registrations := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callback.Create().Before("gorm:create").Register, callback.Create().After("gorm:create").Register},
+		{"query", callback.Query().Before("gorm:query").Register, callback.Query().After("gorm:query").Register},
+		{"update", callback.Update().Before("gorm:update").Register, callback.Update().After("gorm:update").Register},
+		{"delete", callback.Delete().Before("gorm:delete").Register, callback.Delete().After("gorm:delete").Register},
+		{"row", callback.Row().Before("gorm:row").Register, callback.Row().After("gorm:row").Register},
+		{"raw", callback.Raw().Before("gorm:raw").Register, callback.Raw().After("gorm:raw").Register},
+	}
+
+	for _, registration := range registrations {
+		err := registration.before("breaker:before_"+registration.operation, p.allow)
+		if err != nil {
+			return err
+		}
+
+		err = registration.after("breaker:after_"+registration.operation, p.record)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// allow allow by given db pointer of gorm.DB.
+//
+// A rejected statement gets the open error added, which makes GORM skip it.
+func (p GormPlugin) allow(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+
+	done, err := p.Breaker.Allow()
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+
+	db.InstanceSet(gormDoneKey, done)
+}
+
+// record record by given db pointer of gorm.DB.
+func (p GormPlugin) record(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormDoneKey)
+	if !ok {
+		return
+	}
+
+	if done, ok := value.(func(error)); ok {
+		done(db.Error)
+	}
+}
+
+// IsDatabaseFailure is database failure by given err.
+//
+// Missing records, constraint violations and other query errors are answers
+// from a healthy database. Only connection, resource and server errors count.
+//
+// It returns true when err counts as a database failure.
+func IsDatabaseFailure(err error) bool {
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, context.Canceled) || errors.Is(err, ErrOpen) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code[:2] {
+		// connection exception, insufficient resources, operator intervention, system error
+		case "08", "53", "57", "58":
+			return true
+		default:
+			return false
+		}
+	}
+
+	return true
+}
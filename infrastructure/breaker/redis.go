@@ -0,0 +1,75 @@
+package breaker
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"net"
+
+	// external package
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisHook struct {
+	Breaker *Breaker
+}
+
+// DialHook dial hook by given next redis.DialHook.
+//
+// It returns redis.DialHook unchanged.
+func (h RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook process hook by given next redis.ProcessHook.
+//
+// It returns redis.ProcessHook failing fast while the breaker is open.
+func (h RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		done, err := h.Breaker.Allow()
+		if err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+
+		err = next(ctx, cmd)
+		done(err)
+		return err
+	}
+}
+
+// ProcessPipelineHook process pipeline hook by given next redis.ProcessPipelineHook.
+//
+// It returns redis.ProcessPipelineHook failing fast while the breaker is open.
+func (h RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		done, err := h.Breaker.Allow()
+		if err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+
+			return err
+		}
+
+		err = next(ctx, cmds)
+		done(err)
+		return err
+	}
+}
+
+// IsRedisFailure is redis failure by given err.
+//
+// Replies such as a missing key or a wrong type come from a healthy server.
+//
+// It returns true when err counts as a redis failure.
+func IsRedisFailure(err error) bool {
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		return false
+	}
+
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, ErrOpen)
+}
@@ -0,0 +1,44 @@
+package constant
+
+const (
+	PrincipalTypeUser    = "user"
+	PrincipalTypeService = "service"
+)
+
+const (
+	RoleAdmin = "admin"
+)
+
+const (
+	ScopeOrdersRead       = "orders:read"
+	ScopeOrdersWrite      = "orders:write"
+	ScopeAdminOrdersRead  = "admin:orders:read"
+	ScopeAdminOrdersWrite = "admin:orders:write"
+
+	ScopeAdminWebhooksRead  = "admin:webhooks:read"
+	ScopeAdminWebhooksWrite = "admin:webhooks:write"
+
+	ScopeAdminSagasRead = "admin:sagas:read"
+
+	// ScopeOrdersActAsUser lets a service call the gRPC order API for the user it names
+	ScopeOrdersActAsUser = "orders:act_as_user"
+)
+
+// DefaultUserScopes are granted to user tokens that carry no scope claim,
+// so tokens issued before scopes existed keep working.
+var DefaultUserScopes = []string{
+	ScopeOrdersRead,
+	ScopeOrdersWrite,
+}
+
+// DefaultRoleScopes are added on top of DefaultUserScopes for each role
+// held by a user token that carries no scope claim.
+var DefaultRoleScopes = map[string][]string{
+	RoleAdmin: {
+		ScopeAdminOrdersRead,
+		ScopeAdminOrdersWrite,
+		ScopeAdminWebhooksRead,
+		ScopeAdminWebhooksWrite,
+		ScopeAdminSagasRead,
+	},
+}
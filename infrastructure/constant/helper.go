@@ -13,3 +13,79 @@ var OrderStatusTranslated = map[int]string{
 	OrderStatusCompleted:  "Completed",
 	OrderStatusCancelled:  "Cancelled",
 }
+
+const (
+	WebhookEventOrderCreated    = "order.created"
+	WebhookEventOrderProcessing = "order.processing"
+	WebhookEventOrderCompleted  = "order.completed"
+	WebhookEventOrderCancelled  = "order.cancelled"
+)
+
+// WebhookEventTypes are the webhook event types sent when an order gets the status.
+var WebhookEventTypes = map[int]string{
+	OrderStatusCreated:    WebhookEventOrderCreated,
+	OrderStatusProcessing: WebhookEventOrderProcessing,
+	OrderStatusCompleted:  WebhookEventOrderCompleted,
+	OrderStatusCancelled:  WebhookEventOrderCancelled,
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+const (
+	EventOrderCreated         = "order.created"
+	EventProductStockUpdate   = "stock.update"
+	EventProductStockRollback = "stock.rollback"
+	EventPaymentSuccess       = "payment.success"
+	EventPaymentFailed        = "payment.failed"
+	EventPaymentVoid          = "payment.void"
+)
+
+const (
+	SagaStateRunning      = "running"
+	SagaStateCompleted    = "completed"
+	SagaStateCompensating = "compensating"
+	SagaStateCompensated  = "compensated"
+	SagaStateFailed       = "failed"
+)
+
+// the forward steps of the checkout saga, in order
+const (
+	SagaStepCreateOrder    = "create_order"
+	SagaStepReserveStock   = "reserve_stock"
+	SagaStepRequestPayment = "request_payment"
+	SagaStepAwaitPayment   = "await_payment"
+	SagaStepCompleteOrder  = "complete_order"
+)
+
+// the compensations of the checkout saga, in the order they run
+const (
+	SagaStepVoidPayment  = "void_payment"
+	SagaStepReleaseStock = "release_stock"
+	SagaStepCancelOrder  = "cancel_order"
+)
+
+const (
+	SagaStepSucceeded = "succeeded"
+	SagaStepFailed    = "failed"
+	SagaStepSkipped   = "skipped"
+	SagaStepTimedOut  = "timed_out"
+	SagaStepCancelled = "cancelled"
+)
+
+const (
+	PaymentMethodBankTransfer   = "bank_transfer"
+	PaymentMethodVirtualAccount = "virtual_account"
+	PaymentMethodCreditCard     = "credit_card"
+	PaymentMethodEWallet        = "e_wallet"
+)
+
+var PaymentMethods = map[string]bool{
+	PaymentMethodBankTransfer:   true,
+	PaymentMethodVirtualAccount: true,
+	PaymentMethodCreditCard:     true,
+	PaymentMethodEWallet:        true,
+}
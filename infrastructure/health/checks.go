@@ -0,0 +1,88 @@
+package health
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"fmt"
+	"orderfc/infrastructure/breaker"
+
+	// external package
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
+	"gorm.io/gorm"
+)
+
+// DatabaseCheck database check by given db pointer of gorm.DB.
+//
+// It returns Check pinging the database.
+func DatabaseCheck(db *gorm.DB) Check {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// RedisCheck redis check by given client pointer of redis.Client.
+//
+// It returns Check pinging redis.
+func RedisCheck(client *redis.Client) Check {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
+
+// KafkaCheck kafka check by given dialer pointer of kafka.Dialer, and slice of brokers.
+//
+// It returns Check passing when at least one broker accepts a connection.
+func KafkaCheck(dialer *kafka.Dialer, brokers []string) Check {
+	return func(ctx context.Context) error {
+		if len(brokers) == 0 {
+			return errors.New("no brokers configured")
+		}
+
+		var errs []error
+		for _, broker := range brokers {
+			conn, err := dialer.DialContext(ctx, "tcp", broker)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", broker, err))
+				continue
+			}
+
+			conn.Close()
+			return nil
+		}
+
+		return errors.Join(errs...)
+	}
+}
+
+// BreakerCheck breaker check by given b pointer of breaker.Breaker.
+//
+// It returns Check failing while the breaker is open.
+func BreakerCheck(b *breaker.Breaker) Check {
+	return func(ctx context.Context) error {
+		if state := b.State(); state == breaker.StateOpen {
+			return fmt.Errorf("circuit breaker %s", state)
+		}
+
+		return nil
+	}
+}
+
+// RunningCheck running check by given running func.
+//
+// It returns Check passing while running reports true.
+func RunningCheck(running func() bool) Check {
+	return func(ctx context.Context) error {
+		if !running() {
+			return errors.New("not running")
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,53 @@
+package health
+
+import (
+	// golang package
+	"context"
+
+	// external package
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type grpcServer struct {
+	healthpb.UnimplementedHealthServer
+	checker  *Checker
+	services map[string]bool
+}
+
+// GRPCServer grpc server by given services names.
+//
+// It answers grpc.health.v1.Health/Check with the readiness report, for the
+// whole server ("") and for each of services. Watch is not implemented, the
+// probes poll Check.
+//
+// It returns healthpb.HealthServer.
+func (h *Checker) GRPCServer(services ...string) healthpb.HealthServer {
+	known := map[string]bool{"": true}
+	for _, service := range services {
+		known[service] = true
+	}
+
+	return &grpcServer{
+		checker:  h,
+		services: known,
+	}
+}
+
+// Check check by given req pointer of healthpb.HealthCheckRequest.
+//
+// It returns pointer of healthpb.HealthCheckResponse, and nil error when the service is known.
+// Otherwise, nil pointer of healthpb.HealthCheckResponse, and a NotFound error will be returned.
+func (s *grpcServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if !s.services[req.GetService()] {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+
+	servingStatus := healthpb.HealthCheckResponse_SERVING
+	if s.checker.Run(ctx).Status != StatusOK {
+		servingStatus = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	return &healthpb.HealthCheckResponse{Status: servingStatus}, nil
+}
@@ -0,0 +1,155 @@
+package health
+
+import (
+	// golang package
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	// external package
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusError       = "error"
+	StatusUnavailable = "unavailable"
+
+	defaultTimeout = 2 * time.Second
+)
+
+type Check func(ctx context.Context) error
+
+type CheckResult struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type Checker struct {
+	timeout time.Duration
+	names   []string
+	checks  map[string]Check
+}
+
+// NewChecker new checker by given timeout.
+//
+// It returns pointer of Checker when successful.
+// Otherwise, nil pointer of Checker will be returned.
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Checker{
+		timeout: timeout,
+		checks:  map[string]Check{},
+	}
+}
+
+// Register register by given name, and check.
+func (h *Checker) Register(name string, check Check) {
+	if _, isExist := h.checks[name]; !isExist {
+		h.names = append(h.names, name)
+	}
+
+	h.checks[name] = check
+}
+
+// Run run.
+//
+// Every check runs concurrently with its own timeout, so one hanging
+// dependency cannot hold the whole report back.
+//
+// It returns Report with the overall status and one result per check.
+func (h *Checker) Run(ctx context.Context) Report {
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]CheckResult, len(h.names)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range h.names {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+
+			startTime := time.Now()
+			err := runCheck(checkCtx, check)
+			result := CheckResult{
+				Status:    StatusOK,
+				LatencyMS: time.Since(startTime).Milliseconds(),
+			}
+
+			if err != nil {
+				result.Status = StatusError
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			report.Checks[name] = result
+			if err != nil {
+				report.Status = StatusUnavailable
+			}
+			mu.Unlock()
+		}(name, h.checks[name])
+	}
+
+	wg.Wait()
+	return report
+}
+
+// runCheck run check by given check.
+//
+// It returns the check error, or the context error when the check does not return in time.
+func runCheck(ctx context.Context, check Check) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Liveness liveness.
+//
+// The process is alive as long as it can answer, dependencies are not checked.
+//
+// It returns gin.HandlerFunc when successful.
+// Otherwise, empty gin.HandlerFunc will be returned.
+func (h *Checker) Liveness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, Report{Status: StatusOK})
+	}
+}
+
+// Readiness readiness.
+//
+// It returns gin.HandlerFunc answering 200 when every check passes, and 503 otherwise.
+func (h *Checker) Readiness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := h.Run(c.Request.Context())
+
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+
+		c.JSON(status, report)
+	}
+}
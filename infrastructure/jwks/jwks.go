@@ -0,0 +1,286 @@
+package jwks
+
+import (
+	// golang package
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRefreshInterval = 15 * time.Minute
+	minForcedRefresh       = 30 * time.Second
+	fetchTimeout           = 5 * time.Second
+)
+
+var ErrKeyNotFound = errors.New("jwks: key not found")
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type KeySet struct {
+	file            string
+	url             string
+	refreshInterval time.Duration
+	client          *http.Client
+
+	mu              sync.RWMutex
+	keys            map[string]crypto.PublicKey
+	nextRefresh     time.Time
+	lastForcedFetch time.Time
+}
+
+// NewKeySet new key set by given file, url, and refreshInterval.
+//
+// Keys are loaded from file when it is set, otherwise from url. The set is
+// reloaded once refreshInterval has passed, and also when a token refers to
+// an unknown kid so rotated keys are picked up without a restart.
+//
+// It returns pointer of KeySet, and nil error when successful.
+// Otherwise, nil pointer of KeySet, and error will be returned.
+func NewKeySet(ctx context.Context, file string, url string, refreshInterval time.Duration) (*KeySet, error) {
+	if file == "" && url == "" {
+		return nil, errors.New("jwks: either file or url is required")
+	}
+
+	if refreshInterval <= 0 {
+		refreshInterval = defaultRefreshInterval
+	}
+
+	keySet := &KeySet{
+		file:            file,
+		url:             url,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: fetchTimeout},
+	}
+
+	err := keySet.refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return keySet, nil
+}
+
+// Key key by given kid.
+//
+// It returns crypto.PublicKey, and nil error when successful.
+// Otherwise, nil crypto.PublicKey, and error will be returned.
+func (k *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	k.mu.Lock()
+	_, ok := k.keys[kid]
+	now := time.Now()
+	shouldRefresh := now.After(k.nextRefresh) || (!ok && now.Sub(k.lastForcedFetch) > minForcedRefresh)
+	if shouldRefresh {
+		k.lastForcedFetch = now
+		// back off before the next attempt in case the refresh fails
+		k.nextRefresh = now.Add(minForcedRefresh)
+	}
+	k.mu.Unlock()
+
+	if shouldRefresh {
+		// keep serving the cached keys when the source is unavailable
+		_ = k.refresh(ctx)
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return key, nil
+}
+
+// refresh refresh.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (k *KeySet) refresh(ctx context.Context) error {
+	raw, err := k.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.nextRefresh = time.Now().Add(k.refreshInterval)
+	k.mu.Unlock()
+
+	return nil
+}
+
+// load load.
+//
+// It returns slice of byte, and nil error when successful.
+// Otherwise, nil value of byte slice, and error will be returned.
+func (k *KeySet) load(ctx context.Context) ([]byte, error) {
+	if k.file != "" {
+		return os.ReadFile(k.file)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks: unexpected status %d from %s", resp.StatusCode, k.url)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// Parse parse by given raw JSON Web Key Set.
+//
+// Keys that are not meant for signatures or use an unsupported key type are skipped.
+//
+// It returns map of kid to crypto.PublicKey, and nil error when successful.
+// Otherwise, nil map, and error will be returned.
+func Parse(raw []byte) (map[string]crypto.PublicKey, error) {
+	var set jsonWebKeySet
+	err := json.Unmarshal(raw, &set)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		var key crypto.PublicKey
+		switch jwk.Kty {
+		case "RSA":
+			key, err = parseRSA(jwk)
+		case "EC":
+			key, err = parseEC(jwk)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("jwks: key %q: %w", jwk.Kid, err)
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	return keys, nil
+}
+
+// parseRSA parse rsa by given jwk.
+//
+// It returns pointer of rsa.PublicKey, and nil error when successful.
+// Otherwise, nil pointer of rsa.PublicKey, and error will be returned.
+func parseRSA(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 {
+		return nil, errors.New("invalid RSA key")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+// parseEC parse ec by given jwk.
+//
+// It returns pointer of ecdsa.PublicKey, and nil error when successful.
+// Otherwise, nil pointer of ecdsa.PublicKey, and error will be returned.
+func parseEC(jwk jsonWebKey) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	var point ecdh.Curve
+	switch jwk.Crv {
+	case "P-256":
+		curve, point = elliptic.P256(), ecdh.P256()
+	case "P-384":
+		curve, point = elliptic.P384(), ecdh.P384()
+	case "P-521":
+		curve, point = elliptic.P521(), ecdh.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	size := (curve.Params().BitSize + 7) / 8
+	if len(x) != size || len(y) != size {
+		return nil, errors.New("invalid EC coordinates")
+	}
+
+	// ecdh rejects points that are not on the curve
+	uncompressed := append(append([]byte{4}, x...), y...)
+	_, err = point.NewPublicKey(uncompressed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
@@ -0,0 +1,75 @@
+package log
+
+import (
+	// golang package
+	"context"
+
+	// external package
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey string
+
+const (
+	requestIDKey contextKey = "request_id"
+	userIDKey    contextKey = "user_id"
+	orderIDKey   contextKey = "order_id"
+)
+
+// ContextWithRequestID context with request id by given ctx, and requestID.
+//
+// It returns context.Context carrying requestID.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext request id from context by given ctx.
+//
+// It returns string of the request id when present.
+// Otherwise, empty string will be returned.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// ContextWithUserID context with user id by given ctx, and userID.
+//
+// It returns context.Context carrying userID.
+func ContextWithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// ContextWithOrderID context with order id by given ctx, and orderID.
+//
+// It returns context.Context carrying orderID.
+func ContextWithOrderID(ctx context.Context, orderID int64) context.Context {
+	return context.WithValue(ctx, orderIDKey, orderID)
+}
+
+// WithContext with context by given ctx.
+//
+// It returns pointer of logrus.Entry with the request_id, user_id, order_id,
+// trace_id and span_id found in ctx already attached.
+func WithContext(ctx context.Context) *logrus.Entry {
+	fields := logrus.Fields{}
+
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		fields["request_id"] = requestID
+	}
+
+	if userID, ok := ctx.Value(userIDKey).(int64); ok {
+		fields["user_id"] = userID
+	}
+
+	if orderID, ok := ctx.Value(orderIDKey).(int64); ok {
+		fields["order_id"] = orderID
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields["trace_id"] = spanContext.TraceID().String()
+		fields["span_id"] = spanContext.SpanID().String()
+	}
+
+	return Logger.WithContext(ctx).WithFields(fields)
+}
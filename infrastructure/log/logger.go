@@ -1,17 +1,47 @@
 package log
 
 import (
+	// golang package
+	"orderfc/config"
+
+	// external package
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+var defaultRedactFields = []string{"shipping_address", "password", "secret", "token", "authorization"}
+
 var Logger *logrus.Logger
 
-func SetupLogger() {
+// SetupLogger setup logger by given cfg of config.LogConfig.
+//
+// An unknown level falls back to info, and an empty redact list falls back to the default sensitive fields.
+func SetupLogger(cfg config.LogConfig) {
 	log := logrus.New()
-	log.SetFormatter(&logrus.TextFormatter{
-		ForceColors:   true,
-		FullTimestamp: true,
-	})
+	if cfg.Format == FormatJSON {
+		log.SetFormatter(&logrus.JSONFormatter{})
+	} else {
+		log.SetFormatter(&logrus.TextFormatter{
+			ForceColors:   true,
+			FullTimestamp: true,
+		})
+	}
+
+	level, err := logrus.ParseLevel(cfg.Level)
+	if err != nil {
+		level = logrus.InfoLevel
+	}
+	log.SetLevel(level)
+
+	redactFields := cfg.RedactFields
+	if len(redactFields) == 0 {
+		redactFields = defaultRedactFields
+	}
+	log.AddHook(newRedactHook(redactFields))
 
 	log.Info("Log initiated using Logrus")
 	Logger = log
@@ -0,0 +1,58 @@
+package log
+
+import (
+	// golang package
+	"strings"
+
+	// external package
+	"github.com/sirupsen/logrus"
+)
+
+const redactedValue = "[REDACTED]"
+
+// Redactor is implemented by values that know how to hide their own sensitive fields before being logged.
+type Redactor interface {
+	Redact() interface{}
+}
+
+type redactHook struct {
+	fields map[string]bool
+}
+
+// newRedactHook new redact hook by given fields.
+//
+// It returns pointer of redactHook when successful.
+// Otherwise, nil pointer of redactHook will be returned.
+func newRedactHook(fields []string) *redactHook {
+	hook := &redactHook{fields: make(map[string]bool, len(fields))}
+	for _, field := range fields {
+		hook.fields[strings.ToLower(field)] = true
+	}
+
+	return hook
+}
+
+// Levels levels.
+//
+// It returns slice of logrus.Level the hook fires for.
+func (h *redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire fire by given entry pointer of logrus.Entry.
+//
+// It returns nil error.
+func (h *redactHook) Fire(entry *logrus.Entry) error {
+	for key, value := range entry.Data {
+		if h.fields[strings.ToLower(key)] {
+			entry.Data[key] = redactedValue
+			continue
+		}
+
+		if redactor, ok := value.(Redactor); ok {
+			entry.Data[key] = redactor.Redact()
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	// golang package
+	"errors"
+	"time"
+
+	// external package
+	"gorm.io/gorm"
+)
+
+const gormStartKey = "metrics:start_time"
+
+type GormPlugin struct{}
+
+// Name name.
+//
+// It returns string of the plugin name.
+func (GormPlugin) Name() string {
+	return "orderfc:metrics"
+}
+
+// Initialize initialize by given db pointer of gorm.DB.
+//
+// It registers callbacks that time every create, query, update, delete, row and raw statement.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	registrations := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callback.Create().Before("gorm:create").Register, callback.Create().After("gorm:create").Register},
+		{"query", callback.Query().Before("gorm:query").Register, callback.Query().After("gorm:query").Register},
+		{"update", callback.Update().Before("gorm:update").Register, callback.Update().After("gorm:update").Register},
+		{"delete", callback.Delete().Before("gorm:delete").Register, callback.Delete().After("gorm:delete").Register},
+		{"row", callback.Row().Before("gorm:row").Register, callback.Row().After("gorm:row").Register},
+		{"raw", callback.Raw().Before("gorm:raw").Register, callback.Raw().After("gorm:raw").Register},
+	}
+
+	for _, registration := range registrations {
+		err := registration.before("metrics:before_"+registration.operation, startTimer)
+		if err != nil {
+			return err
+		}
+
+		err = registration.after("metrics:after_"+registration.operation, observe(registration.operation))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// startTimer start timer by given db pointer of gorm.DB.
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+// observe observe by given operation.
+//
+// It returns the callback recording the statement latency.
+func observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+
+		startTime, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		err := db.Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		DBQueryDuration.WithLabelValues(operation, table, Result(err)).Observe(time.Since(startTime).Seconds())
+	}
+}
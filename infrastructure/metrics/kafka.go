@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	// external package
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+)
+
+type kafkaReaderCollector struct {
+	readers []*kafka.Reader
+	lag     *prometheus.Desc
+	offset  *prometheus.Desc
+}
+
+// RegisterKafkaReaders register kafka readers by given readers.
+//
+// Consumer lag and committed offset are read from the reader stats on every scrape.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func RegisterKafkaReaders(readers ...*kafka.Reader) error {
+	return prometheus.Register(&kafkaReaderCollector{
+		readers: readers,
+		lag: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kafka", "consumer_lag"),
+			"Messages between the consumer offset and the partition high watermark.",
+			[]string{"topic", "partition", "group"}, nil,
+		),
+		offset: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kafka", "consumer_offset"),
+			"Current consumer offset.",
+			[]string{"topic", "partition", "group"}, nil,
+		),
+	})
+}
+
+// Describe describe by given ch.
+func (c *kafkaReaderCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.lag
+	ch <- c.offset
+}
+
+// Collect collect by given ch.
+func (c *kafkaReaderCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, reader := range c.readers {
+		stats := reader.Stats()
+		group := reader.Config().GroupID
+
+		ch <- prometheus.MustNewConstMetric(c.lag, prometheus.GaugeValue, float64(stats.Lag), stats.Topic, stats.Partition, group)
+		ch <- prometheus.MustNewConstMetric(c.offset, prometheus.GaugeValue, float64(stats.Offset), stats.Topic, stats.Partition, group)
+	}
+}
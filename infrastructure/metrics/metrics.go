@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	// external package
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "orderfc"
+
+var (
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	GRPCRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "gRPC call latency by full method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	CheckoutTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checkout_total",
+		Help:      "Checkout attempts by outcome, where every outcome other than success is a failure reason.",
+	}, []string{"outcome"})
+
+	KafkaPublishTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_publish_total",
+		Help:      "Kafka messages published by topic and result.",
+	}, []string{"topic", "result"})
+
+	KafkaSchemaViolationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_schema_violations_total",
+		Help:      "Kafka events rejected by their event schema by event type and direction, publish or consume.",
+	}, []string{"event_type", "direction"})
+
+	KafkaDeadLetterTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_dead_letter_total",
+		Help:      "Consumed Kafka messages sent to the dead letter topic by source topic and result of the publish.",
+	}, []string{"topic", "result"})
+
+	KafkaConsumeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "kafka_consume_duration_seconds",
+		Help:      "Time spent processing a consumed Kafka message by topic and result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"topic", "result"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency by operation, table and result.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "result"})
+
+	CacheRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups by cache and result.",
+	}, []string{"cache", "result"})
+
+	CircuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_state",
+		Help:      "Circuit breaker state by dependency: 0 closed, 1 half open, 2 open.",
+	}, []string{"name"})
+
+	CircuitBreakerRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_rejected_total",
+		Help:      "Calls rejected by an open circuit breaker by dependency.",
+	}, []string{"name"})
+
+	DBReplicaFallbackTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_replica_fallback_total",
+		Help:      "Read queries retried on the primary after a replica failed.",
+	})
+
+	DBReplicaMissTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_replica_miss_total",
+		Help:      "Row lookups retried on the primary because the replica did not have the row yet.",
+	})
+
+	WebhookDeliveryAttemptsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_delivery_attempts_total",
+		Help:      "Webhook delivery attempts by event type and the resulting delivery status.",
+	}, []string{"event_type", "status"})
+
+	SagaStepsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "saga_steps_total",
+		Help:      "Finished checkout saga steps and compensations by step and status.",
+	}, []string{"step", "status"})
+
+	SagasFinishedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sagas_finished_total",
+		Help:      "Checkout sagas that reached a final state, by state.",
+	}, []string{"state"})
+)
+
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+const (
+	DirectionPublish = "publish"
+	DirectionConsume = "consume"
+)
+
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheError = "error"
+)
+
+const (
+	CheckoutSuccess          = "success"
+	CheckoutInvalidRequest   = "invalid_request"
+	CheckoutUnauthorized     = "unauthorized"
+	CheckoutDuplicateRequest = "duplicate_request"
+	CheckoutIdempotencyError = "idempotency_error"
+	CheckoutInvalidProducts  = "invalid_products"
+	CheckoutOrderDetailError = "order_detail_error"
+	CheckoutSaveOrderError   = "save_order_error"
+)
+
+// Result result by given err.
+//
+// It returns ResultSuccess when err is nil.
+// Otherwise, ResultError will be returned.
+func Result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+
+	return ResultSuccess
+}
@@ -0,0 +1,104 @@
+package openapi
+
+import (
+	// golang package
+	"fmt"
+	"sort"
+	"strings"
+
+	// external package
+	"github.com/gin-gonic/gin"
+)
+
+// Check check by given doc pointer of Document, and routes of gin.RoutesInfo.
+//
+// The document drifts from the handlers when a registered route is not
+// documented, a documented operation has no route, a path parameter is not
+// described, or an operation has no successful response.
+//
+// It returns nil error when the document matches routes.
+// Otherwise, error listing every difference will be returned.
+func Check(doc *Document, routes gin.RoutesInfo) error {
+	var problems []string
+
+	registered := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		key := route.Method + " " + Path(route.Path)
+		registered[key] = true
+
+		if !documented(doc, route.Method, Path(route.Path)) {
+			problems = append(problems, "route is not documented: "+key)
+		}
+	}
+
+	for path, item := range doc.Paths {
+		for method, operation := range item {
+			key := strings.ToUpper(method) + " " + path
+			if !registered[key] {
+				problems = append(problems, "documented operation has no route: "+key)
+			}
+
+			problems = append(problems, checkOperation(key, path, operation)...)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems)
+	return fmt.Errorf("openapi document drifted from the routes, %d problem(s):\n  - %s", len(problems), strings.Join(problems, "\n  - "))
+}
+
+// documented documented by given doc pointer of Document, method, and path in OpenAPI syntax.
+//
+// It returns true when doc has an operation for method and path.
+// Otherwise, false will be returned.
+func documented(doc *Document, method string, path string) bool {
+	item, ok := doc.Paths[path]
+	if !ok {
+		return false
+	}
+
+	_, ok = item[strings.ToLower(method)]
+	return ok
+}
+
+// checkOperation check operation by given key, path, and operation pointer of Operation.
+//
+// It returns slice of string describing each problem found in operation.
+func checkOperation(key string, path string, operation *Operation) []string {
+	var problems []string
+
+	parameters := map[string]bool{}
+	for _, parameter := range operation.Parameters {
+		if parameter.In == "path" {
+			parameters[parameter.Name] = true
+		}
+	}
+
+	for _, segment := range strings.Split(path, "/") {
+		name, ok := strings.CutPrefix(segment, "{")
+		if !ok {
+			continue
+		}
+
+		name = strings.TrimSuffix(name, "}")
+		if !parameters[name] {
+			problems = append(problems, fmt.Sprintf("path parameter %s is not described: %s", name, key))
+		}
+	}
+
+	successful := false
+	for status := range operation.Responses {
+		if strings.HasPrefix(status, "2") {
+			successful = true
+		}
+	}
+
+	if !successful {
+		problems = append(problems, "operation has no successful response: "+key)
+	}
+
+	return problems
+}
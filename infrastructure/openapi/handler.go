@@ -0,0 +1,70 @@
+package openapi
+
+import (
+	// golang package
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"orderfc/infrastructure/apperror"
+
+	// external package
+	"github.com/gin-gonic/gin"
+)
+
+const swaggerUIVersion = "5.17.14"
+
+var docsTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`))
+
+// Handler handler by given doc pointer of Document.
+//
+// The document is encoded once, it does not change while the service runs.
+//
+// It returns gin.HandlerFunc serving doc as JSON.
+func Handler(doc *Document) gin.HandlerFunc {
+	data, err := json.Marshal(doc)
+
+	return func(c *gin.Context) {
+		if err != nil {
+			_ = c.Error(apperror.Internal(err))
+			return
+		}
+
+		c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+	}
+}
+
+// DocsHandler docs handler by given title, and specURL of the served document.
+//
+// It returns gin.HandlerFunc serving a Swagger UI page for the document.
+func DocsHandler(title string, specURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+
+		err := docsTemplate.Execute(c.Writer, map[string]string{
+			"Title":   title,
+			"Version": swaggerUIVersion,
+			"SpecURL": specURL,
+		})
+		if err != nil {
+			_ = c.Error(apperror.Internal(err))
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package openapi
+
+import (
+	// golang package
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Example              interface{}        `json:"example,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+// TagFunc describes a custom binding tag on the schema of the field it is set
+// on, and reports whether the tag rejects the zero value like required does.
+type TagFunc func(schema *Schema, param string) bool
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// RegisterTag register tag by given name of the binding tag, and fn of TagFunc.
+//
+// Tags that are neither registered nor known to the document are ignored.
+func (d *Document) RegisterTag(name string, fn TagFunc) {
+	d.tagFuncs[name] = fn
+}
+
+// RegisterName register name by given value, and name of its component schema.
+//
+// Types are named after their Go type by default, which is ambiguous for
+// generic names such as Response.
+func (d *Document) RegisterName(value interface{}, name string) {
+	d.names[reflect.TypeOf(value)] = name
+}
+
+// Ref ref by given name of the component schema.
+//
+// It returns pointer of Schema referencing components.schemas.
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// SchemaOf schema of by given value.
+//
+// Named structs are added to components.schemas under their type name and
+// referenced, so every model is described once. Properties follow the json
+// tags and the binding tags add required fields and limits, which keeps the
+// document in line with what the handlers actually accept.
+//
+// It returns pointer of Schema describing the type of value.
+func (d *Document) SchemaOf(value interface{}) *Schema {
+	return d.schema(reflect.TypeOf(value))
+}
+
+// QueryParameters query parameters by given value of a struct bound with ShouldBindQuery.
+//
+// It returns slice of Parameter, one per form tag of value.
+func (d *Document) QueryParameters(value interface{}) []Parameter {
+	t := reflect.TypeOf(value)
+
+	parameters := make([]Parameter, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		schema := d.schema(field.Type)
+		required := d.applyBinding(schema, field.Tag.Get("binding"))
+		parameters = append(parameters, Parameter{
+			Name:     name,
+			In:       "query",
+			Required: required,
+			Schema:   schema,
+		})
+	}
+
+	return parameters
+}
+
+// schema schema by given t of reflect.Type.
+//
+// It returns pointer of Schema describing t.
+func (d *Document) schema(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{Description: "Any JSON value."}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := d.schema(t.Elem())
+		if schema.Ref != "" {
+			return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+		}
+
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+
+		name, ok := d.names[t]
+		if !ok {
+			name = t.Name()
+		}
+
+		if _, ok := d.Components.Schemas[name]; !ok {
+			// reserve the name first so recursive types end in a reference
+			d.Components.Schemas[name] = &Schema{}
+			*d.Components.Schemas[name] = *d.structSchema(t)
+		}
+
+		return Ref(name)
+	default:
+		// interface{} accepts any value
+		return &Schema{}
+	}
+}
+
+// structSchema struct schema by given t of reflect.Type.
+//
+// It returns pointer of Schema of an object with one property per json field.
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{},
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := d.schema(field.Type)
+		required := d.applyBinding(property, field.Tag.Get("binding"))
+		if required && !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = property
+	}
+
+	return schema
+}
+
+// applyBinding apply binding by given schema pointer of Schema, and tag of the binding struct tag.
+//
+// Rules after dive describe the items of an array.
+//
+// It returns true when the binding marks the field required.
+func (d *Document) applyBinding(schema *Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	required := false
+	target := schema
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			if target == schema {
+				required = true
+			}
+		case "dive":
+			if target.Items == nil {
+				return required
+			}
+
+			target = d.inline(target.Items)
+		case "min", "gte":
+			limit(target, param, true)
+		case "max", "lte":
+			limit(target, param, false)
+		case "gt":
+			limit(target, param, true)
+			target.ExclusiveMinimum = true
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				target.Enum = append(target.Enum, value)
+			}
+		default:
+			if fn, ok := d.tagFuncs[name]; ok && fn(d.inline(target), param) && target == schema {
+				required = true
+			}
+		}
+	}
+
+	return required
+}
+
+// inline inline by given schema pointer of Schema.
+//
+// It returns pointer of the component schema when schema is a reference, so
+// item rules are written on the model itself. Otherwise, schema is returned.
+func (d *Document) inline(schema *Schema) *Schema {
+	name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+	if !ok {
+		return schema
+	}
+
+	if component, ok := d.Components.Schemas[name]; ok {
+		return component
+	}
+
+	return schema
+}
+
+// limit limit by given schema pointer of Schema, param, and lower.
+//
+// The limit is a length for strings, a size for arrays and a value for numbers.
+func limit(schema *Schema, param string, lower bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	size := int(value)
+	switch schema.Type {
+	case "string":
+		if lower {
+			schema.MinLength = &size
+		} else {
+			schema.MaxLength = &size
+		}
+	case "array":
+		if lower {
+			schema.MinItems = &size
+		} else {
+			schema.MaxItems = &size
+		}
+	case "integer", "number":
+		if lower {
+			schema.Minimum = &value
+		} else {
+			schema.Maximum = &value
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package ratelimit
+
+import (
+	// golang package
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	// external package
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix     = "orderfc:ratelimit:"
+	sweepInterval = time.Minute
+	redisCooldown = 5 * time.Second
+)
+
+// slidingWindowScript keeps one sorted set member per accepted request in
+// every key and drops members older than the key's window before counting.
+// The request is only recorded when every key is below its limit, so a
+// denied request does not use up the other rules. It uses the Redis clock
+// so every instance agrees on the window boundaries.
+//
+// ARGV holds the member, then the window in milliseconds and the limit of
+// every key.
+//
+// It returns {allowed, remaining, retry_after_ms, reset_after_ms, ...} with
+// the last three repeated for every key.
+var slidingWindowScript = redis.NewScript(`
+local member = ARGV[1]
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local counts = {}
+local allowed = 1
+for index, key in ipairs(KEYS) do
+	local window = tonumber(ARGV[index * 2])
+	local limit = tonumber(ARGV[index * 2 + 1])
+
+	redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+	counts[index] = redis.call('ZCARD', key)
+	if counts[index] >= limit then
+		allowed = 0
+	end
+end
+
+local results = {allowed}
+for index, key in ipairs(KEYS) do
+	local window = tonumber(ARGV[index * 2])
+	local limit = tonumber(ARGV[index * 2 + 1])
+
+	local retry = 0
+	if allowed == 1 then
+		redis.call('ZADD', key, now, member)
+		counts[index] = counts[index] + 1
+	end
+	redis.call('PEXPIRE', key, window)
+
+	local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+	local reset = window
+	if oldest[2] then
+		reset = window - (now - tonumber(oldest[2]))
+	end
+
+	if allowed == 0 and counts[index] >= limit then
+		retry = reset
+	end
+
+	table.insert(results, math.max(limit - counts[index], 0))
+	table.insert(results, retry)
+	table.insert(results, reset)
+end
+
+return results
+`)
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	ResetAfter time.Duration
+}
+
+// Rule limits the requests counted under Key to Limit per Window.
+type Rule struct {
+	Key    string
+	Limit  int
+	Window time.Duration
+}
+
+type Limiter struct {
+	redis *redis.Client
+	local *localLimiter
+
+	// redisDownUntil holds the unix nano time until which Redis is skipped after a failure
+	redisDownUntil atomic.Int64
+}
+
+// NewLimiter new limiter by given redis pointer of redis.Client.
+//
+// It returns pointer of Limiter when successful.
+// Otherwise, nil pointer of Limiter will be returned.
+func NewLimiter(redis *redis.Client) *Limiter {
+	return &Limiter{
+		redis: redis,
+		local: &localLimiter{buckets: map[string]*bucket{}},
+	}
+}
+
+// Start start.
+//
+// It drops the idle buckets of the local fallback every minute until ctx is done.
+func (l *Limiter) Start(ctx context.Context) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			l.local.sweep(now)
+		}
+	}
+}
+
+// Allow allow by given rules.
+//
+// The request is allowed when every rule allows it, and only then counted
+// against all of them. Requests are counted in Redis with a sliding window.
+// When Redis cannot be reached the decision is made by in-memory token
+// buckets, which only see this instance's traffic, and the Redis error is
+// returned alongside it. Redis is not retried for a few seconds after a
+// failure so a broken connection does not add its timeout to every request.
+//
+// It returns Result of the most restrictive rule, and nil error when successful.
+// Otherwise, Result from the local fallback, and error will be returned.
+func (l *Limiter) Allow(ctx context.Context, rules []Rule) (Result, error) {
+	if len(rules) == 0 {
+		return Result{Allowed: true}, nil
+	}
+
+	if l.redis == nil || time.Now().UnixNano() < l.redisDownUntil.Load() {
+		return l.local.allow(rules), nil
+	}
+
+	keys := make([]string, 0, len(rules))
+	args := make([]interface{}, 0, 1+2*len(rules))
+	args = append(args, uuid.NewString())
+	for _, rule := range rules {
+		keys = append(keys, keyPrefix+rule.Key)
+		args = append(args, rule.Window.Milliseconds(), rule.Limit)
+	}
+
+	values, err := slidingWindowScript.Run(ctx, l.redis, keys, args...).Int64Slice()
+	if err != nil || len(values) != 1+3*len(rules) {
+		if err == nil {
+			err = fmt.Errorf("unexpected script result %v", values)
+		}
+
+		l.redisDownUntil.Store(time.Now().Add(redisCooldown).UnixNano())
+		return l.local.allow(rules), err
+	}
+
+	results := make([]Result, len(rules))
+	for index, rule := range rules {
+		offset := 1 + 3*index
+		results[index] = Result{
+			Allowed:    values[0] == 1,
+			Limit:      rule.Limit,
+			Remaining:  int(values[offset]),
+			RetryAfter: time.Duration(values[offset+1]) * time.Millisecond,
+			ResetAfter: time.Duration(values[offset+2]) * time.Millisecond,
+		}
+	}
+
+	return mostRestrictive(results), nil
+}
+
+// mostRestrictive most restrictive by given results of every rule.
+//
+// It returns Result with the longest wait of a denied request, or with the
+// fewest remaining requests of an allowed one.
+func mostRestrictive(results []Result) Result {
+	current := results[0]
+	for _, result := range results[1:] {
+		if result.Allowed {
+			if result.Remaining < current.Remaining {
+				current = result
+			}
+
+			continue
+		}
+
+		if result.RetryAfter > current.RetryAfter {
+			current = result
+		}
+	}
+
+	return current
+}
+
+type bucket struct {
+	tokens   float64
+	window   time.Duration
+	updateAt time.Time
+}
+
+type localLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// allow allow by given rules.
+//
+// Every bucket is refilled first, tokens are only taken when each bucket has one.
+//
+// It returns Result of the most restrictive rule.
+func (l *localLimiter) allow(rules []Rule) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	items := make([]*bucket, len(rules))
+	allowed := true
+	for index, rule := range rules {
+		rate := float64(rule.Limit) / rule.Window.Seconds()
+
+		item, ok := l.buckets[rule.Key]
+		if !ok {
+			item = &bucket{tokens: float64(rule.Limit), updateAt: now}
+			l.buckets[rule.Key] = item
+		}
+
+		item.tokens = math.Min(float64(rule.Limit), item.tokens+now.Sub(item.updateAt).Seconds()*rate)
+		item.window = rule.Window
+		item.updateAt = now
+
+		items[index] = item
+		allowed = allowed && item.tokens >= 1
+	}
+
+	results := make([]Result, len(rules))
+	for index, rule := range rules {
+		rate := float64(rule.Limit) / rule.Window.Seconds()
+		item := items[index]
+
+		result := Result{
+			Allowed: allowed,
+			Limit:   rule.Limit,
+		}
+
+		if allowed {
+			item.tokens--
+			result.Remaining = int(item.tokens)
+		} else if item.tokens < 1 {
+			result.RetryAfter = time.Duration((1 - item.tokens) / rate * float64(time.Second))
+		} else {
+			result.Remaining = int(item.tokens)
+		}
+
+		result.ResetAfter = time.Duration((float64(rule.Limit) - item.tokens) / rate * float64(time.Second))
+		results[index] = result
+	}
+
+	return mostRestrictive(results)
+}
+
+// sweep sweep by given now.
+//
+// Buckets untouched for a whole window of their own rule are full again and
+// can be dropped.
+func (l *localLimiter) sweep(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, item := range l.buckets {
+		if now.Sub(item.updateAt) > item.window {
+			delete(l.buckets, key)
+		}
+	}
+}
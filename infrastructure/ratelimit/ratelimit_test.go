@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	// golang package
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterDeniedRequestIsNotCounted(t *testing.T) {
+	limiter := NewLimiter(nil)
+	rules := []Rule{
+		{Key: "checkout:0:user:1", Limit: 5, Window: time.Minute},
+		{Key: "checkout:1:ip:10.0.0.1", Limit: 2, Window: time.Minute},
+	}
+
+	for attempt := 1; attempt <= 4; attempt++ {
+		result, err := limiter.Allow(context.Background(), rules)
+		if err != nil {
+			t.Fatalf("Allow() got error %v", err)
+		}
+
+		if want := attempt <= 2; result.Allowed != want {
+			t.Fatalf("attempt %d allowed = %t, want %t", attempt, result.Allowed, want)
+		}
+
+		if !result.Allowed && (result.Limit != 2 || result.RetryAfter <= 0) {
+			t.Errorf("denied by limit %d retrying after %s, want the ip rule", result.Limit, result.RetryAfter)
+		}
+	}
+
+	// the user rule only counted the two allowed requests
+	result, _ := limiter.Allow(context.Background(), rules[:1])
+	if !result.Allowed || result.Remaining != 2 {
+		t.Errorf("user rule allowed = %t with %d remaining, want true with 2", result.Allowed, result.Remaining)
+	}
+}
+
+func TestLimiterMostRestrictiveRule(t *testing.T) {
+	limiter := NewLimiter(nil)
+	rules := []Rule{
+		{Key: "history:0:user:1", Limit: 10, Window: time.Minute},
+		{Key: "history:1:user:1", Limit: 3, Window: time.Second},
+	}
+
+	result, _ := limiter.Allow(context.Background(), rules)
+	if !result.Allowed || result.Limit != 3 || result.Remaining != 2 {
+		t.Errorf("Allow() = %+v, want limit 3 with 2 remaining", result)
+	}
+}
+
+func TestLocalLimiterSweep(t *testing.T) {
+	limiter := NewLimiter(nil)
+	_, _ = limiter.Allow(context.Background(), []Rule{
+		{Key: "second", Limit: 1, Window: time.Second},
+		{Key: "minute", Limit: 1, Window: time.Minute},
+	})
+
+	limiter.local.sweep(time.Now().Add(2 * time.Second))
+
+	if _, ok := limiter.local.buckets["second"]; ok {
+		t.Error("bucket idle for longer than its window was kept")
+	}
+
+	if _, ok := limiter.local.buckets["minute"]; !ok {
+		t.Error("bucket still within its window was dropped")
+	}
+}
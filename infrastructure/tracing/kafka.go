@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	// golang package
+	"context"
+
+	// external package
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type KafkaHeaderCarrier struct {
+	Headers *[]kafka.Header
+}
+
+// Get get by given key.
+//
+// It returns string of the header value when present.
+// Otherwise, empty string will be returned.
+func (c KafkaHeaderCarrier) Get(key string) string {
+	for _, header := range *c.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+
+	return ""
+}
+
+// Set set by given key, and value.
+//
+// An existing header with the same key is replaced.
+func (c KafkaHeaderCarrier) Set(key string, value string) {
+	for index, header := range *c.Headers {
+		if header.Key == key {
+			(*c.Headers)[index].Value = []byte(value)
+			return
+		}
+	}
+
+	*c.Headers = append(*c.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+// Keys keys.
+//
+// It returns slice of string of the header keys.
+func (c KafkaHeaderCarrier) Keys() []string {
+	keys := make([]string, len(*c.Headers))
+	for index, header := range *c.Headers {
+		keys[index] = header.Key
+	}
+
+	return keys
+}
+
+// StartProducerSpan start producer span by given msg pointer of kafka.Message.
+//
+// The span context is injected into the message headers so consumers can continue the trace.
+//
+// It returns context.Context, and trace.Span.
+func StartProducerSpan(ctx context.Context, msg *kafka.Message) (context.Context, trace.Span) {
+	ctx, span := Tracer().Start(ctx, msg.Topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingKafkaMessageKey(string(msg.Key)),
+		),
+	)
+
+	otel.GetTextMapPropagator().Inject(ctx, KafkaHeaderCarrier{Headers: &msg.Headers})
+	return ctx, span
+}
+
+// StartConsumerSpan start consumer span by given msg pointer of kafka.Message, and group.
+//
+// The trace context found in the message headers becomes the parent of the span.
+//
+// It returns context.Context, and trace.Span.
+func StartConsumerSpan(ctx context.Context, msg *kafka.Message, group string) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, KafkaHeaderCarrier{Headers: &msg.Headers})
+
+	return Tracer().Start(ctx, msg.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeDeliver,
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingKafkaConsumerGroup(group),
+			semconv.MessagingKafkaMessageKey(string(msg.Key)),
+			semconv.MessagingKafkaMessageOffset(int(msg.Offset)),
+			attribute.Int("messaging.kafka.partition", msg.Partition),
+		),
+	)
+}
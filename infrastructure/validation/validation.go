@@ -0,0 +1,194 @@
+package validation
+
+import (
+	// golang package
+	"errors"
+	"fmt"
+	"orderfc/infrastructure/apperror"
+	"orderfc/infrastructure/constant"
+	"orderfc/models"
+	"reflect"
+	"strings"
+
+	// external package
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrInvalidRequest   = apperror.Validation("invalid_request", "invalid request")
+	ErrValidationFailed = apperror.Validation("validation_failed", "request validation failed")
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Register register.
+//
+// It adds the custom validators to gin's binding engine and reports fields by
+// their JSON name, so binding and Struct return the same field paths.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func Register() error {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("unexpected binding validator engine")
+	}
+
+	validate.RegisterTagNameFunc(jsonName)
+
+	validators := map[string]validator.Func{
+		"product_id":     validateProductID,
+		"payment_method": validatePaymentMethod,
+	}
+
+	for tag, fn := range validators {
+		err := validate.RegisterValidation(tag, fn)
+		if err != nil {
+			return err
+		}
+	}
+
+	validate.RegisterStructValidation(validateCheckoutRequest, models.CheckoutRequest{})
+	return nil
+}
+
+// Struct struct by given value.
+//
+// It returns nil error when value is valid.
+// Otherwise, error listing every invalid field will be returned.
+func Struct(value interface{}) error {
+	return FromError(binding.Validator.ValidateStruct(value))
+}
+
+// FromError from error by given err of binding or validation.
+//
+// It returns nil error when err is nil, ErrValidationFailed with one
+// FieldError per invalid field for validation errors.
+// Otherwise, ErrInvalidRequest wrapping err will be returned.
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return ErrInvalidRequest.WithDetails(err.Error()).Wrap(err)
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(fieldError),
+			Code:    fieldError.Tag(),
+			Message: message(fieldError),
+		})
+	}
+
+	return ErrValidationFailed.WithDetails(fields).Wrap(err)
+}
+
+// validateCheckoutRequest validate checkout request by given sl of validator.StructLevel.
+//
+// Duplicate products are reported on each repeated item instead of on the
+// whole list, so the remaining item errors are still returned alongside.
+func validateCheckoutRequest(sl validator.StructLevel) {
+	request := sl.Current().Interface().(models.CheckoutRequest)
+
+	seen := make(map[int64]bool, len(request.Items))
+	for index, item := range request.Items {
+		if seen[item.ProductID] {
+			sl.ReportError(item.ProductID, fmt.Sprintf("items[%d].product_id", index), "ProductID", "unique", "")
+		}
+
+		seen[item.ProductID] = true
+	}
+}
+
+// validateProductID validate product id by given fl of validator.FieldLevel.
+//
+// It returns true when the product id is a positive integer.
+func validateProductID(fl validator.FieldLevel) bool {
+	switch fl.Field().Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return fl.Field().Int() > 0
+	default:
+		return false
+	}
+}
+
+// validatePaymentMethod validate payment method by given fl of validator.FieldLevel.
+//
+// It returns true when the payment method is supported.
+func validatePaymentMethod(fl validator.FieldLevel) bool {
+	return constant.PaymentMethods[fl.Field().String()]
+}
+
+// fieldPath field path by given fieldError of validator.FieldError.
+//
+// It returns string of the JSON path without the root struct, e.g. items[1].quantity.
+func fieldPath(fieldError validator.FieldError) string {
+	_, path, found := strings.Cut(fieldError.Namespace(), ".")
+	if !found {
+		return fieldError.Field()
+	}
+
+	return path
+}
+
+// message message by given fieldError of validator.FieldError.
+//
+// It returns string of the readable message.
+func message(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", fieldError.Param(), unit(fieldError.Kind()))
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", fieldError.Param(), unit(fieldError.Kind()))
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fieldError.Param())
+	case "unique":
+		return "must not repeat a product already in the order"
+	case "product_id":
+		return "must be a positive product id"
+	case "payment_method":
+		return "must be one of the supported payment methods"
+	default:
+		return fmt.Sprintf("failed on the %s validation", fieldError.Tag())
+	}
+}
+
+// unit unit by given kind of the validated field.
+//
+// It returns string of the unit min and max are counted in.
+func unit(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " item(s)"
+	default:
+		return ""
+	}
+}
+
+// jsonName json name by given field of reflect.StructField.
+//
+// It returns string of the field's JSON name, or the Go name when it has none.
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	default:
+		return name
+	}
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	// golang package
+	"context"
+	"orderfc/infrastructure/log"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 20
+)
+
+// BatchFunc claims up to limit due items, handles them and returns how many
+// it claimed.
+type BatchFunc func(ctx context.Context, limit int) (int, error)
+
+// Poller runs the due items of a table in batches, e.g. webhook deliveries
+// or checkout sagas. Items are claimed with a lease that outlasts handling
+// them, so several instances can poll the same table and an item is only
+// claimed again when its worker died before recording the result.
+type Poller struct {
+	name      string
+	interval  time.Duration
+	batchSize int
+	batch     BatchFunc
+	running   atomic.Bool
+}
+
+// NewPoller new poller by given name used in logs, interval, batchSize, and batch of BatchFunc.
+//
+// It returns pointer of Poller, polling every second in batches of 20 when
+// interval or batchSize is not set.
+func NewPoller(name string, interval time.Duration, batchSize int, batch BatchFunc) *Poller {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	return &Poller{
+		name:      name,
+		interval:  interval,
+		batchSize: batchSize,
+		batch:     batch,
+	}
+}
+
+// Start start.
+//
+// It runs batches until ctx is done. A full batch is followed by the next
+// one right away, otherwise the poller waits for the interval.
+func (p *Poller) Start(ctx context.Context) {
+	p.running.Store(true)
+	defer p.running.Store(false)
+
+	log.Logger.Infof("[%s] Worker polling every %s", p.name, p.interval)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		claimed, err := p.batch(ctx, p.batchSize)
+		if err != nil {
+			log.Logger.Errorf("[%s] Error Run Batch: %v", p.name, err)
+		}
+
+		if err == nil && claimed == p.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Running running.
+//
+// It returns true while the poller is running batches.
+// Otherwise, false will be returned.
+func (p *Poller) Running() bool {
+	return p.running.Load()
+}
+
+// Backoff is the wait between failed attempts, doubling from Initial up to Max.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// Delay delay by given attempts.
+//
+// It returns time.Duration to wait after the attempts failed.
+func (b Backoff) Delay(attempts int) time.Duration {
+	delay := b.Initial
+	for i := 1; i < attempts && delay < b.Max; i++ {
+		delay *= 2
+	}
+
+	return min(delay, b.Max)
+}
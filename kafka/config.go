@@ -0,0 +1,126 @@
+package kafka
+
+import (
+	// golang package
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"orderfc/config"
+	"os"
+	"time"
+
+	// external package
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+const dialTimeout = 10 * time.Second
+
+// Topic topic by given cfg of config.KafkaConfig, and name.
+//
+// It returns string of the topic name with the configured prefix.
+func Topic(cfg config.KafkaConfig, name string) string {
+	return cfg.TopicPrefix + name
+}
+
+// NewDialer new dialer by given cfg of config.KafkaConfig.
+//
+// The dialer is used by consumers and health checks so they connect with the
+// same SASL and TLS settings as the producer.
+//
+// It returns pointer of kafka.Dialer, and nil error when successful.
+// Otherwise, nil pointer of kafka.Dialer, and error will be returned.
+func NewDialer(cfg config.KafkaConfig) (*kafka.Dialer, error) {
+	mechanism, err := saslMechanism(cfg.SASL)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := newTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Dialer{
+		Timeout:       dialTimeout,
+		DualStack:     true,
+		SASLMechanism: mechanism,
+		TLS:           tlsConfig,
+	}, nil
+}
+
+// requiredAcks required acks by given acks.
+//
+// It returns kafka.RequiredAcks, defaulting to every in-sync replica.
+func requiredAcks(acks string) kafka.RequiredAcks {
+	switch acks {
+	case "none":
+		return kafka.RequireNone
+	case "one":
+		return kafka.RequireOne
+	default:
+		return kafka.RequireAll
+	}
+}
+
+// saslMechanism sasl mechanism by given cfg of config.KafkaSASLConfig.
+//
+// It returns sasl.Mechanism, nil when SASL is disabled, and nil error when successful.
+// Otherwise, nil sasl.Mechanism, and error will be returned.
+func saslMechanism(cfg config.KafkaSASLConfig) (sasl.Mechanism, error) {
+	switch cfg.Mechanism {
+	case "":
+		return nil, nil
+	case "plain":
+		return plain.Mechanism{Username: cfg.Username, Password: cfg.Password}, nil
+	case "scram-sha-256":
+		return scram.Mechanism(scram.SHA256, cfg.Username, cfg.Password)
+	case "scram-sha-512":
+		return scram.Mechanism(scram.SHA512, cfg.Username, cfg.Password)
+	default:
+		return nil, fmt.Errorf("unsupported kafka sasl mechanism %q", cfg.Mechanism)
+	}
+}
+
+// newTLSConfig new tls config by given cfg of config.KafkaTLSConfig.
+//
+// It returns pointer of tls.Config, nil when TLS is disabled, and nil error when successful.
+// Otherwise, nil pointer of tls.Config, and error will be returned.
+func newTLSConfig(cfg config.KafkaTLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		caCert, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read kafka ca file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, errors.New("kafka ca file contains no certificates")
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load kafka client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
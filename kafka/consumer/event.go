@@ -0,0 +1,57 @@
+package consumer
+
+import (
+	// golang package
+	"context"
+	"fmt"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/metrics"
+	kafkaFC "orderfc/kafka"
+	"orderfc/kafka/schema"
+	"orderfc/models"
+
+	// external package
+	"github.com/segmentio/kafka-go"
+)
+
+// decodeEvent decode event by given schemas pointer of schema.Registry, message of kafka.Message, eventType, and event.
+//
+// The payload is checked against the schema of eventType before event is
+// used, so a message missing a field is rejected instead of read as its
+// zero value.
+//
+// It returns models.EventEnvelope, and nil error when successful.
+// Otherwise, empty models.EventEnvelope, and error will be returned.
+func decodeEvent(schemas *schema.Registry, message kafka.Message, eventType string, event interface{}) (models.EventEnvelope, error) {
+	envelope, err := kafkaFC.DecodeMessage(message, event)
+	if err != nil {
+		return models.EventEnvelope{}, err
+	}
+
+	if envelope.Type != "" && envelope.Type != eventType {
+		return models.EventEnvelope{}, fmt.Errorf("unexpected event type %q, expected %q", envelope.Type, eventType)
+	}
+
+	err = schemas.Validate(eventType, envelope.SchemaVersion, envelope.Payload)
+	if err != nil {
+		metrics.KafkaSchemaViolationsTotal.WithLabelValues(eventType, metrics.DirectionConsume).Inc()
+		return models.EventEnvelope{}, err
+	}
+
+	return envelope, nil
+}
+
+// deadLetter dead letter by given producer of KafkaProducer, message of kafka.Message, group, and reason.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func deadLetter(ctx context.Context, producer kafkaFC.KafkaProducer, message kafka.Message, group string, reason error) error {
+	err := producer.PublishDeadLetter(ctx, message, group, reason)
+	if err != nil {
+		log.WithContext(ctx).Errorf("[KAFKA] Error Publish Dead Letter of %s offset %d: %v", message.Topic, message.Offset, err)
+		return err
+	}
+
+	log.WithContext(ctx).Warnf("[KAFKA] Sent %s offset %d to the dead letter topic: %v", message.Topic, message.Offset, reason)
+	return nil
+}
@@ -0,0 +1,74 @@
+package consumer
+
+import (
+	// golang package
+	"context"
+	"fmt"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/metrics"
+	"runtime/debug"
+	"time"
+)
+
+// Tracing tracing.
+//
+// It returns Middleware continuing the producer's trace in a consumer span.
+func Tracing() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message Message) error {
+			ctx, span := startMessageSpan(ctx, &message.Message, message.GroupID)
+			err := next(ctx, message)
+			endMessageSpan(span, err)
+			return err
+		}
+	}
+}
+
+// Metrics metrics.
+//
+// It returns Middleware observing the handling time by topic and result.
+func Metrics() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message Message) error {
+			startTime := time.Now()
+			err := next(ctx, message)
+			metrics.KafkaConsumeDuration.WithLabelValues(message.Topic, metrics.Result(err)).Observe(time.Since(startTime).Seconds())
+			return err
+		}
+	}
+}
+
+// Logging logging.
+//
+// It returns Middleware logging the failed messages with their position.
+func Logging() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message Message) error {
+			err := next(ctx, message)
+			if err != nil {
+				log.WithContext(ctx).Errorf("[KAFKA] Error Handle %s partition %d offset %d: %v", message.Topic, message.Partition, message.Offset, err)
+			}
+
+			return err
+		}
+	}
+}
+
+// Recover recover.
+//
+// It returns Middleware turning a panicking handler into an error, so one
+// bad message does not stop the consumer.
+func Recover() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, message Message) (err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					log.WithContext(ctx).Errorf("[KAFKA] Panic Handle %s offset %d: %v\n%s", message.Topic, message.Offset, recovered, debug.Stack())
+					err = fmt.Errorf("handler panicked: %v", recovered)
+				}
+			}()
+
+			return next(ctx, message)
+		}
+	}
+}
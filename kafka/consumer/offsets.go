@@ -0,0 +1,84 @@
+package consumer
+
+import (
+	// golang package
+	"sync"
+
+	// external package
+	"github.com/segmentio/kafka-go"
+)
+
+// offsetTracker finds the offsets that are safe to commit while messages of
+// a partition are handled concurrently and finish out of order.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[int]*partitionOffsets
+}
+
+type partitionOffsets struct {
+	// fetched offsets in order, up to the first one still being handled
+	pending []int64
+	// handled state of every pending offset
+	handled map[int64]bool
+}
+
+// newOffsetTracker new offset tracker.
+//
+// It returns pointer of offsetTracker.
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: map[int]*partitionOffsets{}}
+}
+
+// track track by given message of kafka.Message.
+//
+// Fetching an offset that is not past the last one means the partition was
+// rewound by a rebalance, the offsets tracked before are dropped since the
+// messages are delivered again.
+func (t *offsetTracker) track(message kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	partition, ok := t.partitions[message.Partition]
+	if !ok || (len(partition.pending) > 0 && message.Offset <= partition.pending[len(partition.pending)-1]) {
+		partition = &partitionOffsets{handled: map[int64]bool{}}
+		t.partitions[message.Partition] = partition
+	}
+
+	partition.pending = append(partition.pending, message.Offset)
+	partition.handled[message.Offset] = false
+}
+
+// done done by given message of kafka.Message.
+//
+// It returns kafka.Message of the last offset of the partition that can be committed, and true when it moved forward.
+// Otherwise, empty kafka.Message, and false will be returned.
+func (t *offsetTracker) done(message kafka.Message) (kafka.Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	partition, ok := t.partitions[message.Partition]
+	if !ok {
+		return kafka.Message{}, false
+	}
+
+	if _, ok := partition.handled[message.Offset]; !ok {
+		// tracked before a rebalance
+		return kafka.Message{}, false
+	}
+
+	partition.handled[message.Offset] = true
+
+	commit := kafka.Message{Topic: message.Topic, Partition: message.Partition, Offset: -1}
+	for len(partition.pending) > 0 && partition.handled[partition.pending[0]] {
+		commit.Offset = partition.pending[0]
+
+		partition.pending = partition.pending[1:]
+		delete(partition.handled, commit.Offset)
+	}
+
+	if commit.Offset < 0 {
+		return kafka.Message{}, false
+	}
+
+	return commit, true
+}
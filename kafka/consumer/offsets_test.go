@@ -0,0 +1,141 @@
+package consumer
+
+import (
+	// golang package
+	"testing"
+
+	// external package
+	"github.com/segmentio/kafka-go"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	type op struct {
+		// fetched when true, handled otherwise
+		track     bool
+		partition int
+		offset    int64
+		// the offset to commit after the message was handled, -1 for none
+		wantCommit int64
+	}
+
+	fetch := func(partition int, offset int64) op {
+		return op{track: true, partition: partition, offset: offset}
+	}
+
+	handle := func(partition int, offset int64, wantCommit int64) op {
+		return op{partition: partition, offset: offset, wantCommit: wantCommit}
+	}
+
+	tests := []struct {
+		name string
+		ops  []op
+	}{
+		{
+			name: "in order",
+			ops: []op{
+				fetch(0, 1), fetch(0, 2),
+				handle(0, 1, 1),
+				handle(0, 2, 2),
+			},
+		},
+		{
+			name: "out of order waits for the earliest",
+			ops: []op{
+				fetch(0, 1), fetch(0, 2), fetch(0, 3),
+				handle(0, 3, -1),
+				handle(0, 2, -1),
+				handle(0, 1, 3),
+			},
+		},
+		{
+			name: "earliest handled commits up to the next one running",
+			ops: []op{
+				fetch(0, 1), fetch(0, 2), fetch(0, 3), fetch(0, 4),
+				handle(0, 2, -1),
+				handle(0, 1, 2),
+				handle(0, 4, -1),
+				handle(0, 3, 4),
+			},
+		},
+		{
+			name: "gaps between offsets",
+			ops: []op{
+				fetch(0, 10), fetch(0, 12), fetch(0, 15),
+				handle(0, 12, -1),
+				handle(0, 10, 12),
+				handle(0, 15, 15),
+			},
+		},
+		{
+			name: "partitions are independent",
+			ops: []op{
+				fetch(0, 1), fetch(1, 1), fetch(0, 2),
+				handle(0, 2, -1),
+				handle(1, 1, 1),
+				handle(0, 1, 2),
+			},
+		},
+		{
+			name: "rewound partition drops the offsets tracked before",
+			ops: []op{
+				fetch(0, 5), fetch(0, 6),
+				fetch(0, 5),
+				handle(0, 6, -1),
+				handle(0, 5, 5),
+			},
+		},
+		{
+			name: "redelivered after a rebalance",
+			ops: []op{
+				fetch(0, 5), fetch(0, 6),
+				fetch(0, 6),
+				handle(0, 5, -1),
+				handle(0, 6, 6),
+			},
+		},
+		{
+			name: "partition never fetched",
+			ops: []op{
+				handle(3, 1, -1),
+			},
+		},
+		{
+			name: "handled twice",
+			ops: []op{
+				fetch(0, 1), fetch(0, 2),
+				handle(0, 1, 1),
+				handle(0, 1, -1),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tracker := newOffsetTracker()
+			for index, op := range test.ops {
+				message := kafka.Message{Topic: "payment.success", Partition: op.partition, Offset: op.offset}
+				if op.track {
+					tracker.track(message)
+					continue
+				}
+
+				commit, ok := tracker.done(message)
+				if op.wantCommit < 0 {
+					if ok {
+						t.Fatalf("op %d: done(%d/%d) committed %d, want nothing", index, op.partition, op.offset, commit.Offset)
+					}
+
+					continue
+				}
+
+				if !ok || commit.Offset != op.wantCommit {
+					t.Fatalf("op %d: done(%d/%d) = %d, %t, want %d", index, op.partition, op.offset, commit.Offset, ok, op.wantCommit)
+				}
+
+				if commit.Topic != message.Topic || commit.Partition != op.partition {
+					t.Errorf("op %d: committed %s/%d, want %s/%d", index, commit.Topic, commit.Partition, message.Topic, op.partition)
+				}
+			}
+		})
+	}
+}
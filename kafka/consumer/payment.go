@@ -0,0 +1,25 @@
+package consumer
+
+import (
+	// golang package
+	"orderfc/cmd/order/service"
+	kafkaFC "orderfc/kafka"
+	"orderfc/saga"
+)
+
+type PaymentHandler struct {
+	OrderService service.OrderService
+	Producer     kafkaFC.KafkaProducer
+	Saga         *saga.Orchestrator
+}
+
+// NewPaymentHandler new payment handler by given OrderService, KafkaProducer, and orchestrator pointer of saga.Orchestrator.
+//
+// It returns pointer of PaymentHandler.
+func NewPaymentHandler(orderService service.OrderService, kafkaProducer kafkaFC.KafkaProducer, orchestrator *saga.Orchestrator) *PaymentHandler {
+	return &PaymentHandler{
+		OrderService: orderService,
+		Producer:     kafkaProducer,
+		Saga:         orchestrator,
+	}
+}
@@ -4,95 +4,75 @@ import (
 	// golang package
 	"context"
 	"encoding/json"
-	"log"
-	"orderfc/cmd/order/service"
+	"errors"
 	"orderfc/infrastructure/constant"
-	kafkaFC "orderfc/kafka"
+	"orderfc/infrastructure/log"
 	"orderfc/models"
+	"orderfc/saga"
 	"time"
-
-	// external package
-	"github.com/segmentio/kafka-go"
 )
 
-type PaymentFailedConsumer struct {
-	Reader       *kafka.Reader
-	Producer     kafkaFC.KafkaProducer
-	OrderService service.OrderService
-}
-
-// NewPaymentFailedConsumer new payment failed consumer by given slice of brokers, topic, OrderService, and KafkaProducer.
+// HandlePaymentFailed handle payment failed by given event of models.PaymentUpdateStatusEvent.
 //
-// It returns PaymentFailedConsumer when successful.
-// Otherwise, empty PaymentFailedConsumer will be returned.
-func NewPaymentFailedConsumer(brokers []string, topic string, orderService service.OrderService, kafkaProducer kafkaFC.KafkaProducer) *PaymentFailedConsumer {
-	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers: brokers,
-		Topic:   topic,
-		GroupID: "orderfc",
-	})
-
-	return &PaymentFailedConsumer{
-		Reader:       reader,
-		Producer:     kafkaProducer,
-		OrderService: orderService,
-	}
-}
-
-// Start start.
-func (c *PaymentFailedConsumer) Start(ctx context.Context) {
-	log.Println("Listening to topic payment.failed")
+// The checkout saga of the order releases the stock and cancels it. Orders
+// without a saga are cancelled and the stock of their products is rolled
+// back right away.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (h PaymentHandler) HandlePaymentFailed(ctx context.Context, event models.PaymentUpdateStatusEvent) error {
+	ctx = log.ContextWithOrderID(ctx, event.OrderID)
 
-	for {
-		message, err := c.Reader.ReadMessage(ctx)
+	err := h.Saga.PaymentFailed(ctx, event.OrderID, event.Status)
+	if !errors.Is(err, saga.ErrSagaNotFound) {
 		if err != nil {
-			log.Println("[PF] Failed to ReadMessage: ", err)
-			continue
+			log.WithContext(ctx).Errorf("[PF] Error Record Payment on Saga: %v", err)
 		}
 
-		var event models.PaymentUpdateStatusEvent
-		err = json.Unmarshal(message.Value, &event)
-		if err != nil {
-			log.Println("[PF] Error Unmarshal Payment Update Status Event: ", err)
-			continue
-		}
+		return err
+	}
 
-		// update DB status order
-		err = c.OrderService.UpdateOrderStatus(ctx, event.OrderID, constant.OrderStatusCancelled)
-		if err != nil {
-			log.Println("[PF] Error Update Order Status: ", err)
-			continue
-		}
+	// update DB status order
+	err = h.OrderService.UpdateOrderStatus(ctx, event.OrderID, constant.OrderStatusCancelled)
+	if err != nil {
+		log.WithContext(ctx).Errorf("[PF] Error Update Order Status: %v", err)
+		return err
+	}
 
-		// order info
-		orderInfo, err := c.OrderService.GetOrderInfoByOrderID(ctx, event.OrderID)
-		if err != nil {
-			continue
-		}
+	// order info
+	orderInfo, err := h.OrderService.GetOrderInfoByOrderID(ctx, event.OrderID)
+	if err != nil {
+		log.WithContext(ctx).Errorf("[PF] Error Get Order Info: %v", err)
+		return err
+	}
 
-		// order detail info
-		orderDetailInfo, err := c.OrderService.GetOrderDetailByOrderDetailID(ctx, orderInfo.OrderDetailID)
-		if err != nil {
-			continue
-		}
+	// order detail info
+	orderDetailInfo, err := h.OrderService.GetOrderDetailByOrderDetailID(ctx, orderInfo.UserID, orderInfo.OrderDetailID)
+	if err != nil {
+		log.WithContext(ctx).Errorf("[PF] Error Get Order Detail: %v", err)
+		return err
+	}
 
-		// construct products
-		var products []models.CheckoutItem
-		err = json.Unmarshal([]byte(orderDetailInfo.Products), &products)
-		if err != nil {
-			continue
-		}
+	// construct products
+	var products []models.CheckoutItem
+	err = json.Unmarshal([]byte(orderDetailInfo.Products), &products)
+	if err != nil {
+		log.WithContext(ctx).Errorf("[PF] Error Unmarshal Products: %v", err)
+		return Permanent(err)
+	}
 
-		// publish event stock.rollback
-		updateStockEvent := models.ProductStockUpdateEvent{
-			OrderID:   event.OrderID,
-			Products:  convertCheckoutItemToProductItems(products),
-			EventTime: time.Now(),
-		}
+	// publish event stock.rollback
+	updateStockEvent := models.ProductStockUpdateEvent{
+		OrderID:   event.OrderID,
+		Products:  models.ConvertCheckoutItemToProductItems(products),
+		EventTime: time.Now(),
+	}
 
-		err = c.Producer.PublishProductStockRollback(ctx, updateStockEvent)
-		if err != nil {
-			continue
-		}
+	err = h.Producer.PublishProductStockRollback(ctx, updateStockEvent)
+	if err != nil {
+		log.WithContext(ctx).Errorf("[PF] Error Publish Stock Rollback: %v", err)
+		return err
 	}
+
+	return nil
 }
@@ -0,0 +1,450 @@
+package consumer
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"hash/fnv"
+	"orderfc/config"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/worker"
+	kafkaFC "orderfc/kafka"
+	"orderfc/kafka/schema"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	// external package
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	defaultConcurrency     = 1
+	defaultShutdownTimeout = 30 * time.Second
+	defaultMaxAttempts     = 5
+	defaultInitialBackoff  = time.Second
+	defaultMaxBackoff      = 30 * time.Second
+
+	// offsets of handled messages are committed in the background at this
+	// interval, and once more when the reader is closed
+	commitInterval = time.Second
+
+	// messages queued per handler before fetching waits for it
+	handlerQueueSize = 16
+)
+
+type Message struct {
+	kafka.Message
+	GroupID string
+}
+
+// HandlerFunc handles a consumed message. A failed message is handled again
+// after a backoff, and sent to the dead letter topic once it ran out of
+// attempts, unless the error was made by Permanent.
+type HandlerFunc func(ctx context.Context, message Message) error
+
+// permanentError marks an error handling the message again cannot fix.
+type permanentError struct {
+	err error
+}
+
+// Middleware wraps every registered handler, the first one used is the
+// outermost.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+type Route struct {
+	// Name identifies the route in logs and health checks.
+	Name string
+	// Topic is the topic name before the configured prefix is added.
+	Topic   string
+	GroupID string
+	// EventType selects the schema the payload is checked against.
+	EventType string
+	// Concurrency overrides the configured number of handlers when set.
+	Concurrency int
+}
+
+type Runner struct {
+	cfg         config.KafkaConfig
+	dialer      *kafka.Dialer
+	producer    kafkaFC.KafkaProducer
+	schemas     *schema.Registry
+	middlewares []Middleware
+	routes      []*consumerRoute
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+type consumerRoute struct {
+	Route
+	reader  *kafka.Reader
+	handler HandlerFunc
+	running atomic.Bool
+}
+
+// NewRunner new runner by given cfg of config.KafkaConfig, KafkaProducer, and schemas pointer of schema.Registry.
+//
+// It returns pointer of Runner without routes, and nil error when successful.
+// Otherwise, nil pointer of Runner, and error will be returned.
+func NewRunner(cfg config.KafkaConfig, producer kafkaFC.KafkaProducer, schemas *schema.Registry) (*Runner, error) {
+	dialer, err := kafkaFC.NewDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Consumer.Concurrency <= 0 {
+		cfg.Consumer.Concurrency = defaultConcurrency
+	}
+
+	if cfg.Consumer.ShutdownTimeout <= 0 {
+		cfg.Consumer.ShutdownTimeout = defaultShutdownTimeout
+	}
+
+	if cfg.Consumer.MaxAttempts <= 0 {
+		cfg.Consumer.MaxAttempts = defaultMaxAttempts
+	}
+
+	if cfg.Consumer.InitialBackoff <= 0 {
+		cfg.Consumer.InitialBackoff = defaultInitialBackoff
+	}
+
+	if cfg.Consumer.MaxBackoff <= 0 {
+		cfg.Consumer.MaxBackoff = defaultMaxBackoff
+	}
+
+	return &Runner{
+		cfg:      cfg,
+		dialer:   dialer,
+		producer: producer,
+		schemas:  schemas,
+	}, nil
+}
+
+// Use use by given middlewares.
+//
+// Middlewares apply to every route, including the ones registered before.
+func (r *Runner) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// Register register by given runner pointer of Runner, route of Route, and handle.
+//
+// The payload of every message is decoded into T after it passed the schema
+// of the route's event type. Messages that cannot be decoded or break the
+// schema are sent to the dead letter topic right away instead of reaching
+// handle.
+func Register[T any](runner *Runner, route Route, handle func(ctx context.Context, event T) error) {
+	runner.add(route, func(ctx context.Context, message Message) error {
+		var event T
+		_, err := decodeEvent(runner.schemas, message.Message, route.EventType, &event)
+		if err != nil {
+			return Permanent(err)
+		}
+
+		return handle(ctx, event)
+	})
+}
+
+// Permanent permanent by given err.
+//
+// It returns error that sends the message to the dead letter topic without
+// handling it again.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Error error.
+//
+// It returns string of the wrapped error.
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap unwrap.
+//
+// It returns error that was made permanent.
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// add add by given route of Route, and handler of HandlerFunc.
+func (r *Runner) add(route Route, handler HandlerFunc) {
+	if route.Concurrency <= 0 {
+		route.Concurrency = r.cfg.Consumer.Concurrency
+	}
+
+	route.Topic = kafkaFC.Topic(r.cfg, route.Topic)
+	r.routes = append(r.routes, &consumerRoute{
+		Route: route,
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers:        r.cfg.Brokers,
+			Topic:          route.Topic,
+			GroupID:        route.GroupID,
+			Dialer:         r.dialer,
+			CommitInterval: commitInterval,
+		}),
+		handler: handler,
+	})
+}
+
+// Readers readers.
+//
+// It returns slice of pointer of kafka.Reader, one per registered route.
+func (r *Runner) Readers() []*kafka.Reader {
+	readers := make([]*kafka.Reader, len(r.routes))
+	for index, route := range r.routes {
+		readers[index] = route.reader
+	}
+
+	return readers
+}
+
+// Names names.
+//
+// It returns slice of string of every registered route name.
+func (r *Runner) Names() []string {
+	names := make([]string, len(r.routes))
+	for index, route := range r.routes {
+		names[index] = route.Name
+	}
+
+	return names
+}
+
+// Running running by given name of the route.
+//
+// It returns true while the route is fetching messages.
+// Otherwise, false will be returned.
+func (r *Runner) Running(name string) bool {
+	for _, route := range r.routes {
+		if route.Name == name {
+			return route.running.Load()
+		}
+	}
+
+	return false
+}
+
+// Start start.
+//
+// Every registered route is consumed until ctx is done or Stop is called,
+// and Start returns once their in-flight messages are handled.
+func (r *Runner) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+
+	r.mu.Lock()
+	r.cancel = cancel
+	r.stopped = stopped
+	r.mu.Unlock()
+
+	defer close(stopped)
+
+	var wg sync.WaitGroup
+	for _, route := range r.routes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.consume(ctx, route)
+		}()
+	}
+	wg.Wait()
+}
+
+// Stop stop.
+//
+// It stops fetching and waits up to the shutdown timeout for the in-flight
+// messages, the offsets of messages still running then are not committed
+// and they are consumed again after a restart.
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	cancel, stopped := r.cancel, r.stopped
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(r.cfg.Consumer.ShutdownTimeout):
+		log.Logger.Warnf("[KAFKA] Consumers did not stop within %s", r.cfg.Consumer.ShutdownTimeout)
+	}
+}
+
+// consume consume by given route pointer of consumerRoute.
+//
+// Messages are spread over the route's handlers by key, so messages of the
+// same order are handled one after the other. An offset is only committed
+// once it and every offset before it in the partition were handled or sent
+// to the dead letter topic.
+func (r *Runner) consume(ctx context.Context, route *consumerRoute) {
+	route.running.Store(true)
+	defer route.running.Store(false)
+
+	log.Logger.Infof("[KAFKA] Listening to topic %s with %d handler(s) as %s", route.Topic, route.Concurrency, route.Name)
+
+	handler := route.handler
+	for index := len(r.middlewares) - 1; index >= 0; index-- {
+		handler = r.middlewares[index](handler)
+	}
+
+	offsets := newOffsetTracker()
+	// handlers finish their message after a stop, only fetching is cancelled
+	handlerCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	queues := make([]chan kafka.Message, route.Concurrency)
+	for index := range queues {
+		queues[index] = make(chan kafka.Message, handlerQueueSize)
+
+		wg.Add(1)
+		go func(queue <-chan kafka.Message) {
+			defer wg.Done()
+			for message := range queue {
+				if ctx.Err() != nil {
+					// queued but not started before the stop, consumed again after a restart
+					continue
+				}
+
+				if !r.handle(ctx, handlerCtx, handler, Message{Message: message, GroupID: route.GroupID}) {
+					// stopped while retrying, consumed again after a restart
+					continue
+				}
+
+				commit, ok := offsets.done(message)
+				if !ok {
+					continue
+				}
+
+				err := route.reader.CommitMessages(handlerCtx, commit)
+				if err != nil {
+					log.Logger.Errorf("[KAFKA] Error Commit %s offset %d: %v", commit.Topic, commit.Offset, err)
+				}
+			}
+		}(queues[index])
+	}
+
+	r.fetch(ctx, route, offsets, queues)
+
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+
+	err := route.reader.Close()
+	if err != nil {
+		log.Logger.Errorf("[KAFKA] Error Close Reader of %s: %v", route.Topic, err)
+	}
+
+	log.Logger.Infof("[KAFKA] Stopped consuming topic %s", route.Topic)
+}
+
+// handle handle by given stopCtx cancelled by a stop, ctx of the handler, handler of HandlerFunc, and message of Message.
+//
+// A failed message is handled again after a backoff doubling up to the max
+// backoff, the other messages of its key wait meanwhile. Once it ran out of
+// attempts, or failed permanently, it is sent to the dead letter topic, which
+// is retried the same way until it succeeds.
+//
+// It returns true when the message was handled or sent to the dead letter topic.
+// Otherwise, false will be returned when stopped before either happened.
+func (r *Runner) handle(stopCtx context.Context, ctx context.Context, handler HandlerFunc, message Message) bool {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = handler(ctx, message)
+		if err == nil {
+			return true
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) || attempt >= r.cfg.Consumer.MaxAttempts {
+			break
+		}
+
+		if !r.wait(stopCtx, attempt) {
+			return false
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		if deadLetter(ctx, r.producer, message.Message, message.GroupID, err) == nil {
+			return true
+		}
+
+		if !r.wait(stopCtx, attempt) {
+			return false
+		}
+	}
+}
+
+// wait wait by given ctx, and attempts that failed.
+//
+// It returns true once the backoff after attempts passed.
+// Otherwise, false will be returned when ctx is done first.
+func (r *Runner) wait(ctx context.Context, attempts int) bool {
+	backoff := worker.Backoff{Initial: r.cfg.Consumer.InitialBackoff, Max: r.cfg.Consumer.MaxBackoff}
+
+	timer := time.NewTimer(backoff.Delay(attempts))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// fetch fetch by given route pointer of consumerRoute, offsets pointer of offsetTracker, and queues.
+//
+// It fetches until ctx is done, queuing every message for the handler of its
+// key. A failed fetch, e.g. while the brokers are down, is tried again after
+// the same backoff as a failed message.
+func (r *Runner) fetch(ctx context.Context, route *consumerRoute, offsets *offsetTracker, queues []chan kafka.Message) {
+	failures := 0
+	for {
+		message, err := route.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			failures++
+			log.Logger.Errorf("[KAFKA] Error Fetch Message of %s, attempt %d: %v", route.Topic, failures, err)
+
+			if !r.wait(ctx, failures) {
+				return
+			}
+
+			continue
+		}
+		failures = 0
+
+		offsets.track(message)
+
+		select {
+		case queues[queueIndex(message, len(queues))] <- message:
+		case <-ctx.Done():
+			// never handled, so neither committed
+			return
+		}
+	}
+}
+
+// queueIndex queue index by given message of kafka.Message, and size.
+//
+// It returns int of the queue for the message key, or its partition when it has no key.
+func queueIndex(message kafka.Message, size int) int {
+	if len(message.Key) == 0 {
+		return message.Partition % size
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write(message.Key)
+	return int(hash.Sum32() % uint32(size))
+}
@@ -0,0 +1,41 @@
+package consumer
+
+import (
+	// golang package
+	"context"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/tracing"
+	kafkaFC "orderfc/kafka"
+
+	// external package
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startMessageSpan start message span by given message pointer of kafka.Message, and group.
+//
+// The returned context continues the producer's trace and carries its
+// correlation id as request id.
+//
+// It returns context.Context, and trace.Span.
+func startMessageSpan(ctx context.Context, message *kafka.Message, group string) (context.Context, trace.Span) {
+	ctx, span := tracing.StartConsumerSpan(ctx, message, group)
+
+	requestID := kafkaFC.CorrelationID(*message)
+	if requestID != "" {
+		ctx = log.ContextWithRequestID(ctx, requestID)
+	}
+
+	return ctx, span
+}
+
+// endMessageSpan end message span by given span, and err.
+func endMessageSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
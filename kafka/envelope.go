@@ -0,0 +1,142 @@
+package kafka
+
+import (
+	// golang package
+	"context"
+	"encoding/json"
+	"orderfc/infrastructure/log"
+	"orderfc/models"
+	"strconv"
+	"time"
+
+	// external package
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+// EventSource is the source of every event published by this service.
+const EventSource = "orderfc"
+
+// headers mirroring the envelope, so events can be routed and filtered
+// without decoding the value
+const (
+	HeaderEventID       = "event_id"
+	HeaderEventType     = "event_type"
+	HeaderSchemaVersion = "schema_version"
+	HeaderOccurredAt    = "occurred_at"
+	HeaderSource        = "source"
+	HeaderCorrelationID = "correlation_id"
+)
+
+// NewEnvelopeMessage new envelope message by given topic, key, eventType, schemaVersion, and payload.
+//
+// The correlation id is the request id carried by ctx, and otherwise the
+// event id, so every chain of events can be followed.
+//
+// It returns kafka.Message of the enveloped payload, and nil error when successful.
+// Otherwise, empty kafka.Message, and error will be returned.
+func NewEnvelopeMessage(ctx context.Context, topic string, key string, eventType string, schemaVersion int, payload json.RawMessage) (kafka.Message, error) {
+	envelope := models.EventEnvelope{
+		EventID:       uuid.New().String(),
+		Type:          eventType,
+		SchemaVersion: schemaVersion,
+		OccurredAt:    time.Now().UTC(),
+		Source:        EventSource,
+		CorrelationID: log.RequestIDFromContext(ctx),
+		Payload:       payload,
+	}
+
+	if envelope.CorrelationID == "" {
+		envelope.CorrelationID = envelope.EventID
+	}
+
+	value, err := json.Marshal(envelope)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	return kafka.Message{
+		Topic: topic,
+		Key:   []byte(key),
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: HeaderEventID, Value: []byte(envelope.EventID)},
+			{Key: HeaderEventType, Value: []byte(envelope.Type)},
+			{Key: HeaderSchemaVersion, Value: []byte(strconv.Itoa(envelope.SchemaVersion))},
+			{Key: HeaderOccurredAt, Value: []byte(envelope.OccurredAt.Format(time.RFC3339Nano))},
+			{Key: HeaderSource, Value: []byte(envelope.Source)},
+			{Key: HeaderCorrelationID, Value: []byte(envelope.CorrelationID)},
+		},
+	}, nil
+}
+
+// DecodeMessage decode message by given message of kafka.Message, and payload.
+//
+// Both enveloped and bare messages are accepted while producers migrate. A
+// bare value is decoded as the payload itself, and its envelope is filled
+// from the message headers when present with a schema version of 0.
+//
+// It returns models.EventEnvelope, and nil error when successful.
+// Otherwise, empty models.EventEnvelope, and error will be returned.
+func DecodeMessage(message kafka.Message, payload interface{}) (models.EventEnvelope, error) {
+	var envelope models.EventEnvelope
+	err := json.Unmarshal(message.Value, &envelope)
+	if err == nil && envelope.SchemaVersion > 0 && len(envelope.Payload) > 0 {
+		err = json.Unmarshal(envelope.Payload, payload)
+		if err != nil {
+			return models.EventEnvelope{}, err
+		}
+
+		return envelope, nil
+	}
+
+	err = json.Unmarshal(message.Value, payload)
+	if err != nil {
+		return models.EventEnvelope{}, err
+	}
+
+	envelope = models.EventEnvelope{
+		Payload:       message.Value,
+		OccurredAt:    message.Time,
+		CorrelationID: CorrelationID(message),
+	}
+
+	for _, header := range message.Headers {
+		switch header.Key {
+		case HeaderEventID:
+			envelope.EventID = string(header.Value)
+		case HeaderEventType:
+			envelope.Type = string(header.Value)
+		case HeaderSource:
+			envelope.Source = string(header.Value)
+		case HeaderOccurredAt:
+			occurredAt, err := time.Parse(time.RFC3339Nano, string(header.Value))
+			if err == nil {
+				envelope.OccurredAt = occurredAt
+			}
+		}
+	}
+
+	return envelope, nil
+}
+
+// CorrelationID correlation id by given message of kafka.Message.
+//
+// The correlation id header is written with every envelope, the request id
+// header is read for messages published before it.
+//
+// It returns string of the correlation id.
+// Otherwise, empty string will be returned when the message has neither header.
+func CorrelationID(message kafka.Message) string {
+	var requestID string
+	for _, header := range message.Headers {
+		switch header.Key {
+		case HeaderCorrelationID:
+			return string(header.Value)
+		case HeaderRequestID:
+			requestID = string(header.Value)
+		}
+	}
+
+	return requestID
+}
@@ -5,26 +5,88 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"orderfc/config"
+	"orderfc/infrastructure/breaker"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/metrics"
+	"orderfc/infrastructure/tracing"
+	"orderfc/kafka/schema"
 	"orderfc/models"
+	"slices"
+	"strconv"
+	"time"
 
 	// external package
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// HeaderRequestID is still written next to the correlation id for consumers
+// that predate the envelope.
+const HeaderRequestID = "request_id"
+
+// headers added to a dead lettered message, next to its original headers
+const (
+	HeaderDeadLetterTopic     = "dlq_topic"
+	HeaderDeadLetterPartition = "dlq_partition"
+	HeaderDeadLetterOffset    = "dlq_offset"
+	HeaderDeadLetterGroup     = "dlq_group"
+	HeaderDeadLetterReason    = "dlq_reason"
+	HeaderDeadLetterTime      = "dlq_time"
 )
 
 type KafkaProducer struct {
-	writer *kafka.Writer
+	writer         *kafka.Writer
+	topics         config.KafkaTopicsConfig
+	breaker        *breaker.Breaker
+	publishTimeout time.Duration
+	schemas        *schema.Registry
 }
 
-// NewKafkaProducer new kafka producer by given slice of brokers, and topic.
+// NewKafkaProducer new kafka producer by given cfg of config.KafkaConfig, publishBreaker pointer of breaker.Breaker, and schemas pointer of schema.Registry.
 //
-// It returns pointer of KafkaProducer when successful.
-// Otherwise, nil pointer of KafkaProducer will be returned.
-func NewKafkaProducer(brokers []string) *KafkaProducer {
+// It returns pointer of KafkaProducer, and nil error when successful.
+// Otherwise, nil pointer of KafkaProducer, and error will be returned.
+func NewKafkaProducer(cfg config.KafkaConfig, publishBreaker *breaker.Breaker, schemas *schema.Registry) (*KafkaProducer, error) {
+	mechanism, err := saslMechanism(cfg.SASL)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := newTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
 	writer := &kafka.Writer{
-		Addr:     kafka.TCP(brokers...),
-		Balancer: &kafka.LeastBytes{},
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Balancer:     &kafka.LeastBytes{},
+		BatchSize:    cfg.BatchSize,
+		BatchTimeout: cfg.BatchTimeout,
+		RequiredAcks: requiredAcks(cfg.RequiredAcks),
+		Transport: &kafka.Transport{
+			DialTimeout: dialTimeout,
+			SASL:        mechanism,
+			TLS:         tlsConfig,
+		},
 	}
-	return &KafkaProducer{writer: writer}
+
+	return &KafkaProducer{
+		writer: writer,
+		topics: config.KafkaTopicsConfig{
+			OrderCreated:   Topic(cfg, cfg.Topics.OrderCreated),
+			StockUpdate:    Topic(cfg, cfg.Topics.StockUpdate),
+			StockRollback:  Topic(cfg, cfg.Topics.StockRollback),
+			PaymentSuccess: Topic(cfg, cfg.Topics.PaymentSuccess),
+			PaymentFailed:  Topic(cfg, cfg.Topics.PaymentFailed),
+			PaymentVoid:    Topic(cfg, cfg.Topics.PaymentVoid),
+			DeadLetter:     Topic(cfg, cfg.Topics.DeadLetter),
+		},
+		breaker:        publishBreaker,
+		publishTimeout: cfg.PublishTimeout,
+		schemas:        schemas,
+	}, nil
 }
 
 // PublishOrderCreated publish order created by given event.
@@ -32,56 +94,120 @@ func NewKafkaProducer(brokers []string) *KafkaProducer {
 // It returns nil error when successful.
 // Otherwise, error will be returned.
 func (p *KafkaProducer) PublishOrderCreated(ctx context.Context, event models.OrderCreatedEvent) error {
-	value, err := json.Marshal(event)
-	if err != nil {
-		return err
-	}
+	return p.publish(ctx, p.topics.OrderCreated, fmt.Sprintf("order-%d", event.OrderID), constant.EventOrderCreated, event)
+}
+
+// PublishProductStockUpdate publish product stock update by given ProductStockUpdateEvent.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (p *KafkaProducer) PublishProductStockUpdate(ctx context.Context, event models.ProductStockUpdateEvent) error {
+	return p.publish(ctx, p.topics.StockUpdate, fmt.Sprintf("order-%d", event.OrderID), constant.EventProductStockUpdate, event)
+}
+
+// PublishProductStockRollback publish product stock rollback by given ProductStockUpdateEvent.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (p *KafkaProducer) PublishProductStockRollback(ctx context.Context, event models.ProductStockUpdateEvent) error {
+	return p.publish(ctx, p.topics.StockRollback, fmt.Sprintf("order-%d", event.OrderID), constant.EventProductStockRollback, event)
+}
 
+// PublishPaymentVoid publish payment void by given PaymentVoidEvent.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (p *KafkaProducer) PublishPaymentVoid(ctx context.Context, event models.PaymentVoidEvent) error {
+	return p.publish(ctx, p.topics.PaymentVoid, fmt.Sprintf("order-%d", event.OrderID), constant.EventPaymentVoid, event)
+}
+
+// PublishDeadLetter publish dead letter by given message of kafka.Message, group, and reason.
+//
+// The message is published unchanged to the dead letter topic, with headers
+// naming where it was consumed from and why it was rejected, so it can be
+// inspected and replayed.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (p *KafkaProducer) PublishDeadLetter(ctx context.Context, message kafka.Message, group string, reason error) error {
 	msg := kafka.Message{
-		Key:   []byte(fmt.Sprintf("order-%d", event.OrderID)),
-		Value: value,
-		Topic: "order.created",
+		Key:   message.Key,
+		Value: message.Value,
+		Topic: p.topics.DeadLetter,
+		Headers: append(slices.Clone(message.Headers),
+			kafka.Header{Key: HeaderDeadLetterTopic, Value: []byte(message.Topic)},
+			kafka.Header{Key: HeaderDeadLetterPartition, Value: []byte(strconv.Itoa(message.Partition))},
+			kafka.Header{Key: HeaderDeadLetterOffset, Value: []byte(strconv.FormatInt(message.Offset, 10))},
+			kafka.Header{Key: HeaderDeadLetterGroup, Value: []byte(group)},
+			kafka.Header{Key: HeaderDeadLetterReason, Value: []byte(reason.Error())},
+			kafka.Header{Key: HeaderDeadLetterTime, Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+		),
 	}
 
-	return p.writer.WriteMessages(ctx, msg)
+	err := p.write(ctx, msg)
+	metrics.KafkaDeadLetterTotal.WithLabelValues(message.Topic, metrics.Result(err)).Inc()
+	return err
 }
 
-// PublishProductStockUpdate publish product stock update by given ProductStockUpdateEvent.
+// publish publish by given topic, key, eventType, and event.
+//
+// The event is checked against the latest schema of its type and published
+// with that schema version, an invalid event is never written.
 //
 // It returns nil error when successful.
 // Otherwise, error will be returned.
-func (p *KafkaProducer) PublishProductStockUpdate(ctx context.Context, event models.ProductStockUpdateEvent) error {
-	value, err := json.Marshal(event)
+func (p *KafkaProducer) publish(ctx context.Context, topic string, key string, eventType string, event interface{}) error {
+	payload, err := json.Marshal(event)
 	if err != nil {
 		return err
 	}
 
-	msg := kafka.Message{
-		Key:   []byte(fmt.Sprintf("order-%d", event.OrderID)),
-		Value: value,
-		Topic: "stock.update",
+	version := p.schemas.Latest(eventType)
+	err = p.schemas.Validate(eventType, version, payload)
+	if err != nil {
+		metrics.KafkaSchemaViolationsTotal.WithLabelValues(eventType, metrics.DirectionPublish).Inc()
+		return fmt.Errorf("invalid %s event: %w", eventType, err)
 	}
 
-	return p.writer.WriteMessages(ctx, msg)
+	msg, err := NewEnvelopeMessage(ctx, topic, key, eventType, version, payload)
+	if err != nil {
+		return err
+	}
+
+	return p.write(ctx, msg)
 }
 
-// PublishProductStockRollback publish product stock rollback by given ProductStockUpdateEvent.
+// write write by given msg of kafka.Message.
+//
+// The write is bounded by the publish timeout and fails fast while the
+// breaker is open, since callers publish without a deadline of their own.
 //
 // It returns nil error when successful.
 // Otherwise, error will be returned.
-func (p *KafkaProducer) PublishProductStockRollback(ctx context.Context, event models.ProductStockUpdateEvent) error {
-	value, err := json.Marshal(event)
-	if err != nil {
-		return err
+func (p *KafkaProducer) write(ctx context.Context, msg kafka.Message) error {
+	ctx, span := tracing.StartProducerSpan(ctx, &msg)
+	defer span.End()
+
+	if requestID := log.RequestIDFromContext(ctx); requestID != "" {
+		tracing.KafkaHeaderCarrier{Headers: &msg.Headers}.Set(HeaderRequestID, requestID)
 	}
 
-	msg := kafka.Message{
-		Key:   []byte(fmt.Sprintf("order-%d", event.OrderID)),
-		Value: value,
-		Topic: "stock.rollback",
+	if p.publishTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.publishTimeout)
+		defer cancel()
+	}
+
+	err := p.breaker.Execute(func() error {
+		return p.writer.WriteMessages(ctx, msg)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 	}
 
-	return p.writer.WriteMessages(ctx, msg)
+	metrics.KafkaPublishTotal.WithLabelValues(msg.Topic, metrics.Result(err)).Inc()
+	return err
 }
 
 // Close close.
@@ -0,0 +1,127 @@
+package schema
+
+import (
+	// golang package
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Compatible compatible by given previous, and next pointer of Schema.
+//
+// next is backward compatible when every event published with previous is
+// also valid against next: it may add optional properties and relax limits,
+// but not add required properties, change types, drop enum values or
+// tighten limits.
+//
+// It returns slice of string of every incompatibility, empty when next is compatible.
+func Compatible(previous *Schema, next *Schema) []string {
+	var problems []string
+	compatible("$", previous, next, &problems)
+	return problems
+}
+
+// compatible compatible by given path, previous, next, and problems pointer.
+//
+// Incompatibilities of next and its children are appended to problems.
+func compatible(path string, previous *Schema, next *Schema, problems *[]string) {
+	report := func(message string) {
+		*problems = append(*problems, path+": "+message)
+	}
+
+	if next.Type != "" && next.Type != previous.Type && !(next.Type == "number" && previous.Type == "integer") {
+		report("type changed from " + typeName(previous.Type) + " to " + next.Type)
+		return
+	}
+
+	if next.Format != "" && next.Format != previous.Format {
+		report("format " + next.Format + " added")
+	}
+
+	if len(next.Enum) > 0 {
+		if len(previous.Enum) == 0 {
+			report("enum added")
+		}
+
+		for _, value := range previous.Enum {
+			if !slices.Contains(next.Enum, value) {
+				report("enum value " + fmt.Sprint(value) + " removed")
+			}
+		}
+	}
+
+	for _, name := range next.Required {
+		if !slices.Contains(previous.Required, name) {
+			*problems = append(*problems, path+"."+name+": became required")
+		}
+	}
+
+	if next.AdditionalProperties != nil && !*next.AdditionalProperties &&
+		(previous.AdditionalProperties == nil || *previous.AdditionalProperties) {
+		report("additional properties no longer allowed")
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(next.Properties)) {
+		previousProperty, ok := previous.Properties[name]
+		if !ok {
+			// producers only send the properties of their schema version
+			continue
+		}
+
+		compatible(path+"."+name, previousProperty, next.Properties[name], problems)
+	}
+
+	if next.Items != nil {
+		previousItems := previous.Items
+		if previousItems == nil {
+			previousItems = &Schema{}
+		}
+
+		compatible(path+"[]", previousItems, next.Items, problems)
+	}
+
+	checkLimit(report, "minimum", previous.Minimum, next.Minimum, false)
+	checkLimit(report, "exclusiveMinimum", previous.ExclusiveMinimum, next.ExclusiveMinimum, false)
+	checkLimit(report, "maximum", previous.Maximum, next.Maximum, true)
+	checkLimit(report, "minLength", intLimit(previous.MinLength), intLimit(next.MinLength), false)
+	checkLimit(report, "maxLength", intLimit(previous.MaxLength), intLimit(next.MaxLength), true)
+	checkLimit(report, "minItems", intLimit(previous.MinItems), intLimit(next.MinItems), false)
+	checkLimit(report, "maxItems", intLimit(previous.MaxItems), intLimit(next.MaxItems), true)
+}
+
+// checkLimit check limit by given report, name, previous, next, and upper.
+//
+// A lower limit may only decrease and an upper limit may only increase.
+func checkLimit(report func(string), name string, previous *float64, next *float64, upper bool) {
+	switch {
+	case next == nil:
+		return
+	case previous == nil:
+		report(name + " added")
+	case upper && *next < *previous, !upper && *next > *previous:
+		report(name + " tightened")
+	}
+}
+
+// intLimit int limit by given limit.
+//
+// It returns pointer of float64 of limit, nil when limit is nil.
+func intLimit(limit *int) *float64 {
+	if limit == nil {
+		return nil
+	}
+
+	value := float64(*limit)
+	return &value
+}
+
+// typeName type name by given name.
+//
+// It returns string of name, or any when it is empty.
+func typeName(name string) string {
+	if name == "" {
+		return "any"
+	}
+
+	return name
+}
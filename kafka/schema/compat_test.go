@@ -0,0 +1,128 @@
+package schema
+
+import (
+	// golang package
+	"slices"
+	"testing"
+)
+
+func TestCompatible(t *testing.T) {
+	const previous = `{
+		"type": "object",
+		"required": ["order_id"],
+		"properties": {
+			"order_id": {"type": "integer", "minimum": 1},
+			"status": {"type": "string", "enum": ["created", "completed"], "maxLength": 20},
+			"products": {"type": "array", "minItems": 1, "items": {"type": "object", "properties": {"id": {"type": "integer"}}}}
+		}
+	}`
+
+	tests := []struct {
+		name         string
+		next         string
+		wantProblems []string
+	}{
+		{
+			name: "unchanged",
+			next: previous,
+		},
+		{
+			name: "optional property added and limits relaxed",
+			next: `{
+				"type": "object",
+				"required": ["order_id"],
+				"properties": {
+					"order_id": {"type": "number", "minimum": 0},
+					"status": {"type": "string", "enum": ["created", "completed", "cancelled"], "maxLength": 40},
+					"products": {"type": "array", "items": {"type": "object", "properties": {"id": {"type": "integer"}}}},
+					"note": {"type": "string", "minLength": 1}
+				}
+			}`,
+		},
+		{
+			name: "property became required",
+			next: `{
+				"type": "object",
+				"required": ["order_id", "status"],
+				"properties": {
+					"order_id": {"type": "integer", "minimum": 1},
+					"status": {"type": "string", "enum": ["created", "completed"], "maxLength": 20},
+					"products": {"type": "array", "minItems": 1, "items": {"type": "object", "properties": {"id": {"type": "integer"}}}}
+				}
+			}`,
+			wantProblems: []string{"$.status: became required"},
+		},
+		{
+			name: "type changed and enum value removed",
+			next: `{
+				"type": "object",
+				"required": ["order_id"],
+				"properties": {
+					"order_id": {"type": "string"},
+					"status": {"type": "string", "enum": ["created"], "maxLength": 20},
+					"products": {"type": "array", "minItems": 1, "items": {"type": "object", "properties": {"id": {"type": "string"}}}}
+				}
+			}`,
+			wantProblems: []string{
+				"$.order_id: type changed from integer to string",
+				"$.products[].id: type changed from integer to string",
+				"$.status: enum value completed removed",
+			},
+		},
+		{
+			name: "limits tightened or added",
+			next: `{
+				"type": "object",
+				"required": ["order_id"],
+				"properties": {
+					"order_id": {"type": "integer", "minimum": 2, "maximum": 100},
+					"status": {"type": "string", "enum": ["created", "completed"], "maxLength": 10, "format": "date-time"},
+					"products": {"type": "array", "minItems": 2, "items": {"type": "object", "properties": {"id": {"type": "integer"}}}}
+				}
+			}`,
+			wantProblems: []string{
+				"$.order_id: minimum tightened",
+				"$.order_id: maximum added",
+				"$.products: minItems tightened",
+				"$.status: format date-time added",
+				"$.status: maxLength tightened",
+			},
+		},
+		{
+			name: "additional properties disallowed",
+			next: `{
+				"type": "object",
+				"required": ["order_id"],
+				"additionalProperties": false,
+				"properties": {
+					"order_id": {"type": "integer", "minimum": 1},
+					"status": {"type": "string", "enum": ["created", "completed"], "maxLength": 20},
+					"products": {"type": "array", "minItems": 1, "items": {"type": "object", "properties": {"id": {"type": "integer"}}}}
+				}
+			}`,
+			wantProblems: []string{"$: additional properties no longer allowed"},
+		},
+		{
+			name: "enum added",
+			next: `{
+				"type": "object",
+				"required": ["order_id"],
+				"properties": {
+					"order_id": {"type": "integer", "minimum": 1, "enum": [1, 2]},
+					"status": {"type": "string", "enum": ["created", "completed"], "maxLength": 20},
+					"products": {"type": "array", "minItems": 1, "items": {"type": "object", "properties": {"id": {"type": "integer"}}}}
+				}
+			}`,
+			wantProblems: []string{"$.order_id: enum added"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problems := Compatible(mustParse(t, previous), mustParse(t, test.next))
+			if !slices.Equal(problems, test.wantProblems) {
+				t.Errorf("Compatible() = %q, want %q", problems, test.wantProblems)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package schema
+
+import (
+	// golang package
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// schemas holds one directory per event type with a v<N>.json file per
+// schema version, numbered from 1 without gaps.
+//
+//go:embed schemas
+var schemas embed.FS
+
+var ErrUnknownEventType = errors.New("no schema registered for event type")
+
+type Registry struct {
+	// versions of every event type, index 0 is version 1
+	versions map[string][]*Schema
+}
+
+// NewRegistry new registry.
+//
+// It returns pointer of Registry of the schemas built into the service, and nil error when successful.
+// Otherwise, nil pointer of Registry, and error will be returned.
+func NewRegistry() (*Registry, error) {
+	root, err := fs.Sub(schemas, "schemas")
+	if err != nil {
+		return nil, err
+	}
+
+	return Load(root)
+}
+
+// Load load by given fsys of fs.FS.
+//
+// It returns pointer of Registry, and nil error when successful.
+// Otherwise, nil pointer of Registry, and error will be returned.
+func Load(fsys fs.FS) (*Registry, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &Registry{versions: map[string][]*Schema{}}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		eventType := entry.Name()
+		files, err := fs.ReadDir(fsys, eventType)
+		if err != nil {
+			return nil, err
+		}
+
+		versions := make([]*Schema, len(files))
+		for _, file := range files {
+			version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(file.Name(), "v"), ".json"))
+			if err != nil || version < 1 || version > len(files) || !strings.HasSuffix(file.Name(), ".json") {
+				return nil, fmt.Errorf("schema %s/%s: expected files v1.json to v%d.json", eventType, file.Name(), len(files))
+			}
+
+			data, err := fs.ReadFile(fsys, path.Join(eventType, file.Name()))
+			if err != nil {
+				return nil, err
+			}
+
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.DisallowUnknownFields()
+
+			var schema Schema
+			err = decoder.Decode(&schema)
+			if err != nil {
+				return nil, fmt.Errorf("schema %s/%s: %w", eventType, file.Name(), err)
+			}
+
+			versions[version-1] = &schema
+		}
+
+		if len(versions) > 0 {
+			registry.versions[eventType] = versions
+		}
+	}
+
+	return registry, nil
+}
+
+// EventTypes event types.
+//
+// It returns sorted slice of string of every event type with a schema.
+func (r *Registry) EventTypes() []string {
+	eventTypes := make([]string, 0, len(r.versions))
+	for eventType := range r.versions {
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	slices.Sort(eventTypes)
+	return eventTypes
+}
+
+// Latest latest by given eventType.
+//
+// It returns int of the latest schema version of eventType, 0 when it has no schema.
+func (r *Registry) Latest(eventType string) int {
+	return len(r.versions[eventType])
+}
+
+// Schema schema by given eventType, and version.
+//
+// It returns pointer of Schema, and true when the version is registered.
+// Otherwise, nil pointer of Schema, and false will be returned.
+func (r *Registry) Schema(eventType string, version int) (*Schema, bool) {
+	versions := r.versions[eventType]
+	if version < 1 || version > len(versions) {
+		return nil, false
+	}
+
+	return versions[version-1], true
+}
+
+// Validate validate by given eventType, version, and payload of the event.
+//
+// Payloads published before the envelope carry version 0 and are checked
+// against version 1. A version newer than the registry knows is checked
+// against the latest one, which is the shape this service decodes.
+//
+// It returns nil error when payload matches the schema.
+// Otherwise, error will be returned.
+func (r *Registry) Validate(eventType string, version int, payload []byte) error {
+	latest := r.Latest(eventType)
+	if latest == 0 {
+		return fmt.Errorf("%w %q", ErrUnknownEventType, eventType)
+	}
+
+	schema, _ := r.Schema(eventType, min(max(version, 1), latest))
+	return schema.Validate(payload)
+}
+
+// CheckCompatibility check compatibility.
+//
+// Every schema version has to be backward compatible with the version before
+// it, so consumers on the new version still accept events published with
+// the old one.
+//
+// It returns nil error when every version is compatible.
+// Otherwise, error listing every incompatibility will be returned.
+func (r *Registry) CheckCompatibility() error {
+	var problems []string
+	for _, eventType := range r.EventTypes() {
+		versions := r.versions[eventType]
+		for index := 1; index < len(versions); index++ {
+			for _, problem := range Compatible(versions[index-1], versions[index]) {
+				problems = append(problems, fmt.Sprintf("%s v%d: %s", eventType, index+1, problem))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package schema
+
+import (
+	// golang package
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of JSON Schema used by the event schemas. Loading a
+// schema with any other keyword fails, so a constraint is never silently
+// ignored.
+type Schema struct {
+	SchemaURI            string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+type ValidationError struct {
+	Violations []string
+}
+
+// Error error.
+//
+// It returns string of every violation.
+func (e *ValidationError) Error() string {
+	return "schema violation: " + strings.Join(e.Violations, "; ")
+}
+
+// Validate validate by given data of a JSON document.
+//
+// It returns nil error when data matches the schema.
+// Otherwise, error of the JSON syntax, or pointer of ValidationError listing every violation will be returned.
+func (s *Schema) Validate(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return err
+	}
+
+	var violations []string
+	s.validate("$", value, &violations)
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	return nil
+}
+
+// validate validate by given path, value, and violations pointer.
+//
+// Violations of value and its children are appended to violations.
+func (s *Schema) validate(path string, value interface{}, violations *[]string) {
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if s.Type != "" && !matchesType(s.Type, value) {
+		report("expected %s, got %s", s.Type, typeOf(value))
+		return
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(allowed interface{}) bool { return equal(allowed, value) }) {
+		report("must be one of %v", s.Enum)
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				*violations = append(*violations, path+"."+name+": is required")
+			}
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(value)) {
+			property := value[name]
+			schema, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*violations = append(*violations, path+"."+name+": is not allowed")
+				}
+
+				continue
+			}
+
+			schema.validate(path+"."+name, property, violations)
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(value) < *s.MinItems {
+			report("must have at least %d item(s)", *s.MinItems)
+		}
+
+		if s.MaxItems != nil && len(value) > *s.MaxItems {
+			report("must have at most %d item(s)", *s.MaxItems)
+		}
+
+		if s.Items != nil {
+			for index, item := range value {
+				s.Items.validate(path+"["+strconv.Itoa(index)+"]", item, violations)
+			}
+		}
+	case string:
+		length := len([]rune(value))
+		if s.MinLength != nil && length < *s.MinLength {
+			report("must be at least %d character(s)", *s.MinLength)
+		}
+
+		if s.MaxLength != nil && length > *s.MaxLength {
+			report("must be at most %d character(s)", *s.MaxLength)
+		}
+
+		if s.Format == "date-time" {
+			_, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				report("must be an RFC 3339 date-time")
+			}
+		}
+	case json.Number:
+		number, _ := value.Float64()
+		if s.Minimum != nil && number < *s.Minimum {
+			report("must be at least %v", *s.Minimum)
+		}
+
+		if s.ExclusiveMinimum != nil && number <= *s.ExclusiveMinimum {
+			report("must be greater than %v", *s.ExclusiveMinimum)
+		}
+
+		if s.Maximum != nil && number > *s.Maximum {
+			report("must be at most %v", *s.Maximum)
+		}
+	}
+}
+
+// matchesType matches type by given name of the JSON Schema type, and value.
+//
+// It returns true when value is of the type.
+// Otherwise, false will be returned.
+func matchesType(name string, value interface{}) bool {
+	if name == "integer" {
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+
+		float, err := number.Float64()
+		return err == nil && float == math.Trunc(float)
+	}
+
+	return typeOf(value) == name
+}
+
+// typeOf type of by given value decoded with json.Number.
+//
+// It returns string of the JSON Schema type of value.
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// equal equal by given allowed value of an enum, and value.
+//
+// It returns true when both are the same JSON value.
+// Otherwise, false will be returned.
+func equal(allowed interface{}, value interface{}) bool {
+	if number, ok := value.(json.Number); ok {
+		float, _ := number.Float64()
+		allowedFloat, ok := allowed.(float64)
+		return ok && allowedFloat == float
+	}
+
+	return allowed == value
+}
@@ -0,0 +1,179 @@
+package schema
+
+import (
+	// golang package
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// orderSchema covers every keyword the event schemas use.
+const orderSchema = `{
+	"type": "object",
+	"required": ["order_id", "status", "products"],
+	"additionalProperties": false,
+	"properties": {
+		"order_id": {"type": "integer", "minimum": 1},
+		"status": {"type": "string", "enum": ["created", "completed"]},
+		"amount": {"type": "number", "exclusiveMinimum": 0, "maximum": 1000},
+		"note": {"type": "string", "minLength": 2, "maxLength": 5},
+		"event_time": {"type": "string", "format": "date-time"},
+		"products": {
+			"type": "array",
+			"minItems": 1,
+			"maxItems": 2,
+			"items": {
+				"type": "object",
+				"required": ["id"],
+				"properties": {"id": {"type": "integer"}}
+			}
+		}
+	}
+}`
+
+// mustParse must parse by given t pointer of testing.T, and data of a JSON schema.
+//
+// It returns pointer of Schema.
+func mustParse(t *testing.T, data string) *Schema {
+	t.Helper()
+
+	var schema Schema
+	err := json.Unmarshal([]byte(data), &schema)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() got error %v", err)
+	}
+
+	return &schema
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema := mustParse(t, orderSchema)
+
+	tests := []struct {
+		name           string
+		data           string
+		wantViolations []string
+	}{
+		{
+			name: "valid",
+			data: `{"order_id": 1, "status": "created", "amount": 10.5, "note": "gift", "event_time": "2024-05-01T10:00:00.123Z", "products": [{"id": 3}]}`,
+		},
+		{
+			name:           "missing required properties",
+			data:           `{"order_id": 1}`,
+			wantViolations: []string{"$.status: is required", "$.products: is required"},
+		},
+		{
+			name:           "wrong type",
+			data:           `{"order_id": "1", "status": "created", "products": {}}`,
+			wantViolations: []string{"$.order_id: expected integer, got string", "$.products: expected array, got object"},
+		},
+		{
+			name:           "fraction is not an integer",
+			data:           `{"order_id": 1.5, "status": "created", "products": [{"id": 3}]}`,
+			wantViolations: []string{"$.order_id: expected integer, got number"},
+		},
+		{
+			name:           "null is not a string",
+			data:           `{"order_id": 1, "status": null, "products": [{"id": 3}]}`,
+			wantViolations: []string{"$.status: expected string, got null"},
+		},
+		{
+			name:           "value outside the enum",
+			data:           `{"order_id": 1, "status": "shipped", "products": [{"id": 3}]}`,
+			wantViolations: []string{"$.status: must be one of [created completed]"},
+		},
+		{
+			name:           "below minimum",
+			data:           `{"order_id": 0, "status": "created", "products": [{"id": 3}]}`,
+			wantViolations: []string{"$.order_id: must be at least 1"},
+		},
+		{
+			name:           "at exclusive minimum",
+			data:           `{"order_id": 1, "status": "created", "amount": 0, "products": [{"id": 3}]}`,
+			wantViolations: []string{"$.amount: must be greater than 0"},
+		},
+		{
+			name:           "above maximum",
+			data:           `{"order_id": 1, "status": "created", "amount": 1000.01, "products": [{"id": 3}]}`,
+			wantViolations: []string{"$.amount: must be at most 1000"},
+		},
+		{
+			name: "string length counts characters",
+			data: `{"order_id": 1, "status": "created", "note": "ééééé", "products": [{"id": 3}]}`,
+		},
+		{
+			name:           "string too short and not a date-time",
+			data:           `{"order_id": 1, "status": "created", "note": "a", "products": [{"id": 3}], "event_time": "yesterday"}`,
+			wantViolations: []string{"$.event_time: must be an RFC 3339 date-time", "$.note: must be at least 2 character(s)"},
+		},
+		{
+			name:           "too few items",
+			data:           `{"order_id": 1, "status": "created", "products": []}`,
+			wantViolations: []string{"$.products: must have at least 1 item(s)"},
+		},
+		{
+			name:           "too many items and invalid item",
+			data:           `{"order_id": 1, "status": "created", "products": [{"id": 1}, {"id": 2}, {}]}`,
+			wantViolations: []string{"$.products: must have at most 2 item(s)", "$.products[2].id: is required"},
+		},
+		{
+			name:           "additional property not allowed",
+			data:           `{"order_id": 1, "status": "created", "products": [{"id": 3}], "coupon": "X"}`,
+			wantViolations: []string{"$.coupon: is not allowed"},
+		},
+		{
+			name: "additional property allowed when not disabled",
+			data: `{"order_id": 1, "status": "created", "products": [{"id": 3, "qty": 2}]}`,
+		},
+		{
+			name:           "wrong root type",
+			data:           `[]`,
+			wantViolations: []string{"$: expected object, got array"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := schema.Validate([]byte(test.data))
+			if test.wantViolations == nil {
+				if err != nil {
+					t.Fatalf("Validate() got error %v", err)
+				}
+
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate() got error %v, want *ValidationError", err)
+			}
+
+			if !slices.Equal(validationErr.Violations, test.wantViolations) {
+				t.Errorf("violations = %q, want %q", validationErr.Violations, test.wantViolations)
+			}
+		})
+	}
+}
+
+func TestSchemaValidateRejectsInvalidJSON(t *testing.T) {
+	err := mustParse(t, orderSchema).Validate([]byte(`{"order_id": 1,`))
+
+	var validationErr *ValidationError
+	if err == nil || errors.As(err, &validationErr) {
+		t.Fatalf("Validate() got error %v, want a JSON syntax error", err)
+	}
+}
+
+func TestRegistrySchemasAreCompatible(t *testing.T) {
+	registry, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry() got error %v", err)
+	}
+
+	err = registry.CheckCompatibility()
+	if err != nil {
+		t.Fatalf("CheckCompatibility() got error %v", err)
+	}
+}
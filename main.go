@@ -3,59 +3,213 @@ package main
 import (
 	// golang package
 	"context"
+	"errors"
+	"net"
+	"net/http"
 	"orderfc/cmd/order/handler"
 	"orderfc/cmd/order/repository"
 	"orderfc/cmd/order/resource"
 	"orderfc/cmd/order/service"
 	"orderfc/cmd/order/usecase"
 	"orderfc/config"
+	"orderfc/infrastructure/breaker"
+	"orderfc/infrastructure/health"
 	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/metrics"
+	"orderfc/infrastructure/tracing"
+	"orderfc/infrastructure/validation"
 	"orderfc/kafka"
 	"orderfc/kafka/consumer"
+	"orderfc/kafka/schema"
+	"orderfc/middleware"
 	"orderfc/routes"
+	"orderfc/saga"
+	"orderfc/webhook"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	// external package
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/pflag"
 )
 
+const defaultShutdownTimeout = 15 * time.Second
+
 // main main.
+//
+// It serves until SIGINT or SIGTERM, then stops accepting requests and
+// messages, lets the in-flight ones finish and flushes the producer and the
+// tracer before exiting.
 func main() {
-	cfg := config.LoadConfig()
-	redis := resource.InitRedis(&cfg)
-	db := resource.InitDB(&cfg)
+	configFile := pflag.String("config", config.DefaultConfigFile, "path to the config file")
+	pflag.Parse()
+
+	cfg := config.LoadConfig(*configFile)
+	log.SetupLogger(cfg.Log)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := validation.Register()
+	if err != nil {
+		log.Logger.Fatalf("failed to register validators: %v", err)
+	}
+
+	dbBreaker := breaker.New("postgres", cfg.Breaker.Database).WithFailureFunc(breaker.IsDatabaseFailure)
+	redisBreaker := breaker.New("redis", cfg.Breaker.Redis).WithFailureFunc(breaker.IsRedisFailure)
+	kafkaBreaker := breaker.New("kafka", cfg.Breaker.Kafka)
+
+	redis := resource.InitRedis(&cfg, redisBreaker)
+	db := resource.InitDB(&cfg, dbBreaker)
+	replicas := resource.InitReplicas(&cfg)
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Logger.Fatalf("failed to init tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
 
-	log.SetupLogger()
-	kafkaProducer := kafka.NewKafkaProducer([]string{"localhost:9093"})
+	eventSchemas, err := schema.NewRegistry()
+	if err != nil {
+		log.Logger.Fatalf("failed to load event schemas: %v", err)
+	}
+
+	kafkaProducer, err := kafka.NewKafkaProducer(cfg.Kafka, kafkaBreaker, eventSchemas)
+	if err != nil {
+		log.Logger.Fatalf("failed to init kafka producer: %v", err)
+	}
 	defer kafkaProducer.Close()
 
-	orderRepository := repository.NewOrderRepository(db, redis)
+	// workers publish until they stopped, so they are waited for before the producer is closed
+	var workers sync.WaitGroup
+	defer workers.Wait()
+
+	orderRepository := repository.NewOrderRepository(db, replicas, redis, cfg.Database.ReadYourWritesWindow, cfg.Cache, cfg.OrderEvents, cfg.Webhook)
 	orderService := service.NewOrderService(*orderRepository)
-	orderUsecase := usecase.NewOrderUsecase(*orderService, *kafkaProducer)
-	orderHandler := handler.NewOrderHandler(*orderUsecase)
+	sagaOrchestrator := saga.NewOrchestrator(cfg.Saga, orderService, kafkaProducer)
+	orderUsecase := usecase.NewOrderUsecase(*orderService, *kafkaProducer, sagaOrchestrator)
+	orderHandler := handler.NewOrderHandler(*orderUsecase, cfg.OrderEvents)
 
-	port := cfg.App.Port
-	router := gin.Default()
-	routes.SetupRoutes(router, *orderHandler, cfg.Secret.JWTSecret)
-	router.Run(":" + port)
+	jwtValidator, err := middleware.NewJWTValidator(ctx, cfg.Auth, cfg.Secret.JWTSecret)
+	if err != nil {
+		log.Logger.Fatalf("failed to init jwt validator: %v", err)
+	}
+
+	serviceAuth := middleware.NewServiceAuthenticator(cfg.ServiceAuth, redis)
+	rateLimiter := middleware.NewRateLimiter(cfg.RateLimit, redis)
+	go rateLimiter.Start(ctx)
 
 	// kafka consumer
-	kafkaPaymentSuccessConsumer := consumer.NewPaymentSuccessConsumer(
-		[]string{"localhost:9093"},
-		"payment.success",
-		*orderService,
-		*kafkaProducer,
-	)
+	consumerRunner, err := consumer.NewRunner(cfg.Kafka, *kafkaProducer, eventSchemas)
+	if err != nil {
+		log.Logger.Fatalf("failed to init kafka consumers: %v", err)
+	}
+
+	routes.SetupConsumers(consumerRunner, cfg.Kafka, *consumer.NewPaymentHandler(*orderService, *kafkaProducer, sagaOrchestrator))
+
+	err = metrics.RegisterKafkaReaders(consumerRunner.Readers()...)
+	if err != nil {
+		log.Logger.Fatalf("failed to register kafka reader metrics: %v", err)
+	}
+
+	go consumerRunner.Start(ctx)
+	defer consumerRunner.Stop()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		sagaOrchestrator.Start(ctx)
+	}()
+
+	webhookWorker := webhook.NewDeliveryWorker(cfg.Webhook, *orderService)
+	if cfg.Webhook.Enabled {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			webhookWorker.Start(ctx)
+		}()
+	}
+
+	kafkaDialer, err := kafka.NewDialer(cfg.Kafka)
+	if err != nil {
+		log.Logger.Fatalf("failed to init kafka dialer: %v", err)
+	}
+
+	healthChecker := health.NewChecker(cfg.App.HealthCheckTimeout)
+	healthChecker.Register("postgres", health.DatabaseCheck(db))
+	healthChecker.Register("redis", health.RedisCheck(redis))
+	healthChecker.Register("kafka", health.KafkaCheck(kafkaDialer, cfg.Kafka.Brokers))
+	healthChecker.Register("circuit_breaker_postgres", health.BreakerCheck(dbBreaker))
+	healthChecker.Register("circuit_breaker_redis", health.BreakerCheck(redisBreaker))
+	healthChecker.Register("circuit_breaker_kafka", health.BreakerCheck(kafkaBreaker))
+	for _, name := range consumerRunner.Names() {
+		healthChecker.Register("consumer_"+name, health.RunningCheck(func() bool { return consumerRunner.Running(name) }))
+	}
+	healthChecker.Register("saga_worker", health.RunningCheck(sagaOrchestrator.Running))
+	if cfg.Webhook.Enabled {
+		healthChecker.Register("webhook_worker", health.RunningCheck(webhookWorker.Running))
+	}
+
+	if cfg.GRPC.Enabled {
+		grpcListener, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
+		if err != nil {
+			log.Logger.Fatalf("failed to listen on gRPC port: %v", err)
+		}
+
+		grpcServer := routes.SetupGRPCServer(cfg.GRPC, handler.NewOrderGRPCHandler(*orderUsecase), jwtValidator, serviceAuth, rateLimiter, healthChecker)
+		defer grpcServer.GracefulStop()
+
+		go func() {
+			log.Logger.Printf("gRPC server running on port: %s", cfg.GRPC.Port)
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				log.Logger.Errorf("gRPC server stopped: %v", err)
+			}
+		}()
+	}
+
+	port := cfg.App.Port
+	// access logs are written by middleware.RequestLogger
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	// X-Forwarded-For is ignored unless it was set by a trusted proxy
+	err = router.SetTrustedProxies(cfg.App.TrustedProxies)
+	if err != nil {
+		log.Logger.Fatalf("failed to set trusted proxies: %v", err)
+	}
+	routes.SetupRoutes(router, *orderHandler, jwtValidator, serviceAuth, rateLimiter, healthChecker)
+
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: router,
+	}
+	server.RegisterOnShutdown(orderHandler.CloseEventStreams)
+
+	go func() {
+		log.Logger.Printf("Server running on port: %s", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Logger.Errorf("HTTP server stopped: %v", err)
+			stop()
+		}
+	}()
 
-	kafkaPaymentSuccessConsumer.StartPaymentSuccessConsumer(context.Background())
+	<-ctx.Done()
+	log.Logger.Printf("Shutting down")
 
-	kafkaPaymentFailedConsumer := consumer.NewPaymentFailedConsumer(
-		[]string{"localhost:9093"},
-		"payment.failed",
-		*orderService,
-		*kafkaProducer,
-	)
+	shutdownTimeout := cfg.App.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
 
-	kafkaPaymentFailedConsumer.Start(context.Background())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
 
-	log.Logger.Printf("Server running on port: %s", port)
+	err = server.Shutdown(shutdownCtx)
+	if err != nil {
+		log.Logger.Warnf("HTTP server did not stop within %s: %v", shutdownTimeout, err)
+		server.Close()
+	}
 }
@@ -2,60 +2,84 @@ package middleware
 
 import (
 	// golang package
-	"net/http"
+	"errors"
+	"orderfc/infrastructure/apperror"
+	"orderfc/infrastructure/log"
 	"strings"
 
 	// external package
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
 )
 
-// AuthMiddleware auth middleware by given secret.
+var (
+	ErrMissingAuth          = apperror.Unauthorized("missing_auth", "missing required auth")
+	errInvalidTokenResponse = apperror.Unauthorized("invalid_token", "invalid token")
+)
+
+// AuthMiddleware auth middleware by given validator pointer of JWTValidator, and serviceAuth pointer of ServiceAuthenticator.
+//
+// Requests carrying an X-Api-Key header are authenticated as internal services
+// with a signed request, every other request needs a user JWT.
 //
 // It returns gin.HandlerFunc when successful.
 // Otherwise, empty gin.HandlerFunc will be returned.
-func AuthMiddleware(secret string) gin.HandlerFunc {
+func AuthMiddleware(validator *JWTValidator, serviceAuth *ServiceAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if serviceAuth != nil && c.GetHeader(HeaderAPIKey) != "" {
+			authenticateService(c, serviceAuth)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "missing required auth",
-			})
-			c.Abort()
+			AbortWithError(c, ErrMissingAuth)
 			return
 		}
 
-		tokenString := strings.Split(authHeader, " ")
-		if len(tokenString) != 2 {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid token",
-			})
-			c.Abort()
+		tokenString := strings.Fields(authHeader)
+		if len(tokenString) != 2 || !strings.EqualFold(tokenString[0], "Bearer") {
+			AbortWithError(c, errInvalidTokenResponse)
 			return
 		}
 
-		token, err := jwt.Parse(tokenString[1], func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid Token",
-			})
+		principal, err := validator.Validate(c.Request.Context(), tokenString[1])
+		if err != nil {
+			log.WithContext(c.Request.Context()).WithFields(logrus.Fields{
+				"path": c.Request.URL.Path,
+			}).Warnf("validator.Validate() got error %v", err)
+			AbortWithError(c, errInvalidTokenResponse)
+			return
+		}
 
-			c.Abort()
+		SetPrincipal(c, principal)
+		c.Next()
+	}
+}
+
+// authenticateService authenticate service by given c pointer of gin.Context, and serviceAuth pointer of ServiceAuthenticator.
+func authenticateService(c *gin.Context, serviceAuth *ServiceAuthenticator) {
+	principal, err := serviceAuth.Authenticate(c.Request)
+	if err != nil {
+		log.WithContext(c.Request.Context()).WithFields(logrus.Fields{
+			"path":    c.Request.URL.Path,
+			"api_key": c.GetHeader(HeaderAPIKey),
+		}).Warnf("serviceAuth.Authenticate() got error %v", err)
+
+		if errors.Is(err, ErrBodyTooLarge) {
+			AbortWithError(c, apperror.TooLarge("request_too_large", ErrBodyTooLarge.Error()))
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid Token",
-			})
-			c.Abort()
+		if errors.Is(err, ErrNonceCheckUnavailable) {
+			AbortWithError(c, apperror.Unavailable("nonce_check_unavailable", ErrNonceCheckUnavailable.Error(), err))
 			return
 		}
 
-		c.Set("user_id", claims["user_id"].(float64))
-		c.Next()
+		AbortWithError(c, apperror.Unauthorized("invalid_service_credentials", err.Error()))
+		return
 	}
+
+	SetPrincipal(c, principal)
+	c.Next()
 }
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	// golang package
+	"orderfc/infrastructure/apperror"
+
+	// external package
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole require role by given roles.
+//
+// The principal must hold at least one of roles. It must be used after AuthMiddleware.
+//
+// It returns gin.HandlerFunc when successful.
+// Otherwise, empty gin.HandlerFunc will be returned.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			AbortWithError(c, ErrMissingAuth)
+			return
+		}
+
+		for _, role := range roles {
+			if principal.HasRole(role) {
+				c.Next()
+				return
+			}
+		}
+
+		AbortWithError(c, apperror.Forbidden("insufficient_role", "forbidden").WithDetails(gin.H{
+			"required_roles": roles,
+		}))
+	}
+}
+
+// RequireScope require scope by given scopes.
+//
+// The principal must hold every one of scopes. It must be used after AuthMiddleware.
+//
+// It returns gin.HandlerFunc when successful.
+// Otherwise, empty gin.HandlerFunc will be returned.
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			AbortWithError(c, ErrMissingAuth)
+			return
+		}
+
+		for _, scope := range scopes {
+			if !principal.HasScope(scope) {
+				AbortWithError(c, apperror.Forbidden("insufficient_scope", "insufficient scope").WithDetails(gin.H{
+					"required_scope": scope,
+				}))
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	// golang package
+	"orderfc/infrastructure/apperror"
+	"orderfc/infrastructure/log"
+
+	// external package
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler error handler.
+//
+// Handlers and middlewares report failures with c.Error and return. The last
+// error is rendered as the common error envelope with the status of its kind.
+// Causes of internal and unavailable errors are logged but never sent.
+//
+// It returns gin.HandlerFunc when successful.
+// Otherwise, empty gin.HandlerFunc will be returned.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		appErr := apperror.From(c.Errors.Last().Err)
+		ctx := c.Request.Context()
+		switch appErr.Kind {
+		case apperror.KindInternal:
+			log.WithContext(ctx).Errorf("request failed: %v", appErr)
+		case apperror.KindUnavailable:
+			log.WithContext(ctx).Warnf("request failed: %v", appErr)
+		}
+
+		c.JSON(appErr.HTTPStatus(), appErr.Response(log.RequestIDFromContext(ctx)))
+	}
+}
+
+// AbortWithError abort with error by given c pointer of gin.Context, and err.
+//
+// It stops the remaining handlers and leaves err for ErrorHandler to render.
+func AbortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
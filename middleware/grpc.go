@@ -0,0 +1,365 @@
+package middleware
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"orderfc/infrastructure/apperror"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/metrics"
+	"orderfc/infrastructure/validation"
+	"orderfc/models"
+	"strings"
+	"time"
+
+	// external package
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+const (
+	grpcErrorDomain    = "orderfc"
+	grpcRequestTimeout = 2 * time.Second
+)
+
+var errForbiddenScope = apperror.Forbidden("forbidden", "forbidden")
+
+// GRPCAuthenticator authenticates gRPC calls the way AuthMiddleware and
+// RequireScope do for HTTP. Calls carrying x-api-key metadata are verified as
+// signed service requests, every other call needs a user JWT in the
+// authorization metadata.
+type GRPCAuthenticator struct {
+	validator   *JWTValidator
+	serviceAuth *ServiceAuthenticator
+	scopes      map[string][]string
+	public      []string
+}
+
+// NewGRPCAuthenticator new grpc authenticator by given validator pointer of JWTValidator, serviceAuth pointer of ServiceAuthenticator, scopes by full method, and public service names.
+//
+// Methods of the public services, e.g. health and reflection, skip
+// authentication. Any other method missing from scopes is denied, so a new
+// method cannot be exposed by accident.
+//
+// It returns pointer of GRPCAuthenticator.
+func NewGRPCAuthenticator(validator *JWTValidator, serviceAuth *ServiceAuthenticator, scopes map[string][]string, public ...string) *GRPCAuthenticator {
+	return &GRPCAuthenticator{
+		validator:   validator,
+		serviceAuth: serviceAuth,
+		scopes:      scopes,
+		public:      public,
+	}
+}
+
+// Unary unary.
+//
+// The signature of a service call covers the deterministically marshalled
+// request message in place of the HTTP body.
+//
+// It returns grpc.UnaryServerInterceptor.
+func (a *GRPCAuthenticator) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if a.isPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		var body []byte
+		if message, ok := req.(proto.Message); ok {
+			var err error
+			body, err = proto.MarshalOptions{Deterministic: true}.Marshal(message)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		ctx, err := a.authenticate(ctx, info.FullMethod, body)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream stream.
+//
+// Stream calls are signed with an empty body since no message has been
+// received when they are authenticated.
+//
+// It returns grpc.StreamServerInterceptor.
+func (a *GRPCAuthenticator) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if a.isPublic(info.FullMethod) {
+			return handler(srv, stream)
+		}
+
+		ctx, err := a.authenticate(stream.Context(), info.FullMethod, nil)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authenticate authenticate by given fullMethod, and body.
+//
+// It returns context.Context carrying the principal, and nil error when successful.
+// Otherwise, nil context, and error will be returned.
+func (a *GRPCAuthenticator) authenticate(ctx context.Context, fullMethod string, body []byte) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	scopes, ok := a.scopes[fullMethod]
+	if !ok {
+		log.WithContext(ctx).Warnf("gRPC method %s has no required scope configured", fullMethod)
+		return nil, errForbiddenScope
+	}
+
+	if a.serviceAuth != nil && metadataValue(md, HeaderAPIKey) != "" {
+		principal, err := a.serviceAuth.Verify(ctx, SignedRequest{
+			APIKey:     metadataValue(md, HeaderAPIKey),
+			Timestamp:  metadataValue(md, HeaderTimestamp),
+			Nonce:      metadataValue(md, HeaderNonce),
+			Signature:  metadataValue(md, HeaderSignature),
+			Method:     http.MethodPost,
+			RequestURI: fullMethod,
+			Body:       body,
+		})
+		if err != nil {
+			log.WithContext(ctx).WithFields(logrus.Fields{
+				"method":  fullMethod,
+				"api_key": metadataValue(md, HeaderAPIKey),
+			}).Warnf("serviceAuth.Verify() got error %v", err)
+
+			if errors.Is(err, ErrNonceCheckUnavailable) {
+				return nil, apperror.Unavailable("nonce_check_unavailable", ErrNonceCheckUnavailable.Error(), err)
+			}
+
+			return nil, apperror.Unauthorized("invalid_service_credentials", err.Error())
+		}
+
+		return authorize(ctx, principal, scopes)
+	}
+
+	authHeader := metadataValue(md, "authorization")
+	if authHeader == "" {
+		return nil, ErrMissingAuth
+	}
+
+	tokenString := strings.Fields(authHeader)
+	if len(tokenString) != 2 || !strings.EqualFold(tokenString[0], "Bearer") {
+		return nil, errInvalidTokenResponse
+	}
+
+	principal, err := a.validator.Validate(ctx, tokenString[1])
+	if err != nil {
+		log.WithContext(ctx).WithFields(logrus.Fields{
+			"method": fullMethod,
+		}).Warnf("validator.Validate() got error %v", err)
+		return nil, errInvalidTokenResponse
+	}
+
+	return authorize(ctx, principal, scopes)
+}
+
+// isPublic is public by given fullMethod.
+//
+// It returns true when fullMethod belongs to a public service.
+// Otherwise, false will be returned.
+func (a *GRPCAuthenticator) isPublic(fullMethod string) bool {
+	for _, service := range a.public {
+		if strings.HasPrefix(fullMethod, "/"+service+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authorize authorize by given principal of models.Principal, and scopes.
+//
+// It returns context.Context carrying principal, and nil error when it holds every scope.
+// Otherwise, nil context, and error will be returned.
+func authorize(ctx context.Context, principal models.Principal, scopes []string) (context.Context, error) {
+	for _, scope := range scopes {
+		if !principal.HasScope(scope) {
+			log.WithContext(ctx).WithFields(logrus.Fields{
+				"subject": principal.Subject,
+				"scope":   scope,
+			}).Warn("principal lacks required scope")
+			return nil, errForbiddenScope
+		}
+	}
+
+	return ContextWithPrincipal(ctx, principal), nil
+}
+
+// GRPCLogger grpc logger.
+//
+// It is the gRPC counterpart of RequestLogger: every call gets a request id
+// and the same timeout, and is logged and measured once it returns.
+//
+// It returns grpc.UnaryServerInterceptor.
+func GRPCLogger() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		requestID := uuid.New().String()
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, grpcRequestTimeout)
+		defer cancel()
+
+		ctx = log.ContextWithRequestID(timeoutCtx, requestID)
+		_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", requestID))
+
+		startTime := time.Now()
+		resp, err := handler(ctx, req)
+		latency := time.Since(startTime)
+
+		code := status.Code(err)
+		metrics.GRPCRequestDuration.WithLabelValues(info.FullMethod, code.String()).Observe(latency.Seconds())
+
+		requestLog := logrus.Fields{
+			"request_id": requestID,
+			"method":     info.FullMethod,
+			"code":       code.String(),
+			"latency":    latency,
+		}
+
+		if code == codes.OK {
+			log.WithContext(ctx).WithFields(requestLog).Info("Request Success")
+		} else {
+			log.WithContext(ctx).WithFields(requestLog).Error("Request Error")
+		}
+
+		return resp, err
+	}
+}
+
+// GRPCErrorHandler grpc error handler.
+//
+// It is the gRPC counterpart of ErrorHandler. Domain errors become a status
+// with the matching code, an ErrorInfo carrying the error code and request
+// id, and a BadRequest listing the invalid fields of validation errors.
+// Panics are recovered as internal errors.
+//
+// It returns grpc.UnaryServerInterceptor.
+func GRPCErrorHandler() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				resp, err = nil, apperror.Internal(fmt.Errorf("panic in %s: %v", info.FullMethod, recovered))
+			}
+
+			if err != nil {
+				err = grpcError(ctx, err)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// grpcError grpc error by given err.
+//
+// Errors that already carry a gRPC status are returned unchanged.
+//
+// It returns error of the gRPC status for err.
+func grpcError(ctx context.Context, err error) error {
+	var withStatus interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &withStatus) {
+		return err
+	}
+
+	appErr := apperror.From(err)
+	switch appErr.Kind {
+	case apperror.KindInternal:
+		log.WithContext(ctx).Errorf("request failed: %v", appErr)
+	case apperror.KindUnavailable:
+		log.WithContext(ctx).Warnf("request failed: %v", appErr)
+	}
+
+	st := status.New(grpcCode(appErr.Kind), appErr.Message)
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason:   appErr.Code,
+			Domain:   grpcErrorDomain,
+			Metadata: map[string]string{"request_id": log.RequestIDFromContext(ctx)},
+		},
+	}
+
+	if fieldErrors, ok := appErr.Details.([]validation.FieldError); ok {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fieldErrors))
+		for _, fieldError := range fieldErrors {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       fieldError.Field,
+				Description: fieldError.Message,
+			})
+		}
+
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
+
+// grpcCode grpc code by given kind of apperror.Kind.
+//
+// It returns codes.Code matching the HTTP status of kind.
+func grpcCode(kind apperror.Kind) codes.Code {
+	switch kind {
+	case apperror.KindValidation:
+		return codes.InvalidArgument
+	case apperror.KindUnauthorized:
+		return codes.Unauthenticated
+	case apperror.KindForbidden:
+		return codes.PermissionDenied
+	case apperror.KindNotFound:
+		return codes.NotFound
+	case apperror.KindConflict:
+		return codes.FailedPrecondition
+	case apperror.KindTooLarge, apperror.KindRateLimited:
+		return codes.ResourceExhausted
+	case apperror.KindUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// metadataValue metadata value by given md of metadata.MD, and key.
+//
+// It returns string of the first value of key, which is matched case insensitively.
+func metadataValue(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context context.
+//
+// It returns context.Context carrying the authenticated principal.
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
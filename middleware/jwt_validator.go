@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"fmt"
+	"orderfc/config"
+	"orderfc/infrastructure/jwks"
+	"orderfc/models"
+	"strings"
+
+	// external package
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrInvalidTokenClaims = errors.New("invalid token claims")
+)
+
+type JWTValidator struct {
+	secret []byte
+	keySet *jwks.KeySet
+	parser *jwt.Parser
+}
+
+// NewJWTValidator new jwt validator by given cfg of config.AuthConfig, and secret.
+//
+// Only the configured algorithms are accepted. HS* tokens are verified with
+// secret, RS*/ES* tokens with the JWKS key matching their kid. Every token
+// must carry the configured issuer and audience.
+//
+// It returns pointer of JWTValidator, and nil error when successful.
+// Otherwise, nil pointer of JWTValidator, and error will be returned.
+func NewJWTValidator(ctx context.Context, cfg config.AuthConfig, secret string) (*JWTValidator, error) {
+	algorithms := cfg.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []string{config.DefaultJWTAlgorithm}
+	}
+
+	validator := &JWTValidator{
+		secret: []byte(secret),
+	}
+
+	needsKeySet := false
+	for _, alg := range algorithms {
+		switch {
+		case strings.HasPrefix(alg, "HS"):
+			if secret == "" {
+				return nil, fmt.Errorf("algorithm %s requires secret.jwtsecret", alg)
+			}
+		case strings.HasPrefix(alg, "RS"), strings.HasPrefix(alg, "PS"), strings.HasPrefix(alg, "ES"):
+			needsKeySet = true
+		default:
+			return nil, fmt.Errorf("unsupported jwt algorithm %q", alg)
+		}
+	}
+
+	if needsKeySet {
+		keySet, err := jwks.NewKeySet(ctx, cfg.JWKSFile, cfg.JWKSURL, cfg.JWKSRefreshInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		validator.keySet = keySet
+	}
+
+	validator.parser = jwt.NewParser(
+		jwt.WithValidMethods(algorithms),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithAudience(cfg.Audience),
+		jwt.WithLeeway(cfg.Leeway),
+	)
+	return validator, nil
+}
+
+// Validate validate by given tokenString.
+//
+// It returns models.Principal, and nil error when successful.
+// Otherwise, empty models.Principal, and error will be returned.
+func (v *JWTValidator) Validate(ctx context.Context, tokenString string) (models.Principal, error) {
+	token, err := v.parser.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return v.key(ctx, token)
+	})
+	if err != nil || !token.Valid {
+		return models.Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return models.Principal{}, ErrInvalidTokenClaims
+	}
+
+	principal, ok := principalFromClaims(claims)
+	if !ok {
+		return models.Principal{}, ErrInvalidTokenClaims
+	}
+
+	return principal, nil
+}
+
+// key key by given token pointer of jwt.Token.
+//
+// It returns the verification key, and nil error when successful.
+// Otherwise, nil key, and error will be returned.
+func (v *JWTValidator) key(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return v.secret, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+		if v.keySet == nil {
+			return nil, errors.New("no key set configured")
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("missing kid header")
+		}
+
+		return v.keySet.Key(ctx, kid)
+	default:
+		return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+	}
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	// golang package
+	"context"
+	"math"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/log"
+	"orderfc/models"
+	"strconv"
+	"strings"
+
+	// external package
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const principalKey = "principal"
+
+type principalContextKey struct{}
+
+// SetPrincipal set principal by given c pointer of gin.Context, and principal.
+//
+// The user_id key is kept for handlers that read the caller id directly.
+func SetPrincipal(c *gin.Context, principal models.Principal) {
+	c.Set(principalKey, principal)
+	c.Request = c.Request.WithContext(ContextWithPrincipal(c.Request.Context(), principal))
+	if principal.Type == constant.PrincipalTypeUser {
+		c.Set("user_id", float64(principal.UserID))
+	}
+}
+
+// ContextWithPrincipal context with principal by given principal.
+//
+// It is how the gRPC interceptors hand the caller to the handlers.
+//
+// It returns context.Context carrying principal, and the user id for logging.
+func ContextWithPrincipal(ctx context.Context, principal models.Principal) context.Context {
+	ctx = context.WithValue(ctx, principalContextKey{}, principal)
+	if principal.Type == constant.PrincipalTypeUser {
+		ctx = log.ContextWithUserID(ctx, principal.UserID)
+	}
+
+	return ctx
+}
+
+// PrincipalFromContext principal from context by given ctx.
+//
+// It returns models.Principal, and true when successful.
+// Otherwise, empty models.Principal, and false will be returned.
+func PrincipalFromContext(ctx context.Context) (models.Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(models.Principal)
+	return principal, ok
+}
+
+// GetPrincipal get principal by given c pointer of gin.Context.
+//
+// It returns models.Principal, and true when successful.
+// Otherwise, empty models.Principal, and false will be returned.
+func GetPrincipal(c *gin.Context) (models.Principal, bool) {
+	value, isExist := c.Get(principalKey)
+	if !isExist {
+		return models.Principal{}, false
+	}
+
+	principal, ok := value.(models.Principal)
+	return principal, ok
+}
+
+// principalFromClaims principal from claims by given claims of jwt.MapClaims.
+//
+// User tokens carry user_id, service account tokens carry client_id instead.
+//
+// It returns models.Principal, and true when successful.
+// Otherwise, empty models.Principal, and false will be returned.
+func principalFromClaims(claims jwt.MapClaims) (models.Principal, bool) {
+	principal := models.Principal{
+		Roles:  stringsClaim(claims, "roles"),
+		Scopes: stringsClaim(claims, "scopes"),
+	}
+
+	if role, ok := claims["role"].(string); ok && role != "" {
+		principal.Roles = append(principal.Roles, role)
+	}
+
+	if scope, ok := claims["scope"].(string); ok {
+		principal.Scopes = append(principal.Scopes, strings.Fields(scope)...)
+	}
+
+	if userID, ok := userIDClaim(claims); ok {
+		principal.Type = constant.PrincipalTypeUser
+		principal.UserID = userID
+		principal.Subject, _ = claims["sub"].(string)
+		if len(principal.Scopes) == 0 {
+			principal.Scopes = append(principal.Scopes, constant.DefaultUserScopes...)
+			for _, role := range principal.Roles {
+				principal.Scopes = append(principal.Scopes, constant.DefaultRoleScopes[role]...)
+			}
+		}
+
+		return principal, true
+	}
+
+	if clientID, ok := claims["client_id"].(string); ok && clientID != "" {
+		principal.Type = constant.PrincipalTypeService
+		principal.Subject = clientID
+		return principal, true
+	}
+
+	return models.Principal{}, false
+}
+
+// stringsClaim strings claim by given claims of jwt.MapClaims, and key.
+//
+// It returns slice of string when the claim is a list of strings.
+// Otherwise, nil value of string slice will be returned.
+func stringsClaim(claims jwt.MapClaims, key string) []string {
+	values, ok := claims[key].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var result []string
+	for _, value := range values {
+		if item, ok := value.(string); ok && item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// userIDClaim user id claim by given claims of jwt.MapClaims.
+//
+// The claim may be encoded as a JSON number or a numeric string.
+//
+// It returns int64, and true when successful.
+// Otherwise, empty int64, and false will be returned.
+func userIDClaim(claims jwt.MapClaims) (int64, bool) {
+	switch value := claims["user_id"].(type) {
+	case float64:
+		if value <= 0 || value > math.MaxInt64 || value != math.Trunc(value) {
+			return 0, false
+		}
+
+		return int64(value), true
+	case string:
+		userID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || userID <= 0 {
+			return 0, false
+		}
+
+		return userID, true
+	default:
+		return 0, false
+	}
+}
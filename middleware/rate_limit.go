@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	// golang package
+	"context"
+	"math"
+	"net"
+	"orderfc/config"
+	"orderfc/infrastructure/apperror"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/ratelimit"
+	"orderfc/models"
+	"strconv"
+	"time"
+
+	// external package
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+var errRateLimited = apperror.RateLimited("rate_limited", "too many requests")
+
+type RateLimiter struct {
+	enabled bool
+	routes  map[string][]config.RateLimitRule
+	limiter *ratelimit.Limiter
+}
+
+// NewRateLimiter new rate limiter by given cfg of config.RateLimitConfig, and redis pointer of redis.Client.
+//
+// It returns pointer of RateLimiter when successful.
+// Otherwise, nil pointer of RateLimiter will be returned.
+func NewRateLimiter(cfg config.RateLimitConfig, redis *redis.Client) *RateLimiter {
+	return &RateLimiter{
+		enabled: cfg.Enabled,
+		routes:  cfg.Routes,
+		limiter: ratelimit.NewLimiter(redis),
+	}
+}
+
+// Start start.
+//
+// It sweeps the in-memory fallback of the limiter until ctx is done.
+func (r *RateLimiter) Start(ctx context.Context) {
+	r.limiter.Start(ctx)
+}
+
+// Limit limit by given route name.
+//
+// Every rule configured for route is checked, and the most restrictive one
+// decides the X-RateLimit-* headers. Rules keyed by user fall back to the
+// client IP when the request is not authenticated.
+//
+// It returns gin.HandlerFunc when successful.
+// Otherwise, empty gin.HandlerFunc will be returned.
+func (r *RateLimiter) Limit(route string) gin.HandlerFunc {
+	rules := r.routes[route]
+
+	return func(c *gin.Context) {
+		if !r.enabled || len(rules) == 0 {
+			c.Next()
+			return
+		}
+
+		principal, _ := GetPrincipal(c)
+		current := r.allow(c.Request.Context(), route, rules, rateLimitSubject(principal, c.ClientIP()))
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(current.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(current.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(current.ResetAfter)))
+
+		if !current.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(current.RetryAfter)))
+			AbortWithError(c, errRateLimited)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Unary unary by given routes by full method.
+//
+// It is the gRPC counterpart of Limit, a method shares the counters of the
+// HTTP route it is mapped to, so a limit cannot be bypassed by switching
+// protocol. Rules keyed by ip count the peer address, since forwarded
+// metadata can be set by any client. The x-ratelimit-* headers are sent as
+// response metadata.
+//
+// It returns grpc.UnaryServerInterceptor.
+func (r *RateLimiter) Unary(routes map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		route, ok := routes[info.FullMethod]
+		rules := r.routes[route]
+		if !r.enabled || !ok || len(rules) == 0 {
+			return handler(ctx, req)
+		}
+
+		var address string
+		if client, ok := peer.FromContext(ctx); ok && client.Addr != nil {
+			address = client.Addr.String()
+			if host, _, err := net.SplitHostPort(address); err == nil {
+				address = host
+			}
+		}
+
+		principal, _ := PrincipalFromContext(ctx)
+		current := r.allow(ctx, route, rules, rateLimitSubject(principal, address))
+
+		md := metadata.Pairs(
+			"x-ratelimit-limit", strconv.Itoa(current.Limit),
+			"x-ratelimit-remaining", strconv.Itoa(current.Remaining),
+			"x-ratelimit-reset", strconv.Itoa(ceilSeconds(current.ResetAfter)),
+		)
+
+		if !current.Allowed {
+			md.Set("retry-after", strconv.Itoa(ceilSeconds(current.RetryAfter)))
+			_ = grpc.SetHeader(ctx, md)
+			return nil, errRateLimited
+		}
+
+		_ = grpc.SetHeader(ctx, md)
+		return handler(ctx, req)
+	}
+}
+
+// allow allow by given route name, rules of the route, and subject function by rule.
+//
+// It returns ratelimit.Result of the most restrictive rule, the request is
+// only counted when every rule allows it.
+func (r *RateLimiter) allow(ctx context.Context, route string, rules []config.RateLimitRule, subject func(by string) string) ratelimit.Result {
+	limits := make([]ratelimit.Rule, len(rules))
+	for index, rule := range rules {
+		limits[index] = ratelimit.Rule{
+			Key:    route + ":" + strconv.Itoa(index) + ":" + subject(rule.By),
+			Limit:  rule.Limit,
+			Window: rule.Window,
+		}
+	}
+
+	result, err := r.limiter.Allow(ctx, limits)
+	if err != nil {
+		log.WithContext(ctx).WithFields(logrus.Fields{
+			"route": route,
+		}).Warnf("r.limiter.Allow() got error %v, using local limiter", err)
+	}
+
+	return result
+}
+
+// rateLimitSubject rate limit subject by given principal of models.Principal, and address of the client.
+//
+// An empty principal, i.e. an unauthenticated request, is counted by address.
+//
+// It returns func returning string identifying who a rule counts requests for.
+func rateLimitSubject(principal models.Principal, address string) func(by string) string {
+	return func(by string) string {
+		if by == "user" && principal.Type != "" {
+			if principal.Type == constant.PrincipalTypeService {
+				return "service:" + principal.Subject
+			}
+
+			return "user:" + strconv.FormatInt(principal.UserID, 10)
+		}
+
+		return "ip:" + address
+	}
+}
+
+// ceilSeconds ceil seconds by given duration.
+//
+// It returns int of whole seconds, rounded up.
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
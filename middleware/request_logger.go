@@ -3,33 +3,57 @@ package middleware
 import (
 	// golang package
 	"context"
+	"orderfc/infrastructure/constant"
 	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/metrics"
+	"strconv"
 	"time"
 
 	// external package
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// RequestLogger request logger.
+// RequestLogger request logger by given streamRoutes.
+//
+// Requests to streamRoutes, e.g. server-sent events, stay open for as long
+// as the client listens, so they are not bound by the request timeout.
 //
 // It returns gin.HandlerFunc when successful.
 // Otherwise, empty gin.HandlerFunc will be returned.
-func RequestLogger() gin.HandlerFunc {
+func RequestLogger(streamRoutes ...string) gin.HandlerFunc {
+	streaming := make(map[string]bool, len(streamRoutes))
+	for _, route := range streamRoutes {
+		streaming[route] = true
+	}
+
 	return func(c *gin.Context) {
 		requestID := uuid.New().String()
 
-		timeoutCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
+		ctx := c.Request.Context()
+		if !streaming[c.FullPath()] {
+			timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			defer cancel()
+			ctx = timeoutCtx
+		}
 
-		ctx := context.WithValue(timeoutCtx, "request_id", requestID)
+		ctx = log.ContextWithRequestID(ctx, requestID)
 		c.Request = c.Request.WithContext(ctx)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request_id", requestID))
 
 		startTime := time.Now()
 		c.Next()
 		latency := time.Since(startTime)
 
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Observe(latency.Seconds())
+
 		requestLog := logrus.Fields{
 			"request_id": requestID,
 			"method":     c.Request.Method,
@@ -38,10 +62,19 @@ func RequestLogger() gin.HandlerFunc {
 			"latency":    latency,
 		}
 
+		if principal, ok := GetPrincipal(c); ok {
+			requestLog["principal_type"] = principal.Type
+			if principal.Type == constant.PrincipalTypeService {
+				requestLog["service"] = principal.Subject
+			} else {
+				requestLog["user_id"] = principal.UserID
+			}
+		}
+
 		if c.Writer.Status() == 200 || c.Writer.Status() == 201 {
-			log.Logger.WithFields(requestLog).Info("Request Success")
+			log.WithContext(c.Request.Context()).WithFields(requestLog).Info("Request Success")
 		} else {
-			log.Logger.WithFields(requestLog).Error("Request Error")
+			log.WithContext(c.Request.Context()).WithFields(requestLog).Error("Request Error")
 		}
 	}
 }
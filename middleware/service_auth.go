@@ -0,0 +1,239 @@
+package middleware
+
+import (
+	// golang package
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"orderfc/config"
+	"orderfc/infrastructure/constant"
+	"orderfc/models"
+	"strconv"
+	"time"
+
+	// external package
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	HeaderAPIKey    = "X-Api-Key"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderNonce     = "X-Nonce"
+	HeaderSignature = "X-Signature"
+
+	defaultMaxClockSkew = 5 * time.Minute
+	maxSignedBodySize   = 10 << 20
+	nonceKeyPrefix      = "orderfc:nonce:"
+)
+
+var (
+	ErrUnknownAPIKey         = errors.New("unknown api key")
+	ErrMissingSignature      = errors.New("missing signature headers")
+	ErrInvalidTimestamp      = errors.New("timestamp outside allowed window")
+	ErrInvalidSignature      = errors.New("invalid signature")
+	ErrReplayedRequest       = errors.New("nonce already used")
+	ErrNonceCheckUnavailable = errors.New("unable to verify nonce")
+	ErrBodyTooLarge          = fmt.Errorf("request body larger than %d bytes", maxSignedBodySize)
+)
+
+// nonceStore remembers the nonces already used.
+type nonceStore interface {
+	// SetNX stores key for expiration unless it is stored already, and reports whether it was stored.
+	SetNX(ctx context.Context, key string, expiration time.Duration) (bool, error)
+}
+
+type redisNonceStore struct {
+	redis *redis.Client
+}
+
+// SetNX set nx by given key, and expiration.
+//
+// It returns bool true when key was not stored yet, and nil error when successful.
+// Otherwise, false, and error will be returned.
+func (s redisNonceStore) SetNX(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	return s.redis.SetNX(ctx, key, 1, expiration).Result()
+}
+
+type ServiceAuthenticator struct {
+	keys         map[string]config.APIKeyConfig
+	nonces       nonceStore
+	maxClockSkew time.Duration
+}
+
+// NewServiceAuthenticator new service authenticator by given cfg of config.ServiceAuthConfig, and redis pointer of redis.Client.
+//
+// It returns pointer of ServiceAuthenticator when successful.
+// Otherwise, nil pointer of ServiceAuthenticator will be returned.
+func NewServiceAuthenticator(cfg config.ServiceAuthConfig, redis *redis.Client) *ServiceAuthenticator {
+	maxClockSkew := cfg.MaxClockSkew
+	if maxClockSkew <= 0 {
+		maxClockSkew = defaultMaxClockSkew
+	}
+
+	keys := make(map[string]config.APIKeyConfig, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		keys[key.ID] = key
+	}
+
+	authenticator := &ServiceAuthenticator{
+		keys:         keys,
+		maxClockSkew: maxClockSkew,
+	}
+
+	if redis != nil {
+		authenticator.nonces = redisNonceStore{redis: redis}
+	}
+
+	return authenticator
+}
+
+// SignedRequest is what a service signs, taken from HTTP headers or gRPC metadata.
+type SignedRequest struct {
+	APIKey     string
+	Timestamp  string
+	Nonce      string
+	Signature  string
+	Method     string
+	RequestURI string
+	Body       []byte
+}
+
+// Authenticate authenticate by given req pointer of http.Request.
+//
+// The request body is read to verify the signature and restored afterwards.
+//
+// It returns models.Principal, and nil error when successful.
+// Otherwise, empty models.Principal, and error will be returned.
+func (a *ServiceAuthenticator) Authenticate(req *http.Request) (models.Principal, error) {
+	// unknown keys are rejected before the body is read
+	if _, ok := a.keys[req.Header.Get(HeaderAPIKey)]; !ok {
+		return models.Principal{}, ErrUnknownAPIKey
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return models.Principal{}, err
+	}
+
+	return a.Verify(req.Context(), SignedRequest{
+		APIKey:     req.Header.Get(HeaderAPIKey),
+		Timestamp:  req.Header.Get(HeaderTimestamp),
+		Nonce:      req.Header.Get(HeaderNonce),
+		Signature:  req.Header.Get(HeaderSignature),
+		Method:     req.Method,
+		RequestURI: req.URL.RequestURI(),
+		Body:       body,
+	})
+}
+
+// Verify verify by given signed of SignedRequest.
+//
+// It returns models.Principal, and nil error when successful.
+// Otherwise, empty models.Principal, and error will be returned.
+func (a *ServiceAuthenticator) Verify(ctx context.Context, signed SignedRequest) (models.Principal, error) {
+	key, ok := a.keys[signed.APIKey]
+	if !ok {
+		return models.Principal{}, ErrUnknownAPIKey
+	}
+
+	if signed.Timestamp == "" || signed.Nonce == "" || signed.Signature == "" {
+		return models.Principal{}, ErrMissingSignature
+	}
+
+	unix, err := strconv.ParseInt(signed.Timestamp, 10, 64)
+	if err != nil {
+		return models.Principal{}, ErrInvalidTimestamp
+	}
+
+	skew := time.Since(time.Unix(unix, 0))
+	if skew > a.maxClockSkew || skew < -a.maxClockSkew {
+		return models.Principal{}, ErrInvalidTimestamp
+	}
+
+	expected := SignRequest(key.Secret, signed.Method, signed.RequestURI, signed.Timestamp, signed.Nonce, signed.Body)
+	if !hmac.Equal([]byte(expected), []byte(signed.Signature)) {
+		return models.Principal{}, ErrInvalidSignature
+	}
+
+	err = a.claimNonce(ctx, key.ID, signed.Nonce)
+	if err != nil {
+		return models.Principal{}, err
+	}
+
+	return models.Principal{
+		Type:    constant.PrincipalTypeService,
+		Subject: key.Service,
+		Roles:   key.Roles,
+		Scopes:  key.Scopes,
+	}, nil
+}
+
+// claimNonce claim nonce by given keyID, and nonce.
+//
+// A nonce is accepted once per key for twice the clock skew window, which
+// covers every timestamp that could still pass the window check.
+//
+// It returns nil error when successful.
+// Otherwise, error will be returned.
+func (a *ServiceAuthenticator) claimNonce(ctx context.Context, keyID string, nonce string) error {
+	if a.nonces == nil {
+		return ErrNonceCheckUnavailable
+	}
+
+	isNew, err := a.nonces.SetNX(ctx, nonceKeyPrefix+keyID+":"+nonce, 2*a.maxClockSkew)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNonceCheckUnavailable, err)
+	}
+
+	if !isNew {
+		return ErrReplayedRequest
+	}
+
+	return nil
+}
+
+// SignRequest sign request by given secret, method, requestURI, timestamp, nonce, and body.
+//
+// The signature is the hex encoded HMAC-SHA256 of the method, request URI,
+// timestamp, nonce and hex encoded SHA-256 of the body joined by newlines.
+//
+// It returns string of the signature.
+func SignRequest(secret string, method string, requestURI string, timestamp string, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + requestURI + "\n" + timestamp + "\n" + nonce + "\n" + hex.EncodeToString(bodyHash[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// readBody read body by given req pointer of http.Request.
+//
+// A body over the limit is rejected rather than cut, a signature over part of
+// it would not cover what the handler reads.
+//
+// It returns slice of byte, and nil error when successful.
+// Otherwise, nil value of byte slice, and ErrBodyTooLarge, or error will be returned.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxSignedBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) > maxSignedBodySize {
+		return nil, ErrBodyTooLarge
+	}
+
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
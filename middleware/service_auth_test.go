@@ -0,0 +1,310 @@
+package middleware
+
+import (
+	// golang package
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"orderfc/config"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	// external package
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testAPIKey = "inventory-1"
+	testSecret = "9f2c4e7a1b8d3f6e0a5c2b9d4e7f1a3c"
+)
+
+func TestMain(m *testing.M) {
+	// rejected requests are logged as warnings
+	log.SetupLogger(config.LogConfig{Level: "error"})
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// fakeNonceStore keeps the nonces in memory, like Redis SETNX without expiry.
+type fakeNonceStore struct {
+	mu   sync.Mutex
+	keys map[string]time.Duration
+	err  error
+}
+
+func (s *fakeNonceStore) SetNX(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return false, s.err
+	}
+
+	if _, ok := s.keys[key]; ok {
+		return false, nil
+	}
+
+	s.keys[key] = expiration
+	return true, nil
+}
+
+func newTestServiceAuthenticator() (*ServiceAuthenticator, *fakeNonceStore) {
+	authenticator := NewServiceAuthenticator(config.ServiceAuthConfig{
+		Keys: []config.APIKeyConfig{
+			{ID: testAPIKey, Service: "inventory", Secret: testSecret, Scopes: []string{"orders:read"}},
+		},
+	}, nil)
+
+	nonces := &fakeNonceStore{keys: map[string]time.Duration{}}
+	authenticator.nonces = nonces
+	return authenticator, nonces
+}
+
+func newSignedRequest(nonce string) SignedRequest {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	body := []byte(`{"order_id":42}`)
+
+	return SignedRequest{
+		APIKey:     testAPIKey,
+		Timestamp:  timestamp,
+		Nonce:      nonce,
+		Signature:  SignRequest(testSecret, http.MethodPost, "/api/v1/orders/42/status?force=true", timestamp, nonce, body),
+		Method:     http.MethodPost,
+		RequestURI: "/api/v1/orders/42/status?force=true",
+		Body:       body,
+	}
+}
+
+func TestServiceAuthenticatorVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(signed *SignedRequest)
+		wantErr error
+	}{
+		{
+			name:   "valid signature",
+			modify: func(signed *SignedRequest) {},
+		},
+		{
+			name:    "method changed",
+			modify:  func(signed *SignedRequest) { signed.Method = http.MethodPut },
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "uri changed",
+			modify:  func(signed *SignedRequest) { signed.RequestURI = "/api/v1/orders/43/status?force=true" },
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "query changed",
+			modify:  func(signed *SignedRequest) { signed.RequestURI = "/api/v1/orders/42/status" },
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "body changed",
+			modify:  func(signed *SignedRequest) { signed.Body = []byte(`{"order_id":43}`) },
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "timestamp changed",
+			modify: func(signed *SignedRequest) {
+				signed.Timestamp = strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "nonce changed",
+			modify:  func(signed *SignedRequest) { signed.Nonce = "other-nonce" },
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "signed with another secret",
+			modify: func(signed *SignedRequest) {
+				signed.Signature = SignRequest("3e8a1f5c7b2d9e4a6c0f8b1d5e3a7c9f", signed.Method, signed.RequestURI, signed.Timestamp, signed.Nonce, signed.Body)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "stale timestamp",
+			modify: func(signed *SignedRequest) {
+				signed.Timestamp = strconv.FormatInt(time.Now().Add(-defaultMaxClockSkew-time.Minute).Unix(), 10)
+				signed.Signature = SignRequest(testSecret, signed.Method, signed.RequestURI, signed.Timestamp, signed.Nonce, signed.Body)
+			},
+			wantErr: ErrInvalidTimestamp,
+		},
+		{
+			name: "timestamp in the future",
+			modify: func(signed *SignedRequest) {
+				signed.Timestamp = strconv.FormatInt(time.Now().Add(defaultMaxClockSkew+time.Minute).Unix(), 10)
+				signed.Signature = SignRequest(testSecret, signed.Method, signed.RequestURI, signed.Timestamp, signed.Nonce, signed.Body)
+			},
+			wantErr: ErrInvalidTimestamp,
+		},
+		{
+			name:    "timestamp not a number",
+			modify:  func(signed *SignedRequest) { signed.Timestamp = "yesterday" },
+			wantErr: ErrInvalidTimestamp,
+		},
+		{
+			name:    "missing signature",
+			modify:  func(signed *SignedRequest) { signed.Signature = "" },
+			wantErr: ErrMissingSignature,
+		},
+		{
+			name:    "missing nonce",
+			modify:  func(signed *SignedRequest) { signed.Nonce = "" },
+			wantErr: ErrMissingSignature,
+		},
+		{
+			name:    "unknown api key",
+			modify:  func(signed *SignedRequest) { signed.APIKey = "unknown" },
+			wantErr: ErrUnknownAPIKey,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			authenticator, _ := newTestServiceAuthenticator()
+			signed := newSignedRequest("nonce-1")
+			test.modify(&signed)
+
+			principal, err := authenticator.Verify(context.Background(), signed)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Verify() got error %v, want %v", err, test.wantErr)
+			}
+
+			if test.wantErr != nil {
+				return
+			}
+
+			if principal.Type != constant.PrincipalTypeService || principal.Subject != "inventory" {
+				t.Errorf("Verify() = %+v, want the inventory service", principal)
+			}
+		})
+	}
+}
+
+func TestServiceAuthenticatorReplayedNonce(t *testing.T) {
+	authenticator, nonces := newTestServiceAuthenticator()
+
+	_, err := authenticator.Verify(context.Background(), newSignedRequest("nonce-1"))
+	if err != nil {
+		t.Fatalf("first Verify() got error %v", err)
+	}
+
+	_, err = authenticator.Verify(context.Background(), newSignedRequest("nonce-1"))
+	if !errors.Is(err, ErrReplayedRequest) {
+		t.Fatalf("replayed Verify() got error %v, want %v", err, ErrReplayedRequest)
+	}
+
+	_, err = authenticator.Verify(context.Background(), newSignedRequest("nonce-2"))
+	if err != nil {
+		t.Fatalf("Verify() with a new nonce got error %v", err)
+	}
+
+	if expiration := nonces.keys[nonceKeyPrefix+testAPIKey+":nonce-1"]; expiration != 2*defaultMaxClockSkew {
+		t.Errorf("nonce kept for %s, want %s", expiration, 2*defaultMaxClockSkew)
+	}
+}
+
+func TestServiceAuthenticatorNonceCheckUnavailable(t *testing.T) {
+	authenticator, nonces := newTestServiceAuthenticator()
+	nonces.err = errors.New("connection refused")
+
+	_, err := authenticator.Verify(context.Background(), newSignedRequest("nonce-1"))
+	if !errors.Is(err, ErrNonceCheckUnavailable) {
+		t.Fatalf("Verify() got error %v, want %v", err, ErrNonceCheckUnavailable)
+	}
+
+	// without Redis nonces cannot be checked, so no request is accepted
+	authenticator.nonces = nil
+	_, err = authenticator.Verify(context.Background(), newSignedRequest("nonce-2"))
+	if !errors.Is(err, ErrNonceCheckUnavailable) {
+		t.Fatalf("Verify() without a nonce store got error %v, want %v", err, ErrNonceCheckUnavailable)
+	}
+}
+
+func TestAuthMiddlewareServiceRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       []byte
+		apiKey     string
+		tamper     bool
+		wantStatus int
+	}{
+		{
+			name:       "valid signature",
+			body:       []byte(`{"status":"cancelled"}`),
+			apiKey:     testAPIKey,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "body changed after signing",
+			body:       []byte(`{"status":"cancelled"}`),
+			apiKey:     testAPIKey,
+			tamper:     true,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "unknown api key",
+			body:       []byte(`{"status":"cancelled"}`),
+			apiKey:     "unknown",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "oversized body",
+			body:       bytes.Repeat([]byte("a"), maxSignedBodySize+1),
+			apiKey:     testAPIKey,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			authenticator, _ := newTestServiceAuthenticator()
+
+			var gotBody string
+			router := gin.New()
+			router.Use(ErrorHandler())
+			router.POST("/api/v1/orders/:order_id/status", AuthMiddleware(nil, authenticator), func(c *gin.Context) {
+				body, _ := c.GetRawData()
+				gotBody = string(body)
+				c.Status(http.StatusOK)
+			})
+
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			signature := SignRequest(testSecret, http.MethodPost, "/api/v1/orders/42/status", timestamp, "nonce-1", test.body)
+
+			body := test.body
+			if test.tamper {
+				body = []byte(`{"status":"completed"}`)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/orders/42/status", bytes.NewReader(body))
+			req.Header.Set(HeaderAPIKey, test.apiKey)
+			req.Header.Set(HeaderTimestamp, timestamp)
+			req.Header.Set(HeaderNonce, "nonce-1")
+			req.Header.Set(HeaderSignature, signature)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", recorder.Code, test.wantStatus, strings.TrimSpace(recorder.Body.String()))
+			}
+
+			// the handler still reads the body the signature was checked against
+			if test.wantStatus == http.StatusOK && gotBody != string(test.body) {
+				t.Errorf("handler read body %q, want %q", gotBody, test.body)
+			}
+		})
+	}
+}
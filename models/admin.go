@@ -0,0 +1,56 @@
+package models
+
+import "encoding/json"
+
+type AdminOrderSearchParam struct {
+	UserID        int64  `form:"user_id"`
+	Status        *int   `form:"status"`
+	PaymentMethod string `form:"payment_method"`
+	Page          int    `form:"page"`
+	PageSize      int    `form:"page_size"`
+}
+
+type AdminOrderResponse struct {
+	OrderID         int64   `json:"order_id"`
+	UserID          int64   `json:"user_id"`
+	Amount          float64 `json:"amount"`
+	TotalQty        int     `json:"total_qty"`
+	OrderDetailID   int64   `json:"order_detail_id"`
+	Status          string  `json:"status"`
+	PaymentMethod   string  `json:"payment_method"`
+	ShippingAddress string  `json:"shipping_address"`
+}
+
+type AdminOrderSearchResponse struct {
+	Orders   []AdminOrderResponse `json:"orders"`
+	Total    int64                `json:"total"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"page_size"`
+}
+
+type AdminUpdateOrderStatusRequest struct {
+	Status int    `json:"status"`
+	Reason string `json:"reason"`
+}
+
+type AdminUpdateOrderStatusResponse struct {
+	OrderID int64  `json:"order_id"`
+	Status  string `json:"status"`
+}
+
+type AdminResendEventRequest struct {
+	Event string `json:"event"`
+}
+
+type AdminResendEventResponse struct {
+	OrderID int64  `json:"order_id"`
+	Event   string `json:"event"`
+	Status  string `json:"status"`
+}
+
+type AdminOrderDetailResponse struct {
+	OrderID       int64           `json:"order_id"`
+	OrderDetailID int64           `json:"order_detail_id"`
+	Products      json.RawMessage `json:"products"`
+	OrderHistory  json.RawMessage `json:"order_history"`
+}
@@ -20,17 +20,22 @@ type OrderDetail struct {
 }
 
 type CheckoutItem struct {
-	ProductID int64   `json:"product_id"`
-	Quantity  int     `json:"quantity"`
-	Price     float64 `json:"price"`
+	ProductID int64   `json:"product_id" binding:"product_id"`
+	Quantity  int     `json:"quantity" binding:"required,min=1,max=10000"`
+	Price     float64 `json:"price" binding:"required,gt=0"`
 }
 
 type CheckoutRequest struct {
 	UserID           int64          `json:"user_id"`
-	Items            []CheckoutItem `json:"items"`
-	PaymentMethod    string         `json:"payment_method"`
-	ShippingAddress  string         `json:"shipping_address"`
-	IdempotencyToken string         `json:"idempotency_token"`
+	Items            []CheckoutItem `json:"items" binding:"required,min=1,max=50,dive"`
+	PaymentMethod    string         `json:"payment_method" binding:"required,payment_method"`
+	ShippingAddress  string         `json:"shipping_address" binding:"required,min=10,max=500"`
+	IdempotencyToken string         `json:"idempotency_token" binding:"omitempty,max=64"`
+}
+
+type CheckoutResponse struct {
+	OrderID int64  `json:"order_id"`
+	Status  string `json:"status"`
 }
 
 type OrderRequestLog struct {
@@ -55,9 +60,14 @@ type OrderHistoryResponse struct {
 	History         []StatusHistory `json:"history"`
 }
 
+// StatusHistory is one status change of an order. ActorID is the user that
+// made it, Actor the service when it was made by one.
 type StatusHistory struct {
 	Status    string `json:"status"`
 	Timestamp string `json:"timestamp"`
+	Reason    string `json:"reason,omitempty"`
+	ActorID   int64  `json:"actor_id,omitempty"`
+	Actor     string `json:"actor,omitempty"`
 }
 
 type OrderJoinResult struct {
@@ -78,3 +88,39 @@ type OrderCreatedEvent struct {
 	PaymentMethod   string  `json:"payment_method"`
 	ShippingAddress string  `json:"shipping_address"`
 }
+
+// Redact redact.
+//
+// It returns a copy of the request with the shipping address hidden, so it can be logged.
+func (r CheckoutRequest) Redact() interface{} {
+	if r.ShippingAddress != "" {
+		r.ShippingAddress = "[REDACTED]"
+	}
+
+	return r
+}
+
+// Redact redact.
+//
+// It returns a copy of the event with the shipping address hidden, so it can be logged.
+func (e OrderCreatedEvent) Redact() interface{} {
+	if e.ShippingAddress != "" {
+		e.ShippingAddress = "[REDACTED]"
+	}
+
+	return e
+}
+
+type OrderStatusEvent struct {
+	ID        int64     `json:"id,omitempty"`
+	OrderID   int64     `json:"order_id"`
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason,omitempty"`
+	EventTime time.Time `json:"event_time"`
+}
+
+type OrderEventStream struct {
+	Backlog []OrderStatusEvent
+	Events  <-chan OrderStatusEvent
+	Close   func()
+}
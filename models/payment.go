@@ -1,6 +1,14 @@
 package models
 
+import "time"
+
 type PaymentUpdateStatusEvent struct {
 	OrderID int64  `json:"order_id"`
 	Status  string `json:"status"`
 }
+
+type PaymentVoidEvent struct {
+	OrderID   int64     `json:"order_id"`
+	Reason    string    `json:"reason"`
+	EventTime time.Time `json:"event_time"`
+}
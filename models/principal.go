@@ -0,0 +1,37 @@
+package models
+
+type Principal struct {
+	Type    string   `json:"type"`
+	Subject string   `json:"subject"`
+	UserID  int64    `json:"user_id"`
+	Roles   []string `json:"roles"`
+	Scopes  []string `json:"scopes"`
+}
+
+// HasRole has role by given role.
+//
+// It returns true when the principal holds role.
+// Otherwise, false will be returned.
+func (p Principal) HasRole(role string) bool {
+	for _, item := range p.Roles {
+		if item == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasScope has scope by given scope.
+//
+// It returns true when the principal holds scope.
+// Otherwise, false will be returned.
+func (p Principal) HasScope(scope string) bool {
+	for _, item := range p.Scopes {
+		if item == scope {
+			return true
+		}
+	}
+
+	return false
+}
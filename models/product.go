@@ -23,3 +23,19 @@ type ProductItem struct {
 	ProductID int64 `json:"product_id"`
 	Qty       int   `json:"qty"`
 }
+
+// ConvertCheckoutItemToProductItems convert checkout item to product items by given source slice of CheckoutItem.
+//
+// It returns slice of ProductItem when successful.
+// Otherwise, nil value of ProductItem slice will be returned.
+func ConvertCheckoutItemToProductItems(source []CheckoutItem) []ProductItem {
+	result := make([]ProductItem, len(source))
+	for index, item := range source {
+		result[index] = ProductItem{
+			ProductID: item.ProductID,
+			Qty:       item.Quantity,
+		}
+	}
+
+	return result
+}
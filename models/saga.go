@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+type CheckoutSaga struct {
+	ID           int64
+	OrderID      int64
+	State        string
+	Step         string
+	Steps        string // stringfy json
+	Attempts     int
+	LastError    string
+	Reason       string
+	DeadlineTime time.Time
+	Version      int
+	CreateTime   time.Time
+	UpdateTime   time.Time
+}
+
+type SagaStep struct {
+	Name     string    `json:"name"`
+	Status   string    `json:"status"`
+	Attempts int       `json:"attempts,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+type AdminSagaSearchParam struct {
+	State    string `form:"state" binding:"omitempty,oneof=running completed compensating compensated failed"`
+	Overdue  bool   `form:"overdue"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+}
+
+type AdminSagaResponse struct {
+	OrderID      int64      `json:"order_id"`
+	State        string     `json:"state"`
+	Step         string     `json:"step"`
+	Attempts     int        `json:"attempts"`
+	LastError    string     `json:"last_error,omitempty"`
+	Reason       string     `json:"reason,omitempty"`
+	DeadlineTime time.Time  `json:"deadline_time"`
+	Overdue      bool       `json:"overdue"`
+	Steps        []SagaStep `json:"steps"`
+	CreateTime   time.Time  `json:"create_time"`
+	UpdateTime   time.Time  `json:"update_time"`
+}
+
+type AdminSagaListResponse struct {
+	Sagas    []AdminSagaResponse `json:"sagas"`
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type WebhookSubscription struct {
+	ID         int64
+	URL        string
+	Secret     string
+	EventTypes string // stringfy json
+	Active     bool
+	CreateTime time.Time
+	UpdateTime time.Time
+}
+
+type WebhookDelivery struct {
+	ID              int64
+	SubscriptionID  int64
+	EventID         string
+	EventType       string
+	OrderID         int64
+	Payload         string // stringfy json
+	Status          string
+	Attempts        int
+	NextAttemptTime time.Time
+	ResponseCode    int
+	LastError       string
+	CreateTime      time.Time
+	UpdateTime      time.Time
+}
+
+type WebhookEvent struct {
+	ID        string           `json:"id"`
+	Type      string           `json:"type"`
+	EventTime time.Time        `json:"event_time"`
+	Data      WebhookEventData `json:"data"`
+}
+
+type WebhookEventData struct {
+	OrderID int64  `json:"order_id"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type AdminCreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,http_url,max=2048"`
+	Secret     string   `json:"secret" binding:"omitempty,min=16,max=256"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,dive,oneof=order.created order.processing order.completed order.cancelled"`
+}
+
+type AdminWebhookResponse struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreateTime time.Time `json:"create_time"`
+}
+
+type AdminDeleteWebhookResponse struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+type AdminWebhookDeliveryParam struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending succeeded failed"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"page_size"`
+}
+
+type AdminWebhookDeliveryResponse struct {
+	ID              int64           `json:"id"`
+	EventID         string          `json:"event_id"`
+	EventType       string          `json:"event_type"`
+	OrderID         int64           `json:"order_id"`
+	Status          string          `json:"status"`
+	Attempts        int             `json:"attempts"`
+	NextAttemptTime time.Time       `json:"next_attempt_time"`
+	ResponseCode    int             `json:"response_code,omitempty"`
+	LastError       string          `json:"last_error,omitempty"`
+	Payload         json.RawMessage `json:"payload"`
+	CreateTime      time.Time       `json:"create_time"`
+	UpdateTime      time.Time       `json:"update_time"`
+}
+
+type AdminWebhookDeliveryListResponse struct {
+	Deliveries []AdminWebhookDeliveryResponse `json:"deliveries"`
+	Total      int64                          `json:"total"`
+	Page       int                            `json:"page"`
+	PageSize   int                            `json:"page_size"`
+}
+
+type AdminReplayWebhookDeliveryResponse struct {
+	DeliveryID int64  `json:"delivery_id"`
+	Status     string `json:"status"`
+}
@@ -0,0 +1,4 @@
+// Package orderpb holds the protobuf messages and gRPC service of the order API.
+package orderpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative order.proto
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	// golang package
+	"orderfc/config"
+	"orderfc/infrastructure/constant"
+	"orderfc/kafka/consumer"
+)
+
+// SetupConsumers setup consumers by given runner pointer of consumer.Runner, cfg of config.KafkaConfig, and PaymentHandler.
+func SetupConsumers(runner *consumer.Runner, cfg config.KafkaConfig, paymentHandler consumer.PaymentHandler) {
+	runner.Use(consumer.Tracing(), consumer.Metrics(), consumer.Logging(), consumer.Recover())
+
+	consumer.Register(runner, consumer.Route{
+		Name:      "payment_success",
+		Topic:     cfg.Topics.PaymentSuccess,
+		GroupID:   cfg.GroupIDs.PaymentSuccess,
+		EventType: constant.EventPaymentSuccess,
+	}, paymentHandler.HandlePaymentSuccess)
+
+	consumer.Register(runner, consumer.Route{
+		Name:      "payment_failed",
+		Topic:     cfg.Topics.PaymentFailed,
+		GroupID:   cfg.GroupIDs.PaymentFailed,
+		EventType: constant.EventPaymentFailed,
+	}, paymentHandler.HandlePaymentFailed)
+}
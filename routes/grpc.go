@@ -0,0 +1,60 @@
+package routes
+
+import (
+	// golang package
+	"orderfc/cmd/order/handler"
+	"orderfc/config"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/health"
+	"orderfc/middleware"
+	"orderfc/proto/orderpb"
+
+	// external package
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// grpcScopes are the scopes each gRPC method needs, matching the HTTP routes.
+var grpcScopes = map[string][]string{
+	orderpb.OrderService_Checkout_FullMethodName:    {constant.ScopeOrdersWrite},
+	orderpb.OrderService_GetOrder_FullMethodName:    {constant.ScopeOrdersRead},
+	orderpb.OrderService_ListOrders_FullMethodName:  {constant.ScopeOrdersRead},
+	orderpb.OrderService_CancelOrder_FullMethodName: {constant.ScopeOrdersWrite},
+}
+
+// grpcRateLimits are the rate limited HTTP routes each gRPC method counts against.
+var grpcRateLimits = map[string]string{
+	orderpb.OrderService_Checkout_FullMethodName:   "checkout",
+	orderpb.OrderService_ListOrders_FullMethodName: "order_history",
+}
+
+// SetupGRPCServer setup grpc server by given cfg of config.GRPCConfig, orderHandler pointer of handler.OrderGRPCHandler, jwtValidator pointer of middleware.JWTValidator, serviceAuth pointer of middleware.ServiceAuthenticator, rateLimiter pointer of middleware.RateLimiter, and healthChecker pointer of health.Checker.
+//
+// The health service reports the same checks as /readyz, and together with
+// reflection it is reachable without credentials.
+//
+// It returns pointer of grpc.Server.
+func SetupGRPCServer(cfg config.GRPCConfig, orderHandler *handler.OrderGRPCHandler, jwtValidator *middleware.JWTValidator, serviceAuth *middleware.ServiceAuthenticator, rateLimiter *middleware.RateLimiter, healthChecker *health.Checker) *grpc.Server {
+	authenticator := middleware.NewGRPCAuthenticator(jwtValidator, serviceAuth, grpcScopes,
+		healthpb.Health_ServiceDesc.ServiceName,
+		reflectionv1.ServerReflection_ServiceDesc.ServiceName,
+		reflectionv1alpha.ServerReflection_ServiceDesc.ServiceName,
+	)
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(middleware.GRPCLogger(), middleware.GRPCErrorHandler(), authenticator.Unary(), rateLimiter.Unary(grpcRateLimits)),
+		grpc.ChainStreamInterceptor(authenticator.Stream()),
+	)
+
+	orderpb.RegisterOrderServiceServer(server, orderHandler)
+	healthpb.RegisterHealthServer(server, healthChecker.GRPCServer(orderpb.OrderService_ServiceDesc.ServiceName))
+
+	if cfg.Reflection {
+		reflection.Register(server)
+	}
+
+	return server
+}
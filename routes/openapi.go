@@ -0,0 +1,602 @@
+package routes
+
+import (
+	// golang package
+	"net/http"
+	"orderfc/infrastructure/apperror"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/health"
+	"orderfc/infrastructure/openapi"
+	"orderfc/infrastructure/validation"
+	"orderfc/middleware"
+	"orderfc/models"
+	"orderfc/webhook"
+	"sort"
+)
+
+const (
+	OpenAPIPath = "/openapi.json"
+	DocsPath    = "/docs"
+
+	apiTitle = "Order Service API"
+
+	securityBearer  = "bearerAuth"
+	securityService = "serviceAuth"
+
+	tagOrders     = "orders"
+	tagAdmin      = "admin"
+	tagWebhooks   = "webhooks"
+	tagSagas      = "sagas"
+	tagOperations = "operations"
+)
+
+// OpenAPIDocument openapi document.
+//
+// Every route registered by SetupRoutes must be described here; the
+// cmd/openapi check run in CI fails when the two drift apart. Request and
+// response bodies are generated from the models the handlers bind and
+// return, so changing a model changes the document with it.
+//
+// It returns pointer of openapi.Document.
+func OpenAPIDocument() *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:       apiTitle,
+		Description: "Checkout and order history for users, and order management for admins. Errors share one envelope with a stable machine readable code.",
+		Version:     "1.0.0",
+	})
+
+	doc.Tags = []openapi.Tag{
+		{Name: tagOrders, Description: "Orders of the authenticated user."},
+		{Name: tagAdmin, Description: "Order management, requires the admin role."},
+		{Name: tagWebhooks, Description: "Webhook subscriptions of partners and their delivery log, requires the admin role."},
+		{Name: tagSagas, Description: "Checkout sagas, i.e. where the checkout of every order stands, requires the admin role."},
+		{Name: tagOperations, Description: "Health, metrics and API documentation."},
+	}
+
+	doc.RegisterTag("product_id", func(schema *openapi.Schema, _ string) bool {
+		minimum := float64(1)
+		schema.Minimum = &minimum
+		return true
+	})
+
+	doc.RegisterTag("payment_method", func(schema *openapi.Schema, _ string) bool {
+		schema.Enum = paymentMethods()
+		return true
+	})
+
+	doc.RegisterTag("http_url", func(schema *openapi.Schema, _ string) bool {
+		schema.Format = "uri"
+		return false
+	})
+
+	addSecuritySchemes(doc)
+	addErrorResponses(doc)
+	addOrderOperations(doc)
+	addAdminOperations(doc)
+	addWebhookOperations(doc)
+	addSagaOperations(doc)
+	addOperationalOperations(doc)
+
+	return doc
+}
+
+// addSecuritySchemes add security schemes by given doc pointer of openapi.Document.
+func addSecuritySchemes(doc *openapi.Document) {
+	doc.Components.SecuritySchemes[securityBearer] = &openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  "User token. The user id is taken from the token, roles and scopes from its claims.",
+	}
+
+	doc.Components.SecuritySchemes[securityService] = &openapi.SecurityScheme{
+		Type: "apiKey",
+		In:   "header",
+		Name: middleware.HeaderAPIKey,
+		Description: "Internal services send their key with " + middleware.HeaderTimestamp + " (unix seconds), " +
+			middleware.HeaderNonce + " (single use) and " + middleware.HeaderSignature + ", the hex encoded " +
+			"HMAC-SHA256 of the method, request URI, timestamp, nonce and hex encoded SHA-256 of the body joined by newlines.",
+	}
+}
+
+// addErrorResponses add error responses by given doc pointer of openapi.Document.
+func addErrorResponses(doc *openapi.Document) {
+	doc.RegisterName(apperror.Response{}, "Error")
+	doc.RegisterName(apperror.Body{}, "ErrorBody")
+
+	doc.SchemaOf(apperror.Response{})
+	doc.Components.Schemas["ErrorBody"].Properties["details"].Description = "Extra context, for validation_failed a list of FieldError."
+
+	doc.Components.Schemas["ValidationError"] = &openapi.Schema{
+		AllOf: []*openapi.Schema{
+			openapi.Ref("Error"),
+			{
+				Type: "object",
+				Properties: map[string]*openapi.Schema{
+					"error": {
+						Type: "object",
+						Properties: map[string]*openapi.Schema{
+							"details": {Type: "array", Items: doc.SchemaOf(validation.FieldError{})},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	responses := []struct {
+		name        string
+		description string
+		schema      string
+	}{
+		{"BadRequest", "The request is malformed or failed validation.", "ValidationError"},
+		{"Unauthorized", "Credentials are missing or invalid.", "Error"},
+		{"Forbidden", "The principal lacks the required role or scope.", "Error"},
+		{"NotFound", "The order does not exist.", "Error"},
+		{"Conflict", "The idempotency token was already used.", "Error"},
+		{"TooManyRequests", "The rate limit was exceeded.", "Error"},
+		{"InternalError", "Unexpected failure, the message is generic.", "Error"},
+		{"ServiceUnavailable", "A dependency is unavailable or timed out, retry later.", "Error"},
+	}
+
+	for _, response := range responses {
+		doc.Components.Responses[response.name] = &openapi.Response{
+			Description: response.description,
+			Content:     openapi.JSON(openapi.Ref(response.schema)),
+		}
+	}
+
+	doc.Components.Responses["TooManyRequests"].Headers = map[string]*openapi.Header{
+		"Retry-After": {Description: "Seconds until a request is allowed again.", Schema: &openapi.Schema{Type: "integer"}},
+	}
+}
+
+// addOrderOperations add order operations by given doc pointer of openapi.Document.
+func addOrderOperations(doc *openapi.Document) {
+	doc.Add(http.MethodPost, "/v1/checkout", authenticated(constant.ScopeOrdersWrite, true, &openapi.Operation{
+		Tags:        []string{tagOrders},
+		Summary:     "Create an order",
+		Description: "user_id is ignored, the order belongs to the authenticated user. Repeating an idempotency_token returns 409.",
+		OperationID: "checkout",
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.SchemaOf(models.CheckoutRequest{})),
+		},
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): rateLimited(&openapi.Response{
+				Description: "The order was created.",
+				Content:     openapi.JSON(doc.SchemaOf(models.CheckoutResponse{})),
+			}),
+			openapi.Status(http.StatusBadRequest): openapi.ResponseRef("BadRequest"),
+			openapi.Status(http.StatusConflict):   openapi.ResponseRef("Conflict"),
+		},
+	}))
+
+	doc.Add(http.MethodGet, "/v1/order_history", authenticated(constant.ScopeOrdersRead, true, &openapi.Operation{
+		Tags:        []string{tagOrders},
+		Summary:     "List the orders of the authenticated user",
+		OperationID: "getOrderHistory",
+		Parameters: []openapi.Parameter{
+			{
+				Name:        "status",
+				In:          "query",
+				Description: "Order status to filter by, 0 returns every order.",
+				Schema:      &openapi.Schema{Type: "integer", Enum: orderStatuses()},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): rateLimited(&openapi.Response{
+				Description: "The orders of the user.",
+				Content:     openapi.JSON(data(&openapi.Schema{Type: "array", Items: doc.SchemaOf(models.OrderHistoryResponse{})})),
+			}),
+		},
+	}))
+
+	doc.Add(http.MethodGet, orderEventsPath, authenticated(constant.ScopeOrdersRead, true, &openapi.Operation{
+		Tags:    []string{tagOrders},
+		Summary: "Stream the status changes of an order",
+		Description: "Server-sent events named status, each carrying an OrderStatusEvent as data. The stream starts with the current status " +
+			"and stays open, with a comment line sent as heartbeat. A client reconnecting with Last-Event-ID first gets the events it missed.",
+		OperationID: "getOrderEvents",
+		Parameters: []openapi.Parameter{
+			orderIDParameter(),
+			{
+				Name:        "Last-Event-ID",
+				In:          "header",
+				Description: "Id of the last event received, sent by EventSource when it reconnects.",
+				Schema:      &openapi.Schema{Type: "integer", Format: "int64"},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): rateLimited(&openapi.Response{
+				Description: "The event stream.",
+				Content:     map[string]openapi.MediaType{"text/event-stream": {Schema: doc.SchemaOf(models.OrderStatusEvent{})}},
+			}),
+			openapi.Status(http.StatusBadRequest): openapi.ResponseRef("BadRequest"),
+			openapi.Status(http.StatusNotFound):   openapi.ResponseRef("NotFound"),
+		},
+	}))
+}
+
+// addAdminOperations add admin operations by given doc pointer of openapi.Document.
+func addAdminOperations(doc *openapi.Document) {
+	doc.Add(http.MethodGet, "/admin/v1/orders", authenticated(constant.ScopeAdminOrdersRead, false, &openapi.Operation{
+		Tags:        []string{tagAdmin},
+		Summary:     "Search orders",
+		OperationID: "adminSearchOrders",
+		Parameters:  doc.QueryParameters(models.AdminOrderSearchParam{}),
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "One page of matching orders.",
+				Content:     openapi.JSON(data(doc.SchemaOf(models.AdminOrderSearchResponse{}))),
+			},
+			openapi.Status(http.StatusBadRequest): openapi.ResponseRef("BadRequest"),
+		},
+	}))
+
+	doc.Add(http.MethodGet, "/admin/v1/orders/:id/detail", authenticated(constant.ScopeAdminOrdersRead, false, &openapi.Operation{
+		Tags:        []string{tagAdmin},
+		Summary:     "Get the raw detail of an order",
+		OperationID: "adminGetOrderDetail",
+		Parameters:  []openapi.Parameter{orderIDParameter()},
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "The stored products and status history of the order.",
+				Content:     openapi.JSON(data(doc.SchemaOf(models.AdminOrderDetailResponse{}))),
+			},
+			openapi.Status(http.StatusBadRequest): openapi.ResponseRef("BadRequest"),
+			openapi.Status(http.StatusNotFound):   openapi.ResponseRef("NotFound"),
+		},
+	}))
+
+	doc.Add(http.MethodPatch, "/admin/v1/orders/:id/status", authenticated(constant.ScopeAdminOrdersWrite, false, &openapi.Operation{
+		Tags:        []string{tagAdmin},
+		Summary:     "Force the status of an order",
+		OperationID: "adminUpdateOrderStatus",
+		Parameters:  []openapi.Parameter{orderIDParameter()},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.SchemaOf(models.AdminUpdateOrderStatusRequest{})),
+		},
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "The status was updated.",
+				Content:     openapi.JSON(doc.SchemaOf(models.AdminUpdateOrderStatusResponse{})),
+			},
+			openapi.Status(http.StatusBadRequest): openapi.ResponseRef("BadRequest"),
+			openapi.Status(http.StatusNotFound):   openapi.ResponseRef("NotFound"),
+		},
+	}))
+
+	doc.Add(http.MethodPost, "/admin/v1/orders/:id/events", authenticated(constant.ScopeAdminOrdersWrite, false, &openapi.Operation{
+		Tags:        []string{tagAdmin},
+		Summary:     "Publish an order event again",
+		OperationID: "adminResendOrderEvent",
+		Parameters:  []openapi.Parameter{orderIDParameter()},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.SchemaOf(models.AdminResendEventRequest{})),
+		},
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "The event was published.",
+				Content:     openapi.JSON(doc.SchemaOf(models.AdminResendEventResponse{})),
+			},
+			openapi.Status(http.StatusBadRequest): openapi.ResponseRef("BadRequest"),
+			openapi.Status(http.StatusNotFound):   openapi.ResponseRef("NotFound"),
+		},
+	}))
+
+	status := doc.Components.Schemas["AdminUpdateOrderStatusRequest"].Properties
+	status["status"].Enum = orderStatuses()
+	status["reason"].Description = "Required, kept in the order status history."
+
+	event := doc.Components.Schemas["AdminResendEventRequest"].Properties
+	event["event"].Enum = []interface{}{constant.EventOrderCreated, constant.EventProductStockUpdate, constant.EventProductStockRollback}
+}
+
+// addWebhookOperations add webhook operations by given doc pointer of openapi.Document.
+func addWebhookOperations(doc *openapi.Document) {
+	doc.Add(http.MethodPost, "/admin/v1/webhooks", authenticated(constant.ScopeAdminWebhooksWrite, false, &openapi.Operation{
+		Tags:    []string{tagWebhooks},
+		Summary: "Subscribe a URL to order status changes",
+		Description: "Every status change of an order with a subscribed event type is POSTed as a WebhookEvent. Requests carry " +
+			webhook.HeaderEventID + ", " + webhook.HeaderEventType + ", " + webhook.HeaderTimestamp + " and " + webhook.HeaderSignature +
+			", which is sha256= and the hex HMAC-SHA256 of the timestamp, a dot and the body keyed with the secret. " +
+			"Non 2xx answers are retried with backoff. The secret is generated when omitted and only returned here.",
+		OperationID: "adminCreateWebhook",
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSON(doc.SchemaOf(models.AdminCreateWebhookRequest{})),
+		},
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusCreated): {
+				Description: "The subscription, including its secret.",
+				Content:     openapi.JSON(data(doc.SchemaOf(models.AdminWebhookResponse{}))),
+			},
+			openapi.Status(http.StatusBadRequest): openapi.ResponseRef("BadRequest"),
+		},
+	}))
+
+	doc.Add(http.MethodGet, "/admin/v1/webhooks", authenticated(constant.ScopeAdminWebhooksRead, false, &openapi.Operation{
+		Tags:        []string{tagWebhooks},
+		Summary:     "List the webhook subscriptions",
+		OperationID: "adminListWebhooks",
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "Every subscription, without secrets.",
+				Content:     openapi.JSON(data(&openapi.Schema{Type: "array", Items: doc.SchemaOf(models.AdminWebhookResponse{})})),
+			},
+		},
+	}))
+
+	doc.Add(http.MethodDelete, "/admin/v1/webhooks/:id", authenticated(constant.ScopeAdminWebhooksWrite, false, &openapi.Operation{
+		Tags:        []string{tagWebhooks},
+		Summary:     "Deactivate a webhook subscription",
+		Description: "Its delivery log is kept, pending deliveries fail.",
+		OperationID: "adminDeleteWebhook",
+		Parameters:  []openapi.Parameter{webhookIDParameter()},
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "The subscription was deactivated.",
+				Content:     openapi.JSON(doc.SchemaOf(models.AdminDeleteWebhookResponse{})),
+			},
+			openapi.Status(http.StatusBadRequest): openapi.ResponseRef("BadRequest"),
+			openapi.Status(http.StatusNotFound):   openapi.ResponseRef("NotFound"),
+		},
+	}))
+
+	doc.Add(http.MethodGet, "/admin/v1/webhooks/:id/deliveries", authenticated(constant.ScopeAdminWebhooksRead, false, &openapi.Operation{
+		Tags:        []string{tagWebhooks},
+		Summary:     "List the deliveries of a webhook subscription",
+		OperationID: "adminListWebhookDeliveries",
+		Parameters:  append([]openapi.Parameter{webhookIDParameter()}, doc.QueryParameters(models.AdminWebhookDeliveryParam{})...),
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "One page of deliveries, newest first.",
+				Content:     openapi.JSON(data(doc.SchemaOf(models.AdminWebhookDeliveryListResponse{}))),
+			},
+			openapi.Status(http.StatusBadRequest): openapi.ResponseRef("BadRequest"),
+			openapi.Status(http.StatusNotFound):   openapi.ResponseRef("NotFound"),
+		},
+	}))
+
+	doc.Add(http.MethodPost, "/admin/v1/webhooks/:id/deliveries/:delivery_id/replay", authenticated(constant.ScopeAdminWebhooksWrite, false, &openapi.Operation{
+		Tags:        []string{tagWebhooks},
+		Summary:     "Replay a failed webhook delivery",
+		Description: "The delivery is sent again with the same event id and payload, with a fresh set of attempts.",
+		OperationID: "adminReplayWebhookDelivery",
+		Parameters: []openapi.Parameter{
+			webhookIDParameter(),
+			{
+				Name:     "delivery_id",
+				In:       "path",
+				Required: true,
+				Schema:   &openapi.Schema{Type: "integer", Format: "int64"},
+			},
+		},
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "The delivery is pending again.",
+				Content:     openapi.JSON(doc.SchemaOf(models.AdminReplayWebhookDeliveryResponse{})),
+			},
+			openapi.Status(http.StatusBadRequest): openapi.ResponseRef("BadRequest"),
+			openapi.Status(http.StatusNotFound):   openapi.ResponseRef("NotFound"),
+			openapi.Status(http.StatusConflict):   openapi.ResponseRef("Conflict"),
+		},
+	}))
+
+	// the body sent to the subscribers
+	doc.SchemaOf(models.WebhookEvent{})
+}
+
+// addSagaOperations add saga operations by given doc pointer of openapi.Document.
+func addSagaOperations(doc *openapi.Document) {
+	doc.Add(http.MethodGet, "/admin/v1/sagas", authenticated(constant.ScopeAdminSagasRead, false, &openapi.Operation{
+		Tags:    []string{tagSagas},
+		Summary: "List the checkout sagas",
+		Description: "A saga is running through reserve_stock, request_payment, await_payment and complete_order, or compensating " +
+			"through void_payment, release_stock and cancel_order, until it is completed, compensated or failed. Failed sagas ran " +
+			"out of attempts while compensating and need an operator. Overdue sagas have not finished although their deadline " +
+			"passed, i.e. no worker moved them on in time.",
+		OperationID: "adminListSagas",
+		Parameters:  doc.QueryParameters(models.AdminSagaSearchParam{}),
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "One page of sagas, newest first.",
+				Content:     openapi.JSON(data(doc.SchemaOf(models.AdminSagaListResponse{}))),
+			},
+			openapi.Status(http.StatusBadRequest): openapi.ResponseRef("BadRequest"),
+		},
+	}))
+
+	doc.Add(http.MethodGet, "/admin/v1/orders/:id/saga", authenticated(constant.ScopeAdminSagasRead, false, &openapi.Operation{
+		Tags:        []string{tagSagas},
+		Summary:     "Get the checkout saga of an order",
+		Description: "The current step and the log of every finished step and compensation. Orders placed before checkouts ran as a saga have none.",
+		OperationID: "adminGetOrderSaga",
+		Parameters:  []openapi.Parameter{orderIDParameter()},
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "The saga of the order.",
+				Content:     openapi.JSON(data(doc.SchemaOf(models.AdminSagaResponse{}))),
+			},
+			openapi.Status(http.StatusBadRequest): openapi.ResponseRef("BadRequest"),
+			openapi.Status(http.StatusNotFound):   openapi.ResponseRef("NotFound"),
+		},
+	}))
+}
+
+// addOperationalOperations add operational operations by given doc pointer of openapi.Document.
+func addOperationalOperations(doc *openapi.Document) {
+	doc.RegisterName(health.Report{}, "HealthReport")
+	doc.RegisterName(health.CheckResult{}, "HealthCheckResult")
+	report := doc.SchemaOf(health.Report{})
+
+	doc.Add(http.MethodGet, "/healthz", &openapi.Operation{
+		Tags:        []string{tagOperations},
+		Summary:     "Liveness probe",
+		OperationID: "liveness",
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): {Description: "The process is running.", Content: openapi.JSON(report)},
+		},
+	})
+
+	doc.Add(http.MethodGet, "/readyz", &openapi.Operation{
+		Tags:        []string{tagOperations},
+		Summary:     "Readiness probe",
+		OperationID: "readiness",
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK):                 {Description: "Every dependency is reachable.", Content: openapi.JSON(report)},
+			openapi.Status(http.StatusServiceUnavailable): {Description: "At least one check failed.", Content: openapi.JSON(report)},
+		},
+	})
+
+	doc.Add(http.MethodGet, "/metrics", &openapi.Operation{
+		Tags:        []string{tagOperations},
+		Summary:     "Prometheus metrics",
+		OperationID: "metrics",
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "Metrics in the Prometheus text format.",
+				Content:     map[string]openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: "string"}}},
+			},
+		},
+	})
+
+	doc.Add(http.MethodGet, OpenAPIPath, &openapi.Operation{
+		Tags:        []string{tagOperations},
+		Summary:     "This OpenAPI document",
+		OperationID: "openAPI",
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): {Description: "The OpenAPI 3 document.", Content: openapi.JSON(&openapi.Schema{Type: "object"})},
+		},
+	})
+
+	doc.Add(http.MethodGet, DocsPath, &openapi.Operation{
+		Tags:        []string{tagOperations},
+		Summary:     "API documentation UI",
+		OperationID: "docs",
+		Responses: map[string]*openapi.Response{
+			openapi.Status(http.StatusOK): {
+				Description: "Swagger UI rendering this document.",
+				Content:     map[string]openapi.MediaType{"text/html": {Schema: &openapi.Schema{Type: "string"}}},
+			},
+		},
+	})
+}
+
+// authenticated authenticated by given scope, limited, and operation pointer of openapi.Operation.
+//
+// It returns pointer of openapi.Operation accepting user and service
+// credentials, with the responses shared by every authenticated route.
+func authenticated(scope string, limited bool, operation *openapi.Operation) *openapi.Operation {
+	operation.Security = []openapi.SecurityRequirement{
+		{securityBearer: {}},
+		{securityService: {}},
+	}
+
+	requirement := "Requires the " + scope + " scope."
+	if operation.Description != "" {
+		requirement = operation.Description + " " + requirement
+	}
+
+	operation.Description = requirement
+
+	operation.Responses[openapi.Status(http.StatusUnauthorized)] = openapi.ResponseRef("Unauthorized")
+	operation.Responses[openapi.Status(http.StatusForbidden)] = openapi.ResponseRef("Forbidden")
+	operation.Responses[openapi.Status(http.StatusInternalServerError)] = openapi.ResponseRef("InternalError")
+	operation.Responses[openapi.Status(http.StatusServiceUnavailable)] = openapi.ResponseRef("ServiceUnavailable")
+	if limited {
+		operation.Responses[openapi.Status(http.StatusTooManyRequests)] = openapi.ResponseRef("TooManyRequests")
+	}
+
+	return operation
+}
+
+// rateLimited rate limited by given response pointer of openapi.Response.
+//
+// It returns pointer of openapi.Response with the X-RateLimit-* headers.
+func rateLimited(response *openapi.Response) *openapi.Response {
+	integer := &openapi.Schema{Type: "integer"}
+	response.Headers = map[string]*openapi.Header{
+		"X-RateLimit-Limit":     {Description: "Requests allowed in the window of the most restrictive rule.", Schema: integer},
+		"X-RateLimit-Remaining": {Description: "Requests left in the window.", Schema: integer},
+		"X-RateLimit-Reset":     {Description: "Seconds until the window resets.", Schema: integer},
+	}
+
+	return response
+}
+
+// data data by given schema pointer of openapi.Schema.
+//
+// It returns pointer of openapi.Schema of the {"data": ...} envelope.
+func data(schema *openapi.Schema) *openapi.Schema {
+	return &openapi.Schema{
+		Type:       "object",
+		Properties: map[string]*openapi.Schema{"data": schema},
+		Required:   []string{"data"},
+	}
+}
+
+// orderIDParameter order id parameter.
+//
+// It returns openapi.Parameter of the :id path parameter.
+func orderIDParameter() openapi.Parameter {
+	return openapi.Parameter{
+		Name:     "id",
+		In:       "path",
+		Required: true,
+		Schema:   &openapi.Schema{Type: "integer", Format: "int64"},
+	}
+}
+
+// webhookIDParameter webhook id parameter.
+//
+// It returns openapi.Parameter of the :id path parameter of a webhook subscription.
+func webhookIDParameter() openapi.Parameter {
+	return openapi.Parameter{
+		Name:     "id",
+		In:       "path",
+		Required: true,
+		Schema:   &openapi.Schema{Type: "integer", Format: "int64"},
+	}
+}
+
+// orderStatuses order statuses.
+//
+// It returns slice of every order status code, in order.
+func orderStatuses() []interface{} {
+	statuses := make([]int, 0, len(constant.OrderStatusTranslated))
+	for status := range constant.OrderStatusTranslated {
+		statuses = append(statuses, status)
+	}
+
+	sort.Ints(statuses)
+
+	values := make([]interface{}, 0, len(statuses))
+	for _, status := range statuses {
+		values = append(values, status)
+	}
+
+	return values
+}
+
+// paymentMethods payment methods.
+//
+// It returns slice of every supported payment method, sorted.
+func paymentMethods() []interface{} {
+	methods := make([]string, 0, len(constant.PaymentMethods))
+	for method := range constant.PaymentMethods {
+		methods = append(methods, method)
+	}
+
+	sort.Strings(methods)
+
+	values := make([]interface{}, 0, len(methods))
+	for _, method := range methods {
+		values = append(values, method)
+	}
+
+	return values
+}
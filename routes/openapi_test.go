@@ -0,0 +1,27 @@
+package routes
+
+import (
+	// golang package
+	"orderfc/cmd/order/handler"
+	"orderfc/config"
+	"orderfc/infrastructure/health"
+	"orderfc/infrastructure/openapi"
+	"orderfc/middleware"
+	"testing"
+
+	// external package
+	"github.com/gin-gonic/gin"
+)
+
+// TestOpenAPIDocumentMatchesRoutes registers the routes the way cmd/openapi
+// --check does and fails when a route and the document drift apart.
+func TestOpenAPIDocumentMatchesRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupRoutes(router, handler.OrderHandler{}, nil, nil, middleware.NewRateLimiter(config.RateLimitConfig{}, nil), health.NewChecker(0))
+
+	err := openapi.Check(OpenAPIDocument(), router.Routes())
+	if err != nil {
+		t.Fatalf("openapi.Check() got error %v", err)
+	}
+}
@@ -3,18 +3,51 @@ package routes
 import (
 	// golang package
 	"orderfc/cmd/order/handler"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/health"
+	"orderfc/infrastructure/openapi"
 	"orderfc/middleware"
 
 	// external package
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// SetupRoutes setup routes by given router pointer of gin.Engine, and OrderHandler.
-func SetupRoutes(router *gin.Engine, orderHandler handler.OrderHandler, jwtSecret string) {
-	router.Use(middleware.RequestLogger())
-	authMiddleware := middleware.AuthMiddleware(jwtSecret)
-	router.Use(authMiddleware)
-	router.POST("/v1/checkout", orderHandler.Checkout)
+// orderEventsPath is the server-sent events stream, which is exempt from the request timeout.
+const orderEventsPath = "/v1/orders/:id/events"
 
-	router.GET("/v1/order_history", orderHandler.GetOrderHistory)
+// SetupRoutes setup routes by given router pointer of gin.Engine, OrderHandler, jwtValidator pointer of middleware.JWTValidator, serviceAuth pointer of middleware.ServiceAuthenticator, rateLimiter pointer of middleware.RateLimiter, and healthChecker pointer of health.Checker.
+func SetupRoutes(router *gin.Engine, orderHandler handler.OrderHandler, jwtValidator *middleware.JWTValidator, serviceAuth *middleware.ServiceAuthenticator, rateLimiter *middleware.RateLimiter, healthChecker *health.Checker) {
+	// probes and scrapes are registered before the middlewares, so they are neither traced nor logged
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	router.GET("/healthz", healthChecker.Liveness())
+	router.GET("/readyz", healthChecker.Readiness())
+
+	router.Use(middleware.Tracing(), middleware.RequestLogger(orderEventsPath), middleware.ErrorHandler())
+	authMiddleware := middleware.AuthMiddleware(jwtValidator, serviceAuth)
+
+	router.GET(OpenAPIPath, openapi.Handler(OpenAPIDocument()))
+	router.GET(DocsPath, openapi.DocsHandler(apiTitle, OpenAPIPath))
+
+	v1 := router.Group("/v1", authMiddleware)
+	v1.POST("/checkout", rateLimiter.Limit("checkout"), middleware.RequireScope(constant.ScopeOrdersWrite), orderHandler.Checkout)
+
+	v1.GET("/order_history", rateLimiter.Limit("order_history"), middleware.RequireScope(constant.ScopeOrdersRead), orderHandler.GetOrderHistory)
+
+	v1.GET("/orders/:id/events", rateLimiter.Limit("order_events"), middleware.RequireScope(constant.ScopeOrdersRead), orderHandler.GetOrderEvents)
+
+	admin := router.Group("/admin/v1", authMiddleware, middleware.RequireRole(constant.RoleAdmin))
+	admin.GET("/orders", middleware.RequireScope(constant.ScopeAdminOrdersRead), orderHandler.AdminSearchOrders)
+	admin.GET("/orders/:id/detail", middleware.RequireScope(constant.ScopeAdminOrdersRead), orderHandler.AdminGetOrderDetail)
+	admin.PATCH("/orders/:id/status", middleware.RequireScope(constant.ScopeAdminOrdersWrite), orderHandler.AdminUpdateOrderStatus)
+	admin.POST("/orders/:id/events", middleware.RequireScope(constant.ScopeAdminOrdersWrite), orderHandler.AdminResendOrderEvent)
+
+	admin.POST("/webhooks", middleware.RequireScope(constant.ScopeAdminWebhooksWrite), orderHandler.AdminCreateWebhook)
+	admin.GET("/webhooks", middleware.RequireScope(constant.ScopeAdminWebhooksRead), orderHandler.AdminListWebhooks)
+	admin.DELETE("/webhooks/:id", middleware.RequireScope(constant.ScopeAdminWebhooksWrite), orderHandler.AdminDeleteWebhook)
+	admin.GET("/webhooks/:id/deliveries", middleware.RequireScope(constant.ScopeAdminWebhooksRead), orderHandler.AdminListWebhookDeliveries)
+	admin.POST("/webhooks/:id/deliveries/:delivery_id/replay", middleware.RequireScope(constant.ScopeAdminWebhooksWrite), orderHandler.AdminReplayWebhookDelivery)
+
+	admin.GET("/sagas", middleware.RequireScope(constant.ScopeAdminSagasRead), orderHandler.AdminListSagas)
+	admin.GET("/orders/:id/saga", middleware.RequireScope(constant.ScopeAdminSagasRead), orderHandler.AdminGetOrderSaga)
 }
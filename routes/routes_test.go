@@ -0,0 +1,56 @@
+package routes
+
+import (
+	// golang package
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"orderfc/cmd/order/handler"
+	"orderfc/config"
+	"orderfc/infrastructure/health"
+	"orderfc/infrastructure/log"
+	"orderfc/middleware"
+	"strings"
+	"testing"
+
+	// external package
+	"github.com/gin-gonic/gin"
+)
+
+// TestProbesAreNotLogged fails when liveness, readiness or metrics requests
+// go through the request logger, which would log every probe at info.
+func TestProbesAreNotLogged(t *testing.T) {
+	log.SetupLogger(config.LogConfig{Level: "info"})
+	var output bytes.Buffer
+	log.Logger.SetOutput(&output)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupRoutes(router, handler.OrderHandler{}, nil, nil, middleware.NewRateLimiter(config.RateLimitConfig{}, nil), health.NewChecker(0))
+
+	tests := []struct {
+		path       string
+		wantLogged bool
+	}{
+		{path: "/healthz", wantLogged: false},
+		{path: "/readyz", wantLogged: false},
+		{path: "/metrics", wantLogged: false},
+		{path: OpenAPIPath, wantLogged: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			output.Reset()
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("GET %s = %d, want %d", test.path, recorder.Code, http.StatusOK)
+			}
+
+			if logged := strings.Contains(output.String(), test.path); logged != test.wantLogged {
+				t.Errorf("GET %s logged = %t, want %t: %s", test.path, logged, test.wantLogged, output.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,379 @@
+package saga
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"orderfc/config"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/worker"
+	"orderfc/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLease          = 30 * time.Second
+	defaultPaymentTimeout = 30 * time.Minute
+	defaultMaxAttempts    = 5
+	defaultInitialBackoff = 5 * time.Second
+	defaultMaxBackoff     = 5 * time.Minute
+
+	// a saga written concurrently is read again at most this many times
+	maxConflictRetries = 5
+)
+
+var (
+	// ErrSagaNotFound is returned for orders placed before checkouts ran as a saga.
+	ErrSagaNotFound = errors.New("order has no checkout saga")
+
+	errSagaConflict = errors.New("checkout saga kept changing concurrently")
+)
+
+// OrderService is the part of service.OrderService the saga runs on.
+type OrderService interface {
+	GetCheckoutSagaByOrderID(ctx context.Context, orderID int64) (models.CheckoutSaga, error)
+	ClaimCheckoutSagas(ctx context.Context, limit int, lease time.Duration) ([]models.CheckoutSaga, error)
+	UpdateCheckoutSaga(ctx context.Context, saga models.CheckoutSaga) (bool, error)
+	GetPrimaryOrderInfoByOrderID(ctx context.Context, orderID int64) (models.Order, error)
+	GetPrimaryOrderDetailByOrderDetailID(ctx context.Context, orderDetailID int64) (models.OrderDetail, error)
+	TransitionOrderStatus(ctx context.Context, order models.Order, from int, to int, entry models.StatusHistory) (bool, error)
+}
+
+// Producer is the part of kafka.KafkaProducer publishing the saga events.
+type Producer interface {
+	PublishOrderCreated(ctx context.Context, event models.OrderCreatedEvent) error
+	PublishProductStockUpdate(ctx context.Context, event models.ProductStockUpdateEvent) error
+	PublishProductStockRollback(ctx context.Context, event models.ProductStockUpdateEvent) error
+	PublishPaymentVoid(ctx context.Context, event models.PaymentVoidEvent) error
+}
+
+// Orchestrator runs the checkout saga of every order: it reserves the stock,
+// requests the payment, waits for its outcome and completes the order, or
+// voids the payment, releases the stock and cancels the order when a step
+// fails, the payment fails or times out, or the user cancels.
+//
+// Steps run right after what made them due, a checkout or a payment event,
+// and the worker started with Start picks up every saga whose deadline
+// passed, e.g. after a failed attempt or when the instance running it died.
+type Orchestrator struct {
+	OrderService OrderService
+	Producer     Producer
+
+	cfg     config.SagaConfig
+	poller  *worker.Poller
+	backoff worker.Backoff
+}
+
+// NewOrchestrator new orchestrator by given cfg of config.SagaConfig, OrderService, and Producer.
+//
+// It returns pointer of Orchestrator.
+func NewOrchestrator(cfg config.SagaConfig, orderService OrderService, kafkaProducer Producer) *Orchestrator {
+	if cfg.Lease <= 0 {
+		cfg.Lease = defaultLease
+	}
+
+	if cfg.PaymentTimeout <= 0 {
+		cfg.PaymentTimeout = defaultPaymentTimeout
+	}
+
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaultInitialBackoff
+	}
+
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+
+	o := &Orchestrator{
+		OrderService: orderService,
+		Producer:     kafkaProducer,
+		cfg:          cfg,
+		backoff:      worker.Backoff{Initial: cfg.InitialBackoff, Max: cfg.MaxBackoff},
+	}
+
+	o.poller = worker.NewPoller("SAGA", cfg.PollInterval, cfg.BatchSize, o.runBatch)
+	return o
+}
+
+// Start start.
+//
+// It runs the due sagas until ctx is done.
+func (o *Orchestrator) Start(ctx context.Context) {
+	o.poller.Start(ctx)
+}
+
+// Running running.
+//
+// It returns true while the worker loop is running sagas.
+// Otherwise, false will be returned.
+func (o *Orchestrator) Running() bool {
+	return o.poller.Running()
+}
+
+// Resume resume by given orderID.
+//
+// The saga of the order runs when it is due, a saga claimed by a worker or
+// waiting for its next attempt or its payment is left alone.
+//
+// It returns nil error when successful.
+// Otherwise, ErrSagaNotFound, or error will be returned.
+func (o *Orchestrator) Resume(ctx context.Context, orderID int64) error {
+	return o.transition(ctx, orderID, func(e *execution) bool {
+		return e.active() && !e.saga.DeadlineTime.After(time.Now())
+	})
+}
+
+// PaymentSucceeded payment succeeded by given orderID.
+//
+// A saga waiting for the payment completes the order. A payment arriving
+// after the saga started compensating, e.g. after it timed out, is voided.
+//
+// It returns nil error when successful.
+// Otherwise, ErrSagaNotFound, or error will be returned.
+func (o *Orchestrator) PaymentSucceeded(ctx context.Context, orderID int64) error {
+	return o.transition(ctx, orderID, func(e *execution) bool {
+		if e.paymentOutcome() == constant.SagaStepSucceeded {
+			// redelivered
+			return false
+		}
+
+		e.record(constant.SagaStepAwaitPayment, constant.SagaStepSucceeded, "")
+
+		switch e.saga.State {
+		case constant.SagaStateCompensating, constant.SagaStateCompensated:
+			if e.saga.State == constant.SagaStateCompensating && e.saga.Step == constant.SagaStepVoidPayment {
+				break
+			}
+
+			e.reopen(constant.SagaStepVoidPayment)
+		}
+
+		return true
+	})
+}
+
+// PaymentFailed payment failed by given orderID, and status reported by the payment service.
+//
+// A running saga releases the stock and cancels the order, the failed
+// payment is not voided.
+//
+// It returns nil error when successful.
+// Otherwise, ErrSagaNotFound, or error will be returned.
+func (o *Orchestrator) PaymentFailed(ctx context.Context, orderID int64, status string) error {
+	return o.transition(ctx, orderID, func(e *execution) bool {
+		if e.paymentOutcome() == constant.SagaStepFailed {
+			// redelivered
+			return false
+		}
+
+		reason := "payment failed"
+		if status != "" {
+			reason += ": " + status
+		}
+
+		// the saga moves on once it reaches await_payment
+		e.record(constant.SagaStepAwaitPayment, constant.SagaStepFailed, reason)
+		return true
+	})
+}
+
+// OrderCancelled order cancelled by given orderID, and reason given by the user.
+//
+// The order was already cancelled by the caller. A running saga stops at its
+// current step, voids the payment and releases the stock.
+//
+// It returns nil error when successful.
+// Otherwise, ErrSagaNotFound, or error will be returned.
+func (o *Orchestrator) OrderCancelled(ctx context.Context, orderID int64, reason string) error {
+	return o.transition(ctx, orderID, func(e *execution) bool {
+		if e.saga.State != constant.SagaStateRunning {
+			return false
+		}
+
+		cause := "order cancelled by the user"
+		if reason = strings.TrimSpace(reason); reason != "" {
+			cause += ": " + reason
+		}
+
+		e.record(e.saga.Step, constant.SagaStepCancelled, cause)
+		e.compensate(cause)
+		return true
+	})
+}
+
+// runBatch run batch by given limit of the sagas to claim.
+//
+// The claimed sagas run concurrently, each one moves its deadline past the
+// lease again before every step.
+//
+// It returns int of the claimed sagas, and nil error when successful.
+// Otherwise, 0, and error will be returned.
+func (o *Orchestrator) runBatch(ctx context.Context, limit int) (int, error) {
+	sagas, err := o.OrderService.ClaimCheckoutSagas(ctx, limit, o.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, saga := range sagas {
+		e, err := newExecution(saga)
+		if err != nil {
+			// left claimed, the admin view shows it overdue
+			log.Logger.Errorf("[SAGA] Error Decode Saga of Order #%d: %v", saga.OrderID, err)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			o.run(ctx, e)
+		}()
+	}
+	wg.Wait()
+
+	return len(sagas), nil
+}
+
+// transition transition by given orderID, and apply.
+//
+// The saga of the order is read, changed by apply, claimed and written back,
+// and then run when it has not finished. apply returns false to leave the
+// saga unchanged. A saga written concurrently is read and applied again.
+//
+// It returns nil error when successful.
+// Otherwise, ErrSagaNotFound, or error will be returned.
+func (o *Orchestrator) transition(ctx context.Context, orderID int64, apply func(e *execution) bool) error {
+	for attempt := 0; attempt < maxConflictRetries; attempt++ {
+		saga, err := o.OrderService.GetCheckoutSagaByOrderID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		if saga.ID == 0 {
+			return ErrSagaNotFound
+		}
+
+		e, err := newExecution(saga)
+		if err != nil {
+			return err
+		}
+
+		if !apply(e) {
+			return nil
+		}
+
+		if e.active() {
+			e.saga.DeadlineTime = time.Now().Add(o.cfg.Lease)
+		}
+
+		saved, err := o.save(ctx, e)
+		if err != nil {
+			return err
+		}
+
+		if !saved {
+			continue
+		}
+
+		o.run(ctx, e)
+		return nil
+	}
+
+	return errSagaConflict
+}
+
+// run run by given e pointer of execution.
+//
+// The steps of the claimed saga run one after the other, every result is
+// written before the next step starts. It stops when the saga finished,
+// waits for a retry or its payment, or was written concurrently.
+func (o *Orchestrator) run(ctx context.Context, e *execution) {
+	ctx = log.ContextWithOrderID(ctx, e.saga.OrderID)
+
+	for e.active() {
+		recorded := len(e.steps)
+		step := e.saga.Step
+
+		more := o.advance(ctx, e)
+		if more {
+			e.saga.DeadlineTime = time.Now().Add(o.cfg.Lease)
+		}
+
+		saved, err := o.save(ctx, e)
+		if err != nil {
+			// run again once the claim runs out
+			log.WithContext(ctx).Errorf("[SAGA] Error Save Saga after %s: %v", step, err)
+			return
+		}
+
+		if !saved {
+			log.WithContext(ctx).Warnf("[SAGA] Saga changed concurrently during %s, its latest writer continues", step)
+			o.keepEffects(ctx, e.saga.OrderID, e.steps[recorded:])
+			return
+		}
+
+		if !more {
+			return
+		}
+	}
+}
+
+// keepEffects keep effects by given orderID, and lost steps that were recorded by a write that lost to a concurrent one.
+//
+// The steps already happened, so a reserved stock or requested payment is
+// added to the saga, and compensated again when the saga already compensated.
+func (o *Orchestrator) keepEffects(ctx context.Context, orderID int64, lost []models.SagaStep) {
+	for _, step := range lost {
+		compensation, ok := compensations[step.Name]
+		if !ok || step.Status != constant.SagaStepSucceeded {
+			continue
+		}
+
+		err := o.transition(ctx, orderID, func(e *execution) bool {
+			if e.done(step.Name) {
+				return false
+			}
+
+			e.steps = append(e.steps, step)
+
+			switch {
+			case e.saga.State == constant.SagaStateRunning && e.saga.Step == step.Name:
+				e.next()
+			case e.saga.State == constant.SagaStateCompensated,
+				e.saga.State == constant.SagaStateCompensating && e.done(compensation):
+				e.reopen(compensation)
+			}
+
+			return true
+		})
+		if err != nil {
+			log.WithContext(ctx).Errorf("[SAGA] Error Keep %s of Order #%d: %v", step.Name, orderID, err)
+		}
+	}
+}
+
+// save save by given e pointer of execution.
+//
+// It returns true, and nil error when the saga still had its version and was written.
+// Otherwise, false, and error if any will be returned.
+func (o *Orchestrator) save(ctx context.Context, e *execution) (bool, error) {
+	err := e.encode()
+	if err != nil {
+		return false, err
+	}
+
+	saved, err := o.OrderService.UpdateCheckoutSaga(ctx, e.saga)
+	if err != nil || !saved {
+		return false, err
+	}
+
+	e.saga.Version++
+	return true, nil
+}
@@ -0,0 +1,436 @@
+package saga
+
+import (
+	// golang package
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/metrics"
+	"orderfc/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+// errNotRetryable fails a step right away, retrying cannot change its outcome.
+var errNotRetryable = errors.New("not retryable")
+
+var (
+	forwardSteps = []string{
+		constant.SagaStepReserveStock,
+		constant.SagaStepRequestPayment,
+		constant.SagaStepAwaitPayment,
+		constant.SagaStepCompleteOrder,
+	}
+
+	compensationSteps = []string{
+		constant.SagaStepVoidPayment,
+		constant.SagaStepReleaseStock,
+		constant.SagaStepCancelOrder,
+	}
+
+	// the steps whose effect outlives the order, by the compensation undoing it
+	compensations = map[string]string{
+		constant.SagaStepReserveStock:   constant.SagaStepReleaseStock,
+		constant.SagaStepRequestPayment: constant.SagaStepVoidPayment,
+	}
+)
+
+// execution is a saga being run, with its decoded step log.
+type execution struct {
+	saga  models.CheckoutSaga
+	steps []models.SagaStep
+}
+
+// newExecution new execution by given saga of models.CheckoutSaga.
+//
+// It returns pointer of execution, and nil error when successful.
+// Otherwise, nil pointer of execution, and error will be returned.
+func newExecution(saga models.CheckoutSaga) (*execution, error) {
+	e := &execution{saga: saga}
+	if saga.Steps == "" {
+		return e, nil
+	}
+
+	err := json.Unmarshal([]byte(saga.Steps), &e.steps)
+	if err != nil {
+		return nil, fmt.Errorf("decode steps of saga #%d: %w", saga.ID, err)
+	}
+
+	return e, nil
+}
+
+// encode encode.
+//
+// It returns nil error when the step log was written to the saga.
+// Otherwise, error will be returned.
+func (e *execution) encode() error {
+	steps, err := json.Marshal(e.steps)
+	if err != nil {
+		return err
+	}
+
+	e.saga.Steps = string(steps)
+	return nil
+}
+
+// active active.
+//
+// It returns true while the saga has steps or compensations left to run.
+// Otherwise, false will be returned.
+func (e *execution) active() bool {
+	return e.saga.State == constant.SagaStateRunning || e.saga.State == constant.SagaStateCompensating
+}
+
+// record record by given name of the step, status, and message.
+func (e *execution) record(name string, status string, message string) {
+	e.steps = append(e.steps, models.SagaStep{
+		Name:     name,
+		Status:   status,
+		Attempts: e.saga.Attempts,
+		Error:    message,
+		Time:     time.Now(),
+	})
+
+	metrics.SagaStepsTotal.WithLabelValues(name, status).Inc()
+}
+
+// done done by given name of the step.
+//
+// It returns true when the step succeeded or was skipped.
+// Otherwise, false will be returned.
+func (e *execution) done(name string) bool {
+	return slices.ContainsFunc(e.steps, func(step models.SagaStep) bool {
+		return step.Name == name && (step.Status == constant.SagaStepSucceeded || step.Status == constant.SagaStepSkipped)
+	})
+}
+
+// paymentOutcome payment outcome.
+//
+// It returns SagaStepSucceeded or SagaStepFailed as last reported by the payment service.
+// Otherwise, empty string will be returned.
+func (e *execution) paymentOutcome() string {
+	for index := len(e.steps) - 1; index >= 0; index-- {
+		step := e.steps[index]
+		if step.Name != constant.SagaStepAwaitPayment {
+			continue
+		}
+
+		if step.Status == constant.SagaStepSucceeded || step.Status == constant.SagaStepFailed {
+			return step.Status
+		}
+	}
+
+	return ""
+}
+
+// paymentDue payment due by given timeout.
+//
+// It returns time.Time the payment has to arrive by, counted from when it was requested.
+func (e *execution) paymentDue(timeout time.Duration) time.Time {
+	requested := e.saga.CreateTime
+	for _, step := range e.steps {
+		if step.Name == constant.SagaStepRequestPayment && step.Status == constant.SagaStepSucceeded {
+			requested = step.Time
+		}
+	}
+
+	return requested.Add(timeout)
+}
+
+// next next.
+//
+// It moves the saga to the step after the current one, skipping the
+// compensations already done, or to its final state after the last one.
+func (e *execution) next() {
+	e.saga.Attempts = 0
+	e.saga.LastError = ""
+
+	if e.saga.State == constant.SagaStateRunning {
+		index := slices.Index(forwardSteps, e.saga.Step)
+		if index >= 0 && index+1 < len(forwardSteps) {
+			e.saga.Step = forwardSteps[index+1]
+			return
+		}
+
+		e.finish(constant.SagaStateCompleted)
+		return
+	}
+
+	index := slices.Index(compensationSteps, e.saga.Step)
+	for _, step := range compensationSteps[index+1:] {
+		if !e.done(step) {
+			e.saga.Step = step
+			return
+		}
+	}
+
+	e.finish(constant.SagaStateCompensated)
+}
+
+// compensate compensate by given reason.
+//
+// It stops the forward steps and starts the first compensation not done yet.
+func (e *execution) compensate(reason string) {
+	e.saga.State = constant.SagaStateCompensating
+	e.saga.Reason = reason
+	// before the first compensation, next starts with it
+	e.saga.Step = ""
+	e.next()
+}
+
+// reopen reopen by given name of the compensation.
+//
+// It runs the compensation again, e.g. to void a payment that arrived after
+// the saga compensated.
+func (e *execution) reopen(name string) {
+	e.saga.State = constant.SagaStateCompensating
+	e.saga.Step = name
+	e.saga.Attempts = 0
+	e.saga.LastError = ""
+}
+
+// finish finish by given state.
+func (e *execution) finish(state string) {
+	e.saga.State = state
+	metrics.SagasFinishedTotal.WithLabelValues(state).Inc()
+}
+
+// advance advance by given e pointer of execution.
+//
+// It runs the current step and moves the saga on by its result.
+//
+// It returns true when the next step is due right away.
+// Otherwise, false will be returned.
+func (o *Orchestrator) advance(ctx context.Context, e *execution) bool {
+	if e.saga.State == constant.SagaStateRunning && e.saga.Step == constant.SagaStepAwaitPayment {
+		return o.awaitPayment(ctx, e)
+	}
+
+	status, err := o.execute(ctx, e)
+	if err != nil {
+		return o.fail(ctx, e, err)
+	}
+
+	e.record(e.saga.Step, status, "")
+	e.next()
+	return e.active()
+}
+
+// awaitPayment await payment by given e pointer of execution.
+//
+// The payment outcome is recorded by the payment events, a saga without one
+// waits for it until the payment timeout.
+//
+// It returns true when the saga moved on.
+// Otherwise, false will be returned.
+func (o *Orchestrator) awaitPayment(ctx context.Context, e *execution) bool {
+	switch e.paymentOutcome() {
+	case constant.SagaStepSucceeded:
+		e.next()
+		return true
+	case constant.SagaStepFailed:
+		e.compensate("payment failed")
+		return true
+	}
+
+	due := e.paymentDue(o.cfg.PaymentTimeout)
+	if time.Now().Before(due) {
+		e.saga.DeadlineTime = due
+		return false
+	}
+
+	log.WithContext(ctx).Warnf("[SAGA] No payment within %s", o.cfg.PaymentTimeout)
+	e.record(constant.SagaStepAwaitPayment, constant.SagaStepTimedOut, fmt.Sprintf("no payment within %s", o.cfg.PaymentTimeout))
+	e.compensate("payment timed out")
+	return true
+}
+
+// fail fail by given e pointer of execution, and err of the attempt.
+//
+// A failed attempt is retried after the backoff. A step out of attempts
+// stops the forward steps and starts compensating, a compensation out of
+// attempts fails the saga, which then needs an operator.
+//
+// It returns true when the next step is due right away.
+// Otherwise, false will be returned.
+func (o *Orchestrator) fail(ctx context.Context, e *execution, err error) bool {
+	e.saga.Attempts++
+	e.saga.LastError = err.Error()
+
+	if e.saga.Attempts < o.cfg.MaxAttempts && !errors.Is(err, errNotRetryable) {
+		e.saga.DeadlineTime = time.Now().Add(o.backoff.Delay(e.saga.Attempts))
+		log.WithContext(ctx).Warnf("[SAGA] Step %s attempt %d: %v, retry at %s", e.saga.Step, e.saga.Attempts, err, e.saga.DeadlineTime.Format(time.RFC3339))
+		return false
+	}
+
+	log.WithContext(ctx).Errorf("[SAGA] Step %s failed after %d attempt(s): %v", e.saga.Step, e.saga.Attempts, err)
+	e.record(e.saga.Step, constant.SagaStepFailed, err.Error())
+
+	if e.saga.State == constant.SagaStateRunning {
+		e.compensate(e.saga.Step + " failed")
+		return true
+	}
+
+	e.finish(constant.SagaStateFailed)
+	return false
+}
+
+// execute execute by given e pointer of execution.
+//
+// Every step may run more than once, e.g. when its result could not be
+// written, so the receivers of its events have to handle them idempotently.
+//
+// It returns string of the step status, and nil error when successful.
+// Otherwise, empty string, and error will be returned.
+func (o *Orchestrator) execute(ctx context.Context, e *execution) (string, error) {
+	orderID := e.saga.OrderID
+
+	switch e.saga.Step {
+	case constant.SagaStepReserveStock:
+		event, err := o.stockEvent(ctx, orderID)
+		if err != nil {
+			return "", err
+		}
+
+		return constant.SagaStepSucceeded, o.Producer.PublishProductStockUpdate(ctx, event)
+	case constant.SagaStepRequestPayment:
+		order, err := o.getOrder(ctx, orderID)
+		if err != nil {
+			return "", err
+		}
+
+		return constant.SagaStepSucceeded, o.Producer.PublishOrderCreated(ctx, models.OrderCreatedEvent{
+			OrderID:         order.ID,
+			UserID:          order.UserID,
+			TotalAmount:     order.Amount,
+			PaymentMethod:   order.PaymentMethod,
+			ShippingAddress: order.ShippingAddress,
+		})
+	case constant.SagaStepCompleteOrder:
+		return o.transitionOrder(ctx, orderID, constant.OrderStatusCompleted, "payment succeeded", constant.OrderStatusProcessing)
+	case constant.SagaStepVoidPayment:
+		outcome := e.paymentOutcome()
+		if outcome == constant.SagaStepFailed || (outcome == "" && !e.done(constant.SagaStepRequestPayment)) {
+			return constant.SagaStepSkipped, nil
+		}
+
+		return constant.SagaStepSucceeded, o.Producer.PublishPaymentVoid(ctx, models.PaymentVoidEvent{
+			OrderID:   orderID,
+			Reason:    e.saga.Reason,
+			EventTime: time.Now(),
+		})
+	case constant.SagaStepReleaseStock:
+		if !e.done(constant.SagaStepReserveStock) {
+			return constant.SagaStepSkipped, nil
+		}
+
+		event, err := o.stockEvent(ctx, orderID)
+		if err != nil {
+			return "", err
+		}
+
+		return constant.SagaStepSucceeded, o.Producer.PublishProductStockRollback(ctx, event)
+	case constant.SagaStepCancelOrder:
+		return o.transitionOrder(ctx, orderID, constant.OrderStatusCancelled, e.saga.Reason)
+	default:
+		return "", fmt.Errorf("unknown step %q: %w", e.saga.Step, errNotRetryable)
+	}
+}
+
+// transitionOrder transition order by given orderID, status, reason, and passed statuses the order may already have moved on to.
+//
+// The order only moves while it is still created. An order that already has
+// the status or one of the passed statuses counts as moved, any other status
+// cannot be changed by retrying.
+//
+// It returns string of the step status, and nil error when successful.
+// Otherwise, empty string, and error will be returned.
+func (o *Orchestrator) transitionOrder(ctx context.Context, orderID int64, status int, reason string, passed ...int) (string, error) {
+	order, err := o.getOrder(ctx, orderID)
+	if err != nil {
+		return "", err
+	}
+
+	if order.Status == constant.OrderStatusCreated {
+		entry := models.StatusHistory{
+			Status:    strings.ToLower(constant.OrderStatusTranslated[status]),
+			Timestamp: time.Now().Format(time.RFC3339Nano),
+			Reason:    reason,
+		}
+
+		updated, err := o.OrderService.TransitionOrderStatus(ctx, order, constant.OrderStatusCreated, status, entry)
+		if err != nil {
+			return "", err
+		}
+
+		if updated {
+			return constant.SagaStepSucceeded, nil
+		}
+
+		// changed since it was read, the next attempt sees the new status
+		return "", errors.New("order status changed concurrently")
+	}
+
+	if order.Status == status || slices.Contains(passed, order.Status) {
+		return constant.SagaStepSucceeded, nil
+	}
+
+	return "", fmt.Errorf("order is %s: %w", strings.ToLower(constant.OrderStatusTranslated[order.Status]), errNotRetryable)
+}
+
+// getOrder get order by given orderID.
+//
+// The order is read from the primary, like the saga, since the saga usually
+// runs right after the order was written. An order not found yet is retried
+// like any other failed attempt.
+//
+// It returns models.Order, and nil error when successful.
+// Otherwise, empty models.Order, and error will be returned.
+func (o *Orchestrator) getOrder(ctx context.Context, orderID int64) (models.Order, error) {
+	order, err := o.OrderService.GetPrimaryOrderInfoByOrderID(ctx, orderID)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	if order.ID == 0 {
+		return models.Order{}, fmt.Errorf("order #%d not found", orderID)
+	}
+
+	return order, nil
+}
+
+// stockEvent stock event by given orderID.
+//
+// It returns models.ProductStockUpdateEvent of the products of the order, and nil error when successful.
+// Otherwise, empty models.ProductStockUpdateEvent, and error will be returned.
+func (o *Orchestrator) stockEvent(ctx context.Context, orderID int64) (models.ProductStockUpdateEvent, error) {
+	order, err := o.getOrder(ctx, orderID)
+	if err != nil {
+		return models.ProductStockUpdateEvent{}, err
+	}
+
+	orderDetail, err := o.OrderService.GetPrimaryOrderDetailByOrderDetailID(ctx, order.OrderDetailID)
+	if err != nil {
+		return models.ProductStockUpdateEvent{}, err
+	}
+
+	if orderDetail.ID == 0 {
+		return models.ProductStockUpdateEvent{}, fmt.Errorf("order detail #%d not found", order.OrderDetailID)
+	}
+
+	var products []models.CheckoutItem
+	err = json.Unmarshal([]byte(orderDetail.Products), &products)
+	if err != nil {
+		return models.ProductStockUpdateEvent{}, fmt.Errorf("decode products: %v: %w", err, errNotRetryable)
+	}
+
+	return models.ProductStockUpdateEvent{
+		OrderID:   orderID,
+		Products:  models.ConvertCheckoutItemToProductItems(products),
+		EventTime: time.Now(),
+	}, nil
+}
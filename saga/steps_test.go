@@ -0,0 +1,671 @@
+package saga
+
+import (
+	// golang package
+	"context"
+	"encoding/json"
+	"orderfc/config"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/log"
+	"orderfc/models"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+const testOrderID = 7
+
+func TestMain(m *testing.M) {
+	// the retries and lost writes are logged as warnings
+	log.SetupLogger(config.LogConfig{Level: "error"})
+	os.Exit(m.Run())
+}
+
+// fakeOrderService keeps one saga and its order in memory, writing the saga
+// only while the caller still has its version, like the repository.
+type fakeOrderService struct {
+	saga   models.CheckoutSaga
+	order  models.Order
+	detail models.OrderDetail
+
+	// runs once before the next saga write, e.g. to write it concurrently
+	beforeUpdate func(f *fakeOrderService)
+}
+
+func (f *fakeOrderService) GetCheckoutSagaByOrderID(ctx context.Context, orderID int64) (models.CheckoutSaga, error) {
+	return f.saga, nil
+}
+
+func (f *fakeOrderService) ClaimCheckoutSagas(ctx context.Context, limit int, lease time.Duration) ([]models.CheckoutSaga, error) {
+	return []models.CheckoutSaga{f.saga}, nil
+}
+
+func (f *fakeOrderService) UpdateCheckoutSaga(ctx context.Context, saga models.CheckoutSaga) (bool, error) {
+	if hook := f.beforeUpdate; hook != nil {
+		f.beforeUpdate = nil
+		hook(f)
+	}
+
+	if saga.Version != f.saga.Version {
+		return false, nil
+	}
+
+	saga.Version++
+	f.saga = saga
+	return true, nil
+}
+
+func (f *fakeOrderService) GetPrimaryOrderInfoByOrderID(ctx context.Context, orderID int64) (models.Order, error) {
+	return f.order, nil
+}
+
+func (f *fakeOrderService) GetPrimaryOrderDetailByOrderDetailID(ctx context.Context, orderDetailID int64) (models.OrderDetail, error) {
+	return f.detail, nil
+}
+
+func (f *fakeOrderService) TransitionOrderStatus(ctx context.Context, order models.Order, from int, to int, entry models.StatusHistory) (bool, error) {
+	if f.order.Status != from {
+		return false, nil
+	}
+
+	f.order.Status = to
+	return true, nil
+}
+
+// fakeProducer records the topics of the published events.
+type fakeProducer struct {
+	published []string
+}
+
+func (p *fakeProducer) PublishOrderCreated(ctx context.Context, event models.OrderCreatedEvent) error {
+	p.published = append(p.published, "order_created")
+	return nil
+}
+
+func (p *fakeProducer) PublishProductStockUpdate(ctx context.Context, event models.ProductStockUpdateEvent) error {
+	p.published = append(p.published, "stock_update")
+	return nil
+}
+
+func (p *fakeProducer) PublishProductStockRollback(ctx context.Context, event models.ProductStockUpdateEvent) error {
+	p.published = append(p.published, "stock_rollback")
+	return nil
+}
+
+func (p *fakeProducer) PublishPaymentVoid(ctx context.Context, event models.PaymentVoidEvent) error {
+	p.published = append(p.published, "payment_void")
+	return nil
+}
+
+// newTestOrchestrator new test orchestrator by given saga of models.CheckoutSaga, and order status.
+//
+// It returns pointer of Orchestrator, pointer of fakeOrderService holding
+// saga and its order, and pointer of fakeProducer.
+func newTestOrchestrator(t *testing.T, saga models.CheckoutSaga, status int) (*Orchestrator, *fakeOrderService, *fakeProducer) {
+	t.Helper()
+
+	products, err := json.Marshal([]models.CheckoutItem{{ProductID: 1, Quantity: 2, Price: 10}})
+	if err != nil {
+		t.Fatalf("json.Marshal() got error %v", err)
+	}
+
+	orders := &fakeOrderService{
+		saga:   saga,
+		order:  models.Order{ID: testOrderID, UserID: 1, Amount: 20, OrderDetailID: 3, Status: status},
+		detail: models.OrderDetail{ID: 3, Products: string(products)},
+	}
+	producer := &fakeProducer{}
+
+	return NewOrchestrator(config.SagaConfig{}, orders, producer), orders, producer
+}
+
+// newTestSaga new test saga by given state, step, and steps already recorded.
+//
+// It returns models.CheckoutSaga of testOrderID.
+func newTestSaga(t *testing.T, state string, step string, steps ...models.SagaStep) models.CheckoutSaga {
+	t.Helper()
+
+	e := &execution{
+		saga: models.CheckoutSaga{
+			ID:         1,
+			OrderID:    testOrderID,
+			State:      state,
+			Step:       step,
+			Version:    1,
+			CreateTime: time.Now().Add(-time.Hour),
+		},
+		steps: steps,
+	}
+
+	err := e.encode()
+	if err != nil {
+		t.Fatalf("encode() got error %v", err)
+	}
+
+	return e.saga
+}
+
+// sagaStep saga step by given name, and status.
+//
+// It returns models.SagaStep recorded now.
+func sagaStep(name string, status string) models.SagaStep {
+	return models.SagaStep{Name: name, Status: status, Time: time.Now()}
+}
+
+// stepLog step log by given steps.
+//
+// It returns slice of "name status" of every step, for comparing step logs.
+func stepLog(steps []models.SagaStep) []string {
+	names := make([]string, 0, len(steps))
+	for _, step := range steps {
+		names = append(names, step.Name+" "+step.Status)
+	}
+
+	return names
+}
+
+// decodedSteps decoded steps by given saga of models.CheckoutSaga.
+//
+// It returns slice of "name status" of the step log written to saga.
+func decodedSteps(t *testing.T, saga models.CheckoutSaga) []string {
+	t.Helper()
+
+	e, err := newExecution(saga)
+	if err != nil {
+		t.Fatalf("newExecution() got error %v", err)
+	}
+
+	return stepLog(e.steps)
+}
+
+func TestExecutionNext(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     string
+		step      string
+		steps     []models.SagaStep
+		wantState string
+		wantStep  string
+	}{
+		{
+			name:      "forward step",
+			state:     constant.SagaStateRunning,
+			step:      constant.SagaStepReserveStock,
+			wantState: constant.SagaStateRunning,
+			wantStep:  constant.SagaStepRequestPayment,
+		},
+		{
+			name:      "last forward step completes",
+			state:     constant.SagaStateRunning,
+			step:      constant.SagaStepCompleteOrder,
+			wantState: constant.SagaStateCompleted,
+			wantStep:  constant.SagaStepCompleteOrder,
+		},
+		{
+			name:      "compensation",
+			state:     constant.SagaStateCompensating,
+			step:      constant.SagaStepVoidPayment,
+			wantState: constant.SagaStateCompensating,
+			wantStep:  constant.SagaStepReleaseStock,
+		},
+		{
+			name:      "compensation already done is skipped",
+			state:     constant.SagaStateCompensating,
+			step:      constant.SagaStepVoidPayment,
+			steps:     []models.SagaStep{sagaStep(constant.SagaStepReleaseStock, constant.SagaStepSucceeded)},
+			wantState: constant.SagaStateCompensating,
+			wantStep:  constant.SagaStepCancelOrder,
+		},
+		{
+			name:      "failed compensation is not done",
+			state:     constant.SagaStateCompensating,
+			step:      constant.SagaStepVoidPayment,
+			steps:     []models.SagaStep{sagaStep(constant.SagaStepReleaseStock, constant.SagaStepFailed)},
+			wantState: constant.SagaStateCompensating,
+			wantStep:  constant.SagaStepReleaseStock,
+		},
+		{
+			name:      "last compensation compensates",
+			state:     constant.SagaStateCompensating,
+			step:      constant.SagaStepCancelOrder,
+			wantState: constant.SagaStateCompensated,
+			wantStep:  constant.SagaStepCancelOrder,
+		},
+		{
+			name:  "reopened compensation skips the ones done",
+			state: constant.SagaStateCompensating,
+			step:  constant.SagaStepVoidPayment,
+			steps: []models.SagaStep{
+				sagaStep(constant.SagaStepReleaseStock, constant.SagaStepSkipped),
+				sagaStep(constant.SagaStepCancelOrder, constant.SagaStepSucceeded),
+			},
+			wantState: constant.SagaStateCompensated,
+			wantStep:  constant.SagaStepVoidPayment,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := &execution{
+				saga:  models.CheckoutSaga{State: test.state, Step: test.step, Attempts: 2, LastError: "timeout"},
+				steps: test.steps,
+			}
+			e.next()
+
+			if e.saga.State != test.wantState || e.saga.Step != test.wantStep {
+				t.Errorf("next() moved to %s %s, want %s %s", e.saga.State, e.saga.Step, test.wantState, test.wantStep)
+			}
+
+			if e.saga.Attempts != 0 || e.saga.LastError != "" {
+				t.Errorf("attempts = %d, last error = %q, want them reset", e.saga.Attempts, e.saga.LastError)
+			}
+		})
+	}
+}
+
+func TestExecutionCompensate(t *testing.T) {
+	tests := []struct {
+		name      string
+		steps     []models.SagaStep
+		wantState string
+		wantStep  string
+	}{
+		{
+			name:      "starts with the first compensation",
+			wantState: constant.SagaStateCompensating,
+			wantStep:  constant.SagaStepVoidPayment,
+		},
+		{
+			name:      "skips the compensations done",
+			steps:     []models.SagaStep{sagaStep(constant.SagaStepVoidPayment, constant.SagaStepSkipped)},
+			wantState: constant.SagaStateCompensating,
+			wantStep:  constant.SagaStepReleaseStock,
+		},
+		{
+			name: "every compensation done",
+			steps: []models.SagaStep{
+				sagaStep(constant.SagaStepVoidPayment, constant.SagaStepSkipped),
+				sagaStep(constant.SagaStepReleaseStock, constant.SagaStepSucceeded),
+				sagaStep(constant.SagaStepCancelOrder, constant.SagaStepSucceeded),
+			},
+			wantState: constant.SagaStateCompensated,
+			wantStep:  "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := &execution{
+				saga:  models.CheckoutSaga{State: constant.SagaStateRunning, Step: constant.SagaStepRequestPayment},
+				steps: test.steps,
+			}
+			e.compensate("request_payment failed")
+
+			if e.saga.State != test.wantState || e.saga.Step != test.wantStep {
+				t.Errorf("compensate() moved to %s %q, want %s %q", e.saga.State, e.saga.Step, test.wantState, test.wantStep)
+			}
+
+			if e.saga.Reason != "request_payment failed" {
+				t.Errorf("reason = %q, want %q", e.saga.Reason, "request_payment failed")
+			}
+		})
+	}
+}
+
+func TestExecutionPaymentOutcome(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []models.SagaStep
+		want  string
+	}{
+		{
+			name: "no payment event",
+			steps: []models.SagaStep{
+				sagaStep(constant.SagaStepRequestPayment, constant.SagaStepSucceeded),
+			},
+			want: "",
+		},
+		{
+			name: "succeeded",
+			steps: []models.SagaStep{
+				sagaStep(constant.SagaStepAwaitPayment, constant.SagaStepSucceeded),
+			},
+			want: constant.SagaStepSucceeded,
+		},
+		{
+			name: "timeout is not an outcome",
+			steps: []models.SagaStep{
+				sagaStep(constant.SagaStepAwaitPayment, constant.SagaStepTimedOut),
+			},
+			want: "",
+		},
+		{
+			name: "late payment after a timeout",
+			steps: []models.SagaStep{
+				sagaStep(constant.SagaStepAwaitPayment, constant.SagaStepTimedOut),
+				sagaStep(constant.SagaStepAwaitPayment, constant.SagaStepSucceeded),
+			},
+			want: constant.SagaStepSucceeded,
+		},
+		{
+			name: "last outcome wins",
+			steps: []models.SagaStep{
+				sagaStep(constant.SagaStepAwaitPayment, constant.SagaStepFailed),
+				sagaStep(constant.SagaStepAwaitPayment, constant.SagaStepSucceeded),
+				sagaStep(constant.SagaStepVoidPayment, constant.SagaStepSucceeded),
+			},
+			want: constant.SagaStepSucceeded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := &execution{steps: test.steps}
+			if got := e.paymentOutcome(); got != test.want {
+				t.Errorf("paymentOutcome() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestExecutionPaymentDue(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	requested := created.Add(time.Minute)
+	retried := created.Add(5 * time.Minute)
+
+	tests := []struct {
+		name  string
+		steps []models.SagaStep
+		want  time.Time
+	}{
+		{
+			name: "counted from the creation before the request",
+			want: created.Add(time.Hour),
+		},
+		{
+			name: "counted from the request",
+			steps: []models.SagaStep{
+				{Name: constant.SagaStepRequestPayment, Status: constant.SagaStepSucceeded, Time: requested},
+			},
+			want: requested.Add(time.Hour),
+		},
+		{
+			name: "failed request is not counted",
+			steps: []models.SagaStep{
+				{Name: constant.SagaStepRequestPayment, Status: constant.SagaStepFailed, Time: requested},
+			},
+			want: created.Add(time.Hour),
+		},
+		{
+			name: "latest request is counted",
+			steps: []models.SagaStep{
+				{Name: constant.SagaStepRequestPayment, Status: constant.SagaStepSucceeded, Time: requested},
+				{Name: constant.SagaStepRequestPayment, Status: constant.SagaStepSucceeded, Time: retried},
+			},
+			want: retried.Add(time.Hour),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := &execution{saga: models.CheckoutSaga{CreateTime: created}, steps: test.steps}
+			if got := e.paymentDue(time.Hour); !got.Equal(test.want) {
+				t.Errorf("paymentDue() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestExecuteSkipsCompensations(t *testing.T) {
+	requested := sagaStep(constant.SagaStepRequestPayment, constant.SagaStepSucceeded)
+	reserved := sagaStep(constant.SagaStepReserveStock, constant.SagaStepSucceeded)
+
+	tests := []struct {
+		name          string
+		step          string
+		steps         []models.SagaStep
+		wantStatus    string
+		wantPublished []string
+	}{
+		{
+			name:       "payment never requested is not voided",
+			step:       constant.SagaStepVoidPayment,
+			wantStatus: constant.SagaStepSkipped,
+		},
+		{
+			name:       "failed payment is not voided",
+			step:       constant.SagaStepVoidPayment,
+			steps:      []models.SagaStep{requested, sagaStep(constant.SagaStepAwaitPayment, constant.SagaStepFailed)},
+			wantStatus: constant.SagaStepSkipped,
+		},
+		{
+			name:          "requested payment without outcome is voided",
+			step:          constant.SagaStepVoidPayment,
+			steps:         []models.SagaStep{requested},
+			wantStatus:    constant.SagaStepSucceeded,
+			wantPublished: []string{"payment_void"},
+		},
+		{
+			name:          "succeeded payment is voided",
+			step:          constant.SagaStepVoidPayment,
+			steps:         []models.SagaStep{requested, sagaStep(constant.SagaStepAwaitPayment, constant.SagaStepSucceeded)},
+			wantStatus:    constant.SagaStepSucceeded,
+			wantPublished: []string{"payment_void"},
+		},
+		{
+			name:       "stock never reserved is not released",
+			step:       constant.SagaStepReleaseStock,
+			steps:      []models.SagaStep{sagaStep(constant.SagaStepReserveStock, constant.SagaStepFailed)},
+			wantStatus: constant.SagaStepSkipped,
+		},
+		{
+			name:          "reserved stock is released",
+			step:          constant.SagaStepReleaseStock,
+			steps:         []models.SagaStep{reserved},
+			wantStatus:    constant.SagaStepSucceeded,
+			wantPublished: []string{"stock_rollback"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			saga := newTestSaga(t, constant.SagaStateCompensating, test.step, test.steps...)
+			o, _, producer := newTestOrchestrator(t, saga, constant.OrderStatusCreated)
+
+			e, err := newExecution(saga)
+			if err != nil {
+				t.Fatalf("newExecution() got error %v", err)
+			}
+
+			status, err := o.execute(context.Background(), e)
+			if err != nil {
+				t.Fatalf("execute() got error %v", err)
+			}
+
+			if status != test.wantStatus {
+				t.Errorf("execute() = %s, want %s", status, test.wantStatus)
+			}
+
+			if !slices.Equal(producer.published, test.wantPublished) {
+				t.Errorf("published %q, want %q", producer.published, test.wantPublished)
+			}
+		})
+	}
+}
+
+func TestPaymentSucceededAfterCompensation(t *testing.T) {
+	saga := newTestSaga(t, constant.SagaStateCompensated, constant.SagaStepCancelOrder,
+		sagaStep(constant.SagaStepReserveStock, constant.SagaStepSucceeded),
+		sagaStep(constant.SagaStepRequestPayment, constant.SagaStepSucceeded),
+		sagaStep(constant.SagaStepAwaitPayment, constant.SagaStepTimedOut),
+		sagaStep(constant.SagaStepVoidPayment, constant.SagaStepSucceeded),
+		sagaStep(constant.SagaStepReleaseStock, constant.SagaStepSucceeded),
+		sagaStep(constant.SagaStepCancelOrder, constant.SagaStepSucceeded),
+	)
+	o, orders, producer := newTestOrchestrator(t, saga, constant.OrderStatusCancelled)
+
+	err := o.PaymentSucceeded(context.Background(), testOrderID)
+	if err != nil {
+		t.Fatalf("PaymentSucceeded() got error %v", err)
+	}
+
+	// the late payment is voided, the stock and the order stay compensated
+	if orders.saga.State != constant.SagaStateCompensated {
+		t.Errorf("state = %s, want %s", orders.saga.State, constant.SagaStateCompensated)
+	}
+
+	if !slices.Equal(producer.published, []string{"payment_void"}) {
+		t.Errorf("published %q, want one payment_void", producer.published)
+	}
+
+	wantSteps := []string{
+		"reserve_stock succeeded",
+		"request_payment succeeded",
+		"await_payment timed_out",
+		"void_payment succeeded",
+		"release_stock succeeded",
+		"cancel_order succeeded",
+		"await_payment succeeded",
+		"void_payment succeeded",
+	}
+	if got := decodedSteps(t, orders.saga); !slices.Equal(got, wantSteps) {
+		t.Errorf("steps = %q, want %q", got, wantSteps)
+	}
+
+	// redelivered
+	err = o.PaymentSucceeded(context.Background(), testOrderID)
+	if err != nil {
+		t.Fatalf("PaymentSucceeded() got error %v", err)
+	}
+
+	if len(producer.published) != 1 {
+		t.Errorf("published %q after the redelivery, want one payment_void", producer.published)
+	}
+}
+
+func TestOrderCancelledWhileAwaitingPayment(t *testing.T) {
+	saga := newTestSaga(t, constant.SagaStateRunning, constant.SagaStepAwaitPayment,
+		sagaStep(constant.SagaStepReserveStock, constant.SagaStepSucceeded),
+		sagaStep(constant.SagaStepRequestPayment, constant.SagaStepSucceeded),
+	)
+	// cancelled by the caller before the saga is told
+	o, orders, producer := newTestOrchestrator(t, saga, constant.OrderStatusCancelled)
+
+	err := o.OrderCancelled(context.Background(), testOrderID, " changed my mind ")
+	if err != nil {
+		t.Fatalf("OrderCancelled() got error %v", err)
+	}
+
+	if orders.saga.State != constant.SagaStateCompensated {
+		t.Errorf("state = %s, want %s", orders.saga.State, constant.SagaStateCompensated)
+	}
+
+	if orders.saga.Reason != "order cancelled by the user: changed my mind" {
+		t.Errorf("reason = %q", orders.saga.Reason)
+	}
+
+	if !slices.Equal(producer.published, []string{"payment_void", "stock_rollback"}) {
+		t.Errorf("published %q, want payment_void and stock_rollback", producer.published)
+	}
+
+	wantSteps := []string{
+		"reserve_stock succeeded",
+		"request_payment succeeded",
+		"await_payment cancelled",
+		"void_payment succeeded",
+		"release_stock succeeded",
+		"cancel_order succeeded",
+	}
+	if got := decodedSteps(t, orders.saga); !slices.Equal(got, wantSteps) {
+		t.Errorf("steps = %q, want %q", got, wantSteps)
+	}
+
+	// a payment arriving afterwards is voided again
+	err = o.PaymentSucceeded(context.Background(), testOrderID)
+	if err != nil {
+		t.Fatalf("PaymentSucceeded() got error %v", err)
+	}
+
+	if !slices.Equal(producer.published, []string{"payment_void", "stock_rollback", "payment_void"}) {
+		t.Errorf("published %q, want the late payment voided", producer.published)
+	}
+}
+
+func TestRunKeepsEffectsOfLostWrite(t *testing.T) {
+	saga := newTestSaga(t, constant.SagaStateRunning, constant.SagaStepReserveStock)
+	o, orders, producer := newTestOrchestrator(t, saga, constant.OrderStatusCreated)
+
+	// the user cancels while the stock is being reserved, and that write wins
+	orders.beforeUpdate = func(f *fakeOrderService) {
+		f.saga = newTestSaga(t, constant.SagaStateCompensated, constant.SagaStepCancelOrder,
+			sagaStep(constant.SagaStepReserveStock, constant.SagaStepCancelled),
+			sagaStep(constant.SagaStepVoidPayment, constant.SagaStepSkipped),
+			sagaStep(constant.SagaStepReleaseStock, constant.SagaStepSkipped),
+			sagaStep(constant.SagaStepCancelOrder, constant.SagaStepSucceeded),
+		)
+		f.saga.Version = saga.Version + 1
+		f.order.Status = constant.OrderStatusCancelled
+	}
+
+	e, err := newExecution(saga)
+	if err != nil {
+		t.Fatalf("newExecution() got error %v", err)
+	}
+
+	o.run(context.Background(), e)
+
+	if !slices.Equal(producer.published, []string{"stock_update", "stock_rollback"}) {
+		t.Errorf("published %q, want the reserved stock released", producer.published)
+	}
+
+	if orders.saga.State != constant.SagaStateCompensated {
+		t.Errorf("state = %s, want %s", orders.saga.State, constant.SagaStateCompensated)
+	}
+
+	wantSteps := []string{
+		"reserve_stock cancelled",
+		"void_payment skipped",
+		"release_stock skipped",
+		"cancel_order succeeded",
+		"reserve_stock succeeded",
+		"release_stock succeeded",
+	}
+	if got := decodedSteps(t, orders.saga); !slices.Equal(got, wantSteps) {
+		t.Errorf("steps = %q, want %q", got, wantSteps)
+	}
+}
+
+func TestRunKeepsEffectsOfLostWriteWhileRunning(t *testing.T) {
+	saga := newTestSaga(t, constant.SagaStateRunning, constant.SagaStepReserveStock)
+	o, orders, producer := newTestOrchestrator(t, saga, constant.OrderStatusCreated)
+
+	// another instance reclaimed the saga and is still reserving the stock
+	orders.beforeUpdate = func(f *fakeOrderService) {
+		f.saga.Attempts = 1
+		f.saga.LastError = "broker unavailable"
+		f.saga.DeadlineTime = time.Now().Add(time.Hour)
+		f.saga.Version++
+	}
+
+	e, err := newExecution(saga)
+	if err != nil {
+		t.Fatalf("newExecution() got error %v", err)
+	}
+
+	o.run(context.Background(), e)
+
+	// the reservation is kept and the saga moves on to wait for the payment
+	if orders.saga.State != constant.SagaStateRunning || orders.saga.Step != constant.SagaStepAwaitPayment {
+		t.Errorf("saga at %s %s, want %s %s", orders.saga.State, orders.saga.Step, constant.SagaStateRunning, constant.SagaStepAwaitPayment)
+	}
+
+	if !slices.Equal(producer.published, []string{"stock_update", "order_created"}) {
+		t.Errorf("published %q, want the stock reserved once and the payment requested", producer.published)
+	}
+
+	wantSteps := []string{"reserve_stock succeeded", "request_payment succeeded"}
+	if got := decodedSteps(t, orders.saga); !slices.Equal(got, wantSteps) {
+		t.Errorf("steps = %q, want %q", got, wantSteps)
+	}
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	// golang package
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const dialTimeout = 5 * time.Second
+
+var (
+	ErrPrivateAddress = errors.New("webhook target is not a public address")
+
+	// carrierGradeNAT is shared address space, reachable only inside the provider network
+	carrierGradeNAT = netip.MustParsePrefix("100.64.0.0/10")
+)
+
+// newDialer new dialer.
+//
+// The address is checked after the host name was resolved, right before the
+// connection is made, so a name resolving to an internal address, or
+// changing to one after the subscription was created, is refused too.
+//
+// It returns pointer of net.Dialer refusing connections to non public addresses.
+func newDialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout: dialTimeout,
+		Control: func(network string, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip, err := netip.ParseAddr(host)
+			if err != nil || !IsPublicAddress(ip) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+			}
+
+			return nil
+		},
+	}
+}
+
+// IsPublicAddress is public address by given ip of netip.Addr.
+//
+// It returns true when ip is a global unicast address outside the private,
+// shared and link-local ranges.
+// Otherwise, false will be returned.
+func IsPublicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+
+	return ip.IsValid() &&
+		ip.IsGlobalUnicast() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!carrierGradeNAT.Contains(ip)
+}
+
+// CheckURL check url by given rawURL of the subscription.
+//
+// Host names are resolved when delivering, so only addresses written in the
+// URL and localhost are refused here.
+//
+// It returns nil error when the URL may be subscribed.
+// Otherwise, ErrPrivateAddress, or error will be returned.
+func CheckURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err == nil && !IsPublicAddress(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	// golang package
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const (
+	HeaderEventID   = "X-Webhook-Id"
+	HeaderEventType = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign sign by given secret, timestamp, and body.
+//
+// The signature is the hex encoded HMAC-SHA256 of the timestamp header, a
+// dot and the raw body, keyed with the subscription secret. Receivers should
+// compare it in constant time and reject stale timestamps.
+//
+// It returns string of the X-Webhook-Signature header value.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,229 @@
+package webhook
+
+import (
+	// golang package
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"orderfc/cmd/order/service"
+	"orderfc/config"
+	"orderfc/infrastructure/constant"
+	"orderfc/infrastructure/log"
+	"orderfc/infrastructure/metrics"
+	"orderfc/infrastructure/worker"
+	"orderfc/models"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout        = 10 * time.Second
+	defaultMaxAttempts    = 8
+	defaultInitialBackoff = 10 * time.Second
+	defaultMaxBackoff     = time.Hour
+
+	// kept from the response for the delivery log
+	maxResponseSnippet = 512
+)
+
+var errSubscriptionInactive = errors.New("subscription is no longer active")
+
+type DeliveryWorker struct {
+	OrderService service.OrderService
+	Client       *http.Client
+
+	cfg     config.WebhookConfig
+	poller  *worker.Poller
+	backoff worker.Backoff
+}
+
+// NewDeliveryWorker new delivery worker by given cfg of config.WebhookConfig, and OrderService.
+//
+// Redirects are not followed, a receiver has to answer the subscribed URL
+// itself. Requests only go to public addresses and never through a proxy, so
+// a subscription cannot reach internal services and expose their responses
+// in the delivery log.
+//
+// It returns pointer of DeliveryWorker.
+func NewDeliveryWorker(cfg config.WebhookConfig, orderService service.OrderService) *DeliveryWorker {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaultInitialBackoff
+	}
+
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+
+	w := &DeliveryWorker{
+		OrderService: orderService,
+		Client: &http.Client{
+			Timeout: cfg.Timeout,
+			Transport: &http.Transport{
+				DialContext:         newDialer().DialContext,
+				TLSHandshakeTimeout: dialTimeout,
+				MaxIdleConnsPerHost: 2,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg:     cfg,
+		backoff: worker.Backoff{Initial: cfg.InitialBackoff, Max: cfg.MaxBackoff},
+	}
+
+	w.poller = worker.NewPoller("WEBHOOK", cfg.PollInterval, cfg.BatchSize, w.deliverBatch)
+	return w
+}
+
+// Start start.
+//
+// It delivers the due webhook deliveries until ctx is done.
+func (w *DeliveryWorker) Start(ctx context.Context) {
+	w.poller.Start(ctx)
+}
+
+// Running running.
+//
+// It returns true while the worker loop is delivering.
+// Otherwise, false will be returned.
+func (w *DeliveryWorker) Running() bool {
+	return w.poller.Running()
+}
+
+// deliverBatch deliver batch by given limit of the deliveries to claim.
+//
+// Deliveries are leased for twice the request timeout.
+//
+// It returns int of the claimed deliveries, and nil error when successful.
+// Otherwise, 0, and error will be returned.
+func (w *DeliveryWorker) deliverBatch(ctx context.Context, limit int) (int, error) {
+	deliveries, err := w.OrderService.ClaimWebhookDeliveries(ctx, limit, 2*w.cfg.Timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	subscriptions := map[int64]models.WebhookSubscription{}
+	for _, delivery := range deliveries {
+		if _, ok := subscriptions[delivery.SubscriptionID]; ok {
+			continue
+		}
+
+		subscription, err := w.OrderService.GetWebhookSubscriptionByID(ctx, delivery.SubscriptionID)
+		if err != nil {
+			// the claimed deliveries are retried once their lease runs out
+			return len(deliveries), err
+		}
+
+		subscriptions[delivery.SubscriptionID] = subscription
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery models.WebhookDelivery) {
+			defer wg.Done()
+			w.deliver(ctx, delivery, subscriptions[delivery.SubscriptionID])
+		}(delivery)
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// deliver deliver by given delivery of models.WebhookDelivery, and subscription of models.WebhookSubscription.
+//
+// The outcome of the attempt is recorded on the delivery.
+func (w *DeliveryWorker) deliver(ctx context.Context, delivery models.WebhookDelivery, subscription models.WebhookSubscription) {
+	ctx = log.ContextWithOrderID(ctx, delivery.OrderID)
+
+	var err error
+	if subscription.ID == 0 || !subscription.Active {
+		err = errSubscriptionInactive
+	} else {
+		delivery.ResponseCode, err = w.post(ctx, delivery, subscription)
+	}
+
+	delivery = w.result(delivery, err)
+
+	metrics.WebhookDeliveryAttemptsTotal.WithLabelValues(delivery.EventType, delivery.Status).Inc()
+
+	if err != nil {
+		log.WithContext(ctx).Warnf("[WEBHOOK] Delivery #%d attempt %d to subscription #%d: %v, now %s",
+			delivery.ID, delivery.Attempts, delivery.SubscriptionID, err, delivery.Status)
+	}
+
+	// recorded even when the worker is stopping, the request was already sent
+	err = w.OrderService.UpdateWebhookDeliveryResult(context.WithoutCancel(ctx), delivery)
+	if err != nil {
+		log.WithContext(ctx).Errorf("[WEBHOOK] Error Update Delivery #%d: %v", delivery.ID, err)
+	}
+}
+
+// result result by given delivery of models.WebhookDelivery, and err of the attempt.
+//
+// It returns models.WebhookDelivery succeeded when err is nil, failed once it
+// ran out of attempts or the subscription is gone, and otherwise pending
+// again after the backoff.
+func (w *DeliveryWorker) result(delivery models.WebhookDelivery, err error) models.WebhookDelivery {
+	switch {
+	case err == nil:
+		delivery.Status = constant.WebhookDeliverySucceeded
+		delivery.LastError = ""
+	case errors.Is(err, errSubscriptionInactive) || errors.Is(err, ErrPrivateAddress) || delivery.Attempts >= w.cfg.MaxAttempts:
+		delivery.Status = constant.WebhookDeliveryFailed
+		delivery.LastError = err.Error()
+	default:
+		delivery.Status = constant.WebhookDeliveryPending
+		delivery.LastError = err.Error()
+		delivery.NextAttemptTime = time.Now().Add(w.backoff.Delay(delivery.Attempts))
+	}
+
+	return delivery
+}
+
+// post post by given delivery of models.WebhookDelivery, and subscription of models.WebhookSubscription.
+//
+// It returns int of the response status code, and nil error when the receiver answered with 2xx.
+// Otherwise, the status code if any, and error will be returned.
+func (w *DeliveryWorker) post(ctx context.Context, delivery models.WebhookDelivery, subscription models.WebhookSubscription) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "orderfc-webhook/1")
+	req.Header.Set(HeaderEventID, delivery.EventID)
+	req.Header.Set(HeaderEventType, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, body))
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseSnippet))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver answered %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,254 @@
+package webhook
+
+import (
+	// golang package
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"orderfc/cmd/order/service"
+	"orderfc/config"
+	"orderfc/infrastructure/constant"
+	"orderfc/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testConfig = config.WebhookConfig{
+	MaxAttempts:    4,
+	InitialBackoff: 10 * time.Second,
+	MaxBackoff:     30 * time.Second,
+}
+
+// newTestWorker new test worker by given server pointer of httptest.Server.
+//
+// It returns pointer of DeliveryWorker sending its requests to server, which
+// listens on a loopback address the default client refuses.
+func newTestWorker(server *httptest.Server) *DeliveryWorker {
+	worker := NewDeliveryWorker(testConfig, service.OrderService{})
+	worker.Client = server.Client()
+	return worker
+}
+
+func TestPostSignsRequest(t *testing.T) {
+	delivery := models.WebhookDelivery{
+		ID:        1,
+		EventID:   "evt-1",
+		EventType: "order.completed",
+		Payload:   `{"order_id":7,"status":"completed"}`,
+	}
+	subscription := models.WebhookSubscription{ID: 1, Secret: "whsec_test", Active: true}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != delivery.Payload {
+			t.Errorf("body = %s, want %s", body, delivery.Payload)
+		}
+
+		if got := r.Header.Get(HeaderEventID); got != delivery.EventID {
+			t.Errorf("%s = %q, want %q", HeaderEventID, got, delivery.EventID)
+		}
+
+		if got := r.Header.Get(HeaderEventType); got != delivery.EventType {
+			t.Errorf("%s = %q, want %q", HeaderEventType, got, delivery.EventType)
+		}
+
+		want := Sign(subscription.Secret, r.Header.Get(HeaderTimestamp), body)
+		if got := r.Header.Get(HeaderSignature); got != want {
+			t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	subscription.URL = server.URL
+	code, err := newTestWorker(server).post(context.Background(), delivery, subscription)
+	if err != nil || code != http.StatusNoContent {
+		t.Fatalf("post() = %d, %v, want %d, nil", code, err, http.StatusNoContent)
+	}
+}
+
+func TestPostReportsReceiverError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = io.WriteString(w, strings.Repeat("x", 2*maxResponseSnippet))
+	}))
+	defer server.Close()
+
+	subscription := models.WebhookSubscription{ID: 1, URL: server.URL, Active: true}
+	code, err := newTestWorker(server).post(context.Background(), models.WebhookDelivery{Payload: "{}"}, subscription)
+	if err == nil || code != http.StatusInternalServerError {
+		t.Fatalf("post() = %d, %v, want %d, error", code, err, http.StatusInternalServerError)
+	}
+
+	if strings.Count(err.Error(), "x") != maxResponseSnippet {
+		t.Errorf("error kept %d bytes of the response, want %d", strings.Count(err.Error(), "x"), maxResponseSnippet)
+	}
+}
+
+func TestPostRefusesPrivateAddress(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer server.Close()
+
+	worker := NewDeliveryWorker(testConfig, service.OrderService{})
+	subscription := models.WebhookSubscription{ID: 1, URL: server.URL, Active: true}
+
+	_, err := worker.post(context.Background(), models.WebhookDelivery{Payload: "{}"}, subscription)
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Fatalf("post() got error %v, want %v", err, ErrPrivateAddress)
+	}
+
+	if requested {
+		t.Error("request reached the loopback server")
+	}
+
+	delivery := worker.result(models.WebhookDelivery{Attempts: 1}, err)
+	if delivery.Status != constant.WebhookDeliveryFailed {
+		t.Errorf("status = %s, want %s without retrying", delivery.Status, constant.WebhookDeliveryFailed)
+	}
+}
+
+func TestResult(t *testing.T) {
+	errReceiver := errors.New("receiver answered 503")
+
+	tests := []struct {
+		name        string
+		attempts    int
+		err         error
+		wantStatus  string
+		wantBackoff time.Duration
+	}{
+		{name: "succeeded", attempts: 1, wantStatus: constant.WebhookDeliverySucceeded},
+		{name: "first failure retries after the initial backoff", attempts: 1, err: errReceiver, wantStatus: constant.WebhookDeliveryPending, wantBackoff: 10 * time.Second},
+		{name: "backoff doubles", attempts: 2, err: errReceiver, wantStatus: constant.WebhookDeliveryPending, wantBackoff: 20 * time.Second},
+		{name: "backoff is capped", attempts: 3, err: errReceiver, wantStatus: constant.WebhookDeliveryPending, wantBackoff: 30 * time.Second},
+		{name: "out of attempts", attempts: 4, err: errReceiver, wantStatus: constant.WebhookDeliveryFailed},
+		{name: "inactive subscription", attempts: 1, err: errSubscriptionInactive, wantStatus: constant.WebhookDeliveryFailed},
+	}
+
+	worker := NewDeliveryWorker(testConfig, service.OrderService{})
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before := time.Now()
+			delivery := worker.result(models.WebhookDelivery{Attempts: test.attempts, LastError: "previous"}, test.err)
+
+			if delivery.Status != test.wantStatus {
+				t.Fatalf("status = %s, want %s", delivery.Status, test.wantStatus)
+			}
+
+			if test.err == nil && delivery.LastError != "" {
+				t.Errorf("last error = %q, want it cleared", delivery.LastError)
+			}
+
+			if test.wantBackoff == 0 {
+				return
+			}
+
+			backoff := delivery.NextAttemptTime.Sub(before)
+			if backoff < test.wantBackoff || backoff > test.wantBackoff+time.Second {
+				t.Errorf("next attempt after %s, want %s", backoff, test.wantBackoff)
+			}
+		})
+	}
+}
+
+func TestReplayRedeliversFailedDelivery(t *testing.T) {
+	var eventIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eventIDs = append(eventIDs, r.Header.Get(HeaderEventID))
+		if len(eventIDs) <= testConfig.MaxAttempts {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	worker := newTestWorker(server)
+	subscription := models.WebhookSubscription{ID: 1, URL: server.URL, Secret: "whsec_test", Active: true}
+	delivery := models.WebhookDelivery{ID: 1, EventID: "evt-1", Payload: "{}", Status: constant.WebhookDeliveryPending}
+
+	attempt := func() {
+		// every claim counts as an attempt
+		delivery.Attempts++
+
+		var err error
+		delivery.ResponseCode, err = worker.post(context.Background(), delivery, subscription)
+		delivery = worker.result(delivery, err)
+	}
+
+	for delivery.Status == constant.WebhookDeliveryPending {
+		attempt()
+	}
+
+	if delivery.Status != constant.WebhookDeliveryFailed || delivery.Attempts != testConfig.MaxAttempts {
+		t.Fatalf("status = %s after %d attempt(s), want %s after %d", delivery.Status, delivery.Attempts, constant.WebhookDeliveryFailed, testConfig.MaxAttempts)
+	}
+
+	// what ReplayWebhookDelivery writes
+	delivery.Status = constant.WebhookDeliveryPending
+	delivery.Attempts = 0
+	attempt()
+
+	if delivery.Status != constant.WebhookDeliverySucceeded || delivery.ResponseCode != http.StatusOK {
+		t.Fatalf("replayed delivery = %s with %d, want %s with %d", delivery.Status, delivery.ResponseCode, constant.WebhookDeliverySucceeded, http.StatusOK)
+	}
+
+	for _, eventID := range eventIDs {
+		if eventID != delivery.EventID {
+			t.Errorf("delivered event id %q, want %q on every attempt", eventID, delivery.EventID)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		private bool
+	}{
+		{url: "https://hooks.example.com/orders"},
+		{url: "https://203.0.113.10/orders"},
+		{url: "http://localhost:8080/hook", private: true},
+		{url: "http://api.localhost/hook", private: true},
+		{url: "http://127.0.0.1/hook", private: true},
+		{url: "http://10.1.2.3/hook", private: true},
+		{url: "http://192.168.0.1/hook", private: true},
+		{url: "http://172.16.5.4/hook", private: true},
+		{url: "http://169.254.169.254/latest/meta-data", private: true},
+		{url: "http://100.64.0.1/hook", private: true},
+		{url: "http://0.0.0.0/hook", private: true},
+		{url: "http://[::1]/hook", private: true},
+		{url: "http://[fe80::1]/hook", private: true},
+		{url: "http://[fd00::1]/hook", private: true},
+		{url: "http://[::ffff:127.0.0.1]/hook", private: true},
+	}
+
+	for _, test := range tests {
+		err := CheckURL(test.url)
+		if got := errors.Is(err, ErrPrivateAddress); got != test.private {
+			t.Errorf("CheckURL(%q) got error %v, want private %t", test.url, err, test.private)
+		}
+	}
+}
+
+func TestIsPublicAddress(t *testing.T) {
+	for _, address := range []string{"8.8.8.8", "2001:4860:4860::8888"} {
+		if !IsPublicAddress(netip.MustParseAddr(address)) {
+			t.Errorf("IsPublicAddress(%s) = false, want true", address)
+		}
+	}
+
+	for _, address := range []string{"127.0.0.1", "10.0.0.1", "169.254.1.1", "224.0.0.1", "::", "::1"} {
+		if IsPublicAddress(netip.MustParseAddr(address)) {
+			t.Errorf("IsPublicAddress(%s) = true, want false", address)
+		}
+	}
+}